@@ -1,8 +1,11 @@
 package dao
+
 //dipakai oleh database
 
 import (
+	"base-gin/app/domain"
 	"base-gin/util"
+	"strings"
 	"time"
 )
 
@@ -10,13 +13,25 @@ type Account struct {
 	ID        uint `gorm:"primarykey"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	Username  string `gorm:"size:16;not null;unique;uniqueIndex:user_pass;"`
-	Password  string `gorm:"size:255;not null;uniqueIndex:user_pass;"`
+	Username  string          `gorm:"size:16;not null;unique;uniqueIndex:user_pass;" audit:"redact"`
+	Password  string          `gorm:"size:255;not null;uniqueIndex:user_pass;" audit:"redact"`
+	Role      domain.TypeRole `gorm:"type:enum('member','librarian','admin');not null;default:member;"`
+	// TOTPSecret is encrypted with util.EncryptAESGCM. It is set on enrollment
+	// and only used for login once TOTPEnabledAt is set.
+	TOTPSecret    *string `gorm:"size:255;" audit:"redact"`
+	TOTPEnabledAt *time.Time
+	TOTPLastStep  int64 `gorm:"not null;default:0;"`
+	// FailedLogins counts failed logins since the last successful one. The
+	// account is locked, until an admin unlocks it, once it reaches
+	// LOGIN_LOCKOUT_THRESHOLD.
+	FailedLogins int `gorm:"not null;default:0;"`
+	LockedAt     *time.Time
 }
 
 func NewUser(uname, paswd, secret string) (Account, error) {
 	account := Account{
 		Username: uname,
+		Role:     domain.RoleMember,
 	}
 
 	if err := account.SetPassword(paswd, secret); err != nil {
@@ -26,6 +41,45 @@ func NewUser(uname, paswd, secret string) (Account, error) {
 	return account, nil
 }
 
+// HasRole reports whether the account has one of the given roles.
+func (t *Account) HasRole(roles ...domain.TypeRole) bool {
+	for _, role := range roles {
+		if t.Role == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasTOTP reports whether login requires a TOTP code.
+func (t *Account) HasTOTP() bool {
+	return t.TOTPEnabledAt != nil
+}
+
+// Anonymize replaces the username and every credential of the account with
+// random ones, and locks it so it can not be used again.
+func (t *Account) Anonymize() error {
+	t.Username = "anon-" + strings.ToLower(util.RandomStringAlpha(11))
+	if err := t.SetPassword(util.RandomString(32), ""); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	t.Role = domain.RoleMember
+	t.TOTPSecret = nil
+	t.TOTPEnabledAt = nil
+	t.TOTPLastStep = 0
+	t.FailedLogins = 0
+	t.LockedAt = &now
+
+	return nil
+}
+
+func (t *Account) IsLocked() bool {
+	return t.LockedAt != nil
+}
+
 func (t *Account) VerifyPassword(plainPaswd string) bool {
 	return util.VerifyPasswordHash(t.Password, plainPaswd)
 }
@@ -0,0 +1,50 @@
+package dao
+
+import (
+	"base-gin/app/domain"
+	"strings"
+	"time"
+)
+
+// APIKey lets a service act as its account without a password. Only the
+// SHA-256 hash of the key is kept; Prefix is stored so the owner can tell
+// keys apart.
+type APIKey struct {
+	ID         uint `gorm:"primarykey"`
+	CreatedAt  time.Time
+	AccountID  uint      `gorm:"not null;index;"`
+	Name       string    `gorm:"size:64;not null;"`
+	Prefix     string    `gorm:"size:12;not null;"`
+	KeyHash    string    `gorm:"size:64;not null;unique;"`
+	Scopes     string    `gorm:"size:64;not null;"` // comma separated domain.TypeScope
+	ExpiredAt  time.Time `gorm:"not null;index;"`
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	Account    *Account `gorm:"foreignKey:AccountID;"`
+}
+
+func (t *APIKey) ScopeList() []domain.TypeScope {
+	var scopes []domain.TypeScope
+	for _, s := range strings.Split(t.Scopes, ",") {
+		if s != "" {
+			scopes = append(scopes, domain.TypeScope(s))
+		}
+	}
+
+	return scopes
+}
+
+func (t *APIKey) HasScope(scope domain.TypeScope) bool {
+	for _, s := range t.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsActive reports whether the key is neither revoked nor expired.
+func (t *APIKey) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && t.ExpiredAt.After(now)
+}
@@ -0,0 +1,21 @@
+package dao
+
+import (
+	"base-gin/app/domain"
+	"time"
+)
+
+// AuditLog records who did what to which entity. ActorID is empty for changes
+// made by the system, e.g. a scheduled job. Diff holds the changed columns as
+// JSON, see storage.AuditDiff.
+type AuditLog struct {
+	ID        uint                   `gorm:"primarykey"`
+	CreatedAt time.Time              `gorm:"index;"`
+	ActorID   *uint                  `gorm:"index;"`
+	Actor     *Account               `gorm:"foreignKey:ActorID;"`
+	Entity    domain.TypeAuditEntity `gorm:"size:32;not null;index:idx_audit_entity;"`
+	EntityID  uint                   `gorm:"not null;index:idx_audit_entity;"`
+	Action    domain.TypeAuditAction `gorm:"size:16;not null;"`
+	Diff      string                 `gorm:"type:text;"`
+	IPAddress string                 `gorm:"size:45;"`
+}
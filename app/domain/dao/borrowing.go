@@ -0,0 +1,15 @@
+package dao
+
+import "time"
+
+type Borrowing struct {
+	ID         uint `gorm:"primarykey"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	BorrowDate time.Time `gorm:"not null;"`
+	ReturnDate *time.Time
+	BookID     uint    `gorm:"not null;index;"`
+	Book       *Book   `gorm:"foreignKey:BookID;"`
+	PersonID   uint    `gorm:"not null;index;"`
+	Person     *Person `gorm:"foreignKey:PersonID;"`
+}
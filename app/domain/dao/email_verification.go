@@ -0,0 +1,18 @@
+package dao
+
+import "time"
+
+// EmailVerification holds a one-time code sent to a person's email. Only the
+// hash of the code, and the blind index of the email, are stored. The code
+// only verifies the email it was sent to, so it is useless once the person's
+// email changes again.
+type EmailVerification struct {
+	ID         uint `gorm:"primarykey"`
+	CreatedAt  time.Time
+	PersonID   uint      `gorm:"not null;index;"`
+	EmailIndex string    `gorm:"size:64;not null;"`
+	CodeHash   string    `gorm:"size:255;not null;"`
+	Attempts   int       `gorm:"not null;default:0;"`
+	ExpiredAt  time.Time `gorm:"not null;index;"`
+	UsedAt     *time.Time
+}
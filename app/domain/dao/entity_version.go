@@ -0,0 +1,20 @@
+package dao
+
+import (
+	"base-gin/app/domain"
+	"time"
+)
+
+// EntityVersion is a record as it was before an update, numbered from 1 per
+// record. Data holds the row as JSON and is encrypted, as rows of persons
+// carry personal data. ActorID is the account whose update replaced it.
+type EntityVersion struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	Entity    domain.TypeAuditEntity `gorm:"size:32;not null;uniqueIndex:idx_entity_version;"`
+	EntityID  uint                   `gorm:"not null;uniqueIndex:idx_entity_version;"`
+	Version   uint                   `gorm:"not null;uniqueIndex:idx_entity_version;"`
+	ActorID   *uint
+	Actor     *Account `gorm:"foreignKey:ActorID;"`
+	Data      string   `gorm:"type:text;not null;serializer:pii;"`
+}
@@ -0,0 +1,16 @@
+package dao
+
+import "time"
+
+// ExternalIdentity links an account to a user of an external identity
+// provider, e.g. the `sub` claim of an OpenID Connect issuer.
+type ExternalIdentity struct {
+	ID          uint `gorm:"primarykey"`
+	CreatedAt   time.Time
+	AccountID   uint     `gorm:"not null;index;"`
+	Account     *Account `gorm:"foreignKey:AccountID;"`
+	Issuer      string   `gorm:"size:255;not null;uniqueIndex:issuer_subject;"`
+	Subject     string   `gorm:"size:255;not null;uniqueIndex:issuer_subject;"`
+	Email       string   `gorm:"size:255;"`
+	LastLoginAt time.Time
+}
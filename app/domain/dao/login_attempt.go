@@ -0,0 +1,10 @@
+package dao
+
+import "time"
+
+type LoginAttempt struct {
+	ID          uint      `gorm:"primarykey"`
+	ThrottleKey string    `gorm:"size:128;not null;unique;"`
+	Count       int       `gorm:"not null;"`
+	ExpiredAt   time.Time `gorm:"not null;index;"`
+}
@@ -0,0 +1,23 @@
+package dao
+
+import (
+	"base-gin/app/domain"
+	"time"
+)
+
+// LoginHistory records one login attempt. AccountID is empty when the
+// attempt could not be tied to an account.
+type LoginHistory struct {
+	ID        uint                   `gorm:"primarykey"`
+	CreatedAt time.Time              `gorm:"index;"`
+	AccountID *uint                  `gorm:"index;"`
+	Username  string                 `gorm:"size:16;not null;"`
+	Result    domain.TypeLoginResult `gorm:"size:16;not null;"`
+	IPAddress string                 `gorm:"size:45;"`
+	UserAgent string                 `gorm:"size:255;"`
+	UserOS    string                 `gorm:"size:64;"`
+}
+
+func (t *LoginHistory) Succeeded() bool {
+	return t.Result == domain.LoginOK
+}
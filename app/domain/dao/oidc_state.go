@@ -0,0 +1,17 @@
+package dao
+
+import "time"
+
+// OIDCState remembers an authorization request sent to the OpenID Connect
+// provider until its callback arrives. It can only be used once, and only by
+// the client that started the login, which proves it with the binding value
+// whose hash is kept in BindingHash.
+type OIDCState struct {
+	ID           uint `gorm:"primarykey"`
+	CreatedAt    time.Time
+	State        string    `gorm:"size:64;not null;unique;"`
+	Nonce        string    `gorm:"size:64;not null;"`
+	CodeVerifier string    `gorm:"size:64;not null;"`
+	BindingHash  string    `gorm:"size:64;not null;"`
+	ExpiredAt    time.Time `gorm:"not null;index;"`
+}
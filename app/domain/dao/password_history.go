@@ -0,0 +1,12 @@
+package dao
+
+import "time"
+
+// PasswordHistory keeps the hashes of an account's recent passwords so they
+// can not be picked again.
+type PasswordHistory struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	AccountID uint   `gorm:"not null;index;"`
+	Password  string `gorm:"size:255;not null;"`
+}
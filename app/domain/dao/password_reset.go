@@ -0,0 +1,16 @@
+package dao
+
+import "time"
+
+// PasswordReset holds a one-time code for resetting an account's password.
+// Only the hash of the code is stored. A code is spent once UsedAt is set,
+// either by a successful reset or by a newer code replacing it.
+type PasswordReset struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	AccountID uint      `gorm:"not null;index;"`
+	CodeHash  string    `gorm:"size:255;not null;"`
+	Attempts  int       `gorm:"not null;default:0;"`
+	ExpiredAt time.Time `gorm:"not null;index;"`
+	UsedAt    *time.Time
+}
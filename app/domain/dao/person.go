@@ -2,6 +2,8 @@ package dao
 
 import (
 	"base-gin/app/domain"
+	"base-gin/util"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -11,11 +13,47 @@ type Person struct {
 	gorm.Model
 	AccountID *uint              `gorm:"uniqueIndex;"`//kalo ada * nya boleh null, artinya user tidak harus memiliki akun untuk melihat
 	Account   *Account           `gorm:"foreignKey:AccountID;"`
-	Fullname  string             `gorm:"size:56;not null;"`
-	Gender    *domain.TypeGender `gorm:"type:enum('f','m');"`
-	BirthDate *time.Time
+	Fullname  string             `gorm:"size:56;not null;" audit:"redact"`
+	Gender    *domain.TypeGender `gorm:"type:enum('f','m');" audit:"redact"`
+	// BirthDate, Email and Phone are encrypted at rest. Email and Phone can
+	// only be looked up through their blind index, see SetContact.
+	BirthDate *time.Time `gorm:"size:128;serializer:pii;"`
+	// Email is only verified while EmailVerifiedAt is set; changing it sends
+	// a new code and clears the timestamp.
+	Email           *string `gorm:"size:512;serializer:pii;"`
+	EmailIndex      *string `gorm:"size:64;index;" audit:"-"`
+	EmailVerifiedAt *time.Time
+	Phone           *string `gorm:"size:128;serializer:pii;"`
+	PhoneIndex      *string `gorm:"size:64;index;" audit:"-"`
 }
 
 func (Person) TableName() string {
 	return "persons"
 }
+
+// SetContact sets the email & phone together with their blind indexes.
+func (p *Person) SetContact(email, phone *string) {
+	p.Email, p.EmailIndex = email, blindIndex(email)
+	p.Phone, p.PhoneIndex = phone, blindIndex(phone)
+}
+
+// Anonymize removes whatever identifies the person. Gender and the birth year
+// are kept so borrowings still count in statistics.
+func (p *Person) Anonymize() {
+	p.Fullname = fmt.Sprintf("Anonim #%d", p.ID)
+	if p.BirthDate != nil {
+		birthYear := time.Date(p.BirthDate.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		p.BirthDate = &birthYear
+	}
+	p.SetContact(nil, nil)
+	p.EmailVerifiedAt = nil
+}
+
+func blindIndex(value *string) *string {
+	if value == nil {
+		return nil
+	}
+
+	index := util.PIIBlindIndex(*value)
+	return &index
+}
@@ -0,0 +1,13 @@
+package dao
+
+import "time"
+
+// RecoveryCode is a single-use code that stands in for a TOTP code when the
+// authenticator is lost. Only the hash of the code is stored.
+type RecoveryCode struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	AccountID uint   `gorm:"not null;index;"`
+	CodeHash  string `gorm:"size:255;not null;"`
+	UsedAt    *time.Time
+}
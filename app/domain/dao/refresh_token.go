@@ -0,0 +1,14 @@
+package dao
+
+import "time"
+
+type RefreshToken struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	TokenID   string    `gorm:"size:36;not null;unique;"`
+	Family    string    `gorm:"size:36;not null;index;"`
+	AccountID uint      `gorm:"not null;index;"`
+	ExpiredAt time.Time `gorm:"not null;"`
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
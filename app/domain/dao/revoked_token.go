@@ -0,0 +1,16 @@
+package dao
+
+import "time"
+
+// RevokedToken invalidates tokens before they expire. A row revokes either a
+// single token by its TokenID, every token of one login by its Family, or,
+// when both are empty, every token of the account issued before RevokedBefore.
+type RevokedToken struct {
+	ID            uint `gorm:"primarykey"`
+	CreatedAt     time.Time
+	TokenID       *string `gorm:"size:36;unique;"`
+	Family        *string `gorm:"size:36;index;"`
+	AccountID     uint    `gorm:"not null;index;"`
+	RevokedBefore *time.Time
+	ExpiredAt     time.Time `gorm:"not null;index;"`
+}
@@ -0,0 +1,19 @@
+package dao
+
+import "time"
+
+// Session is one login on one device. It shares its Family with every
+// access & refresh token issued from that login, so revoking the session
+// signs the device out.
+type Session struct {
+	ID         uint `gorm:"primarykey"`
+	CreatedAt  time.Time
+	AccountID  uint   `gorm:"not null;index;"`
+	Family     string `gorm:"size:36;not null;unique;"`
+	IPAddress  string `gorm:"size:45;"`
+	UserAgent  string `gorm:"size:255;"`
+	UserOS     string `gorm:"size:64;"`
+	LastUsedAt time.Time
+	ExpiredAt  time.Time `gorm:"not null;index;"`
+	RevokedAt  *time.Time
+}
@@ -6,3 +6,56 @@ const (
 	GenderMale   TypeGender = "m"
 	GenderFemale TypeGender = "f"
 )
+
+type TypeRole string
+
+const (
+	RoleMember    TypeRole = "member"
+	RoleLibrarian TypeRole = "librarian"
+	RoleAdmin     TypeRole = "admin"
+)
+
+// TypeLoginResult is the outcome of a login attempt kept in login history.
+type TypeLoginResult string
+
+const (
+	LoginOK          TypeLoginResult = "ok"
+	LoginChallenge   TypeLoginResult = "challenge" // password accepted, TOTP pending
+	LoginBadPassword TypeLoginResult = "bad_password"
+	LoginBadTOTP     TypeLoginResult = "bad_totp"
+	LoginLocked      TypeLoginResult = "locked"
+	LoginThrottled   TypeLoginResult = "throttled"
+	LoginUnknownUser TypeLoginResult = "unknown_user"
+)
+
+// TypeScope limits what an API key may do on behalf of its account.
+type TypeScope string
+
+const (
+	ScopeRead  TypeScope = "read"  // GET requests
+	ScopeWrite TypeScope = "write" // every other method
+)
+
+// TypeAuditEntity names the kind of record an audit record is about.
+type TypeAuditEntity string
+
+const (
+	EntityAccount   TypeAuditEntity = "account"
+	EntityAuthor    TypeAuditEntity = "author"
+	EntityBook      TypeAuditEntity = "book"
+	EntityBorrowing TypeAuditEntity = "borrowing"
+	EntityPerson    TypeAuditEntity = "person"
+	EntityPublisher TypeAuditEntity = "publisher"
+)
+
+// TypeAuditAction is what was done to the entity of an audit record.
+type TypeAuditAction string
+
+const (
+	AuditCreate    TypeAuditAction = "create"
+	AuditUpdate    TypeAuditAction = "update"
+	AuditDelete    TypeAuditAction = "delete"  // soft-deleted, see AuditRestore
+	AuditRestore   TypeAuditAction = "restore" // taken out of the trash
+	AuditPurge     TypeAuditAction = "purge"   // removed from the trash for good
+	AuditAnonymize TypeAuditAction = "anonymize"
+)
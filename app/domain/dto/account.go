@@ -9,36 +9,245 @@ import (
 
 type AccountLoginReq struct {
 	Username string `json:"uname" binding:"required,max=16"`//binding : Untuk validasi di resthandler
-	Password string `json:"paswd" binding:"required,min=8,max=255"`
+	Password string `json:"paswd" binding:"required,max=255"`
 }
 
+// AccountRegisterReq embeds AccountLoginReq so a new account is held to the
+// same username & password rules used on login. Password strength is checked
+// by the service against util.PasswordPolicy.
+type AccountRegisterReq struct {
+	AccountLoginReq
+	Fullname     string `json:"fullname" binding:"required,min=4,max=56"`
+	Gender       string `json:"gender" binding:"required,oneof=m f"`
+	BirthDateStr string `json:"birth_date" binding:"required,datetime=2006-01-02"`
+}
+
+func (o *AccountRegisterReq) ToPerson() (dao.Person, error) {
+	gender := domain.GenderMale
+	if o.Gender == "f" {
+		gender = domain.GenderFemale
+	}
+
+	birthDate, err := time.Parse("2006-01-02", o.BirthDateStr)
+	if err != nil {
+		return dao.Person{}, err
+	}
+
+	return dao.Person{
+		Fullname:  o.Fullname,
+		Gender:    &gender,
+		BirthDate: &birthDate,
+	}, nil
+}
+
+type AccountRoleUpdateReq struct {
+	ID   uint   `json:"-"`
+	Role string `json:"role" binding:"required,oneof=member librarian admin"`
+}
+
+type AccountPasswordChangeReq struct {
+	Password    string `json:"paswd" binding:"required,max=255"`
+	NewPassword string `json:"new_paswd" binding:"required,max=255"`
+}
+
+type AccountPasswordForgotReq struct {
+	Username string `json:"uname" binding:"required,max=16"`
+}
+
+type AccountPasswordResetReq struct {
+	Username    string `json:"uname" binding:"required,max=16"`
+	Code        string `json:"code" binding:"required,len=6,numeric"`
+	NewPassword string `json:"new_paswd" binding:"required,max=255"`
+}
+
+type AccountLoginTOTPReq struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required,min=6,max=16"` // TOTP or recovery code
+}
+
+type AccountTOTPEnableReq struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type AccountTOTPDisableReq struct {
+	Password string `json:"paswd" binding:"required,max=255"`
+	Code     string `json:"code" binding:"required,min=6,max=16"` // TOTP or recovery code
+}
+
+// AccountLoginResp carries either a token pair, or, when the account has
+// two-factor authentication enabled, a challenge token to be exchanged for
+// one together with a TOTP code.
 type AccountLoginResp struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken    string `json:"access_token,omitempty"`
+	RefreshToken   string `json:"refresh_token,omitempty"`
+	ChallengeToken string `json:"challenge_token,omitempty"`
+}
+
+type AccountTOTPSetupResp struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"` // otpauth:// URI to be shown as a QR code
+}
+
+type AccountTOTPEnableResp struct {
+	RecoveryCodes []string `json:"recovery_codes"`
 }
 
 type AccountProfileResp struct {
-	Fullname string `json:"fullname"`
-	Gender   string `json:"gender"`
-	Age      int    `json:"age"`
+	Fullname      string `json:"fullname"`
+	Gender        string `json:"gender"`
+	Age           int    `json:"age"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified bool   `json:"email_verified"`
+	Phone         string `json:"phone,omitempty"`
 }
 
 func (o *AccountProfileResp) FromPerson(person *dao.Person) {
-	var gender string
-	if person.Gender == nil {
-		gender = "-"
-	} else if *person.Gender == domain.GenderFemale {
-		gender = "wanita"
-	} else {
-		gender = "pria"
-	}
+	o.Fullname = person.Fullname
+	o.Gender = genderText(person.Gender)
+	o.Age = ageFromBirthDate(person.BirthDate)
+	o.Email, o.EmailVerified, o.Phone = contactFromPerson(person)
+}
 
-	var age float64
-	if person.BirthDate != nil {
-		age = time.Since(*person.BirthDate).Hours() / (24 * 365)
-	}
+type AccountLoginHistoryResp struct {
+	Time      time.Time `json:"time"`
+	Success   bool      `json:"success"`
+	Result    string    `json:"result"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	UserOS    string    `json:"user_os"`
+}
 
-	o.Fullname = person.Fullname
-	o.Gender = gender
-	o.Age = int(age)
+func (o *AccountLoginHistoryResp) FromEntity(item *dao.LoginHistory) {
+	o.Time = item.CreatedAt
+	o.Success = item.Succeeded()
+	o.Result = string(item.Result)
+	o.IPAddress = item.IPAddress
+	o.UserAgent = item.UserAgent
+	o.UserOS = item.UserOS
+}
+
+type AccountSessionResp struct {
+	ID         int       `json:"id"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	UserOS     string    `json:"user_os"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Current    bool      `json:"current"`
+}
+
+func (o *AccountSessionResp) FromEntity(item *dao.Session) {
+	o.ID = int(item.ID)
+	o.IPAddress = item.IPAddress
+	o.UserAgent = item.UserAgent
+	o.UserOS = item.UserOS
+	o.CreatedAt = item.CreatedAt
+	o.LastUsedAt = item.LastUsedAt
+}
+
+type AccountAPIKeyCreateReq struct {
+	Name      string   `json:"name" binding:"required,max=64"`
+	Scopes    []string `json:"scopes" binding:"required,min=1,dive,oneof=read write"`
+	ExpiresIn int      `json:"expires_in" binding:"omitempty,min=1,max=365"` // in days
+}
+
+type AccountAPIKeyResp struct {
+	ID         int                `json:"id"`
+	Name       string             `json:"name"`
+	Prefix     string             `json:"prefix"`
+	Scopes     []domain.TypeScope `json:"scopes"`
+	CreatedAt  time.Time          `json:"created_at"`
+	ExpiredAt  time.Time          `json:"expired_at"`
+	LastUsedAt *time.Time         `json:"last_used_at"`
+	// Key is only returned once, right after the key is created.
+	Key string `json:"key,omitempty"`
+}
+
+func (o *AccountAPIKeyResp) FromEntity(item *dao.APIKey) {
+	o.ID = int(item.ID)
+	o.Name = item.Name
+	o.Prefix = item.Prefix
+	o.Scopes = item.ScopeList()
+	o.CreatedAt = item.CreatedAt
+	o.ExpiredAt = item.ExpiredAt
+	o.LastUsedAt = item.LastUsedAt
+}
+
+// AccountOIDCAuthResp holds the provider's login page. The client keeps
+// Binding and posts it back with the callback, so a code & state sent to
+// another browser can not be used there.
+type AccountOIDCAuthResp struct {
+	URL     string `json:"url"`
+	Binding string `json:"binding"`
+}
+
+type AccountOIDCCallbackReq struct {
+	Code    string `json:"code" binding:"required,max=2048"`
+	State   string `json:"state" binding:"required,max=64"`
+	Binding string `json:"binding" binding:"required,max=64"`
+}
+
+type AccountExportReq struct {
+	Format string `form:"format" binding:"omitempty,oneof=zip json"`
+}
+
+// AccountExportResp bundles what is kept about an account, to answer a data
+// subject's access request.
+type AccountExportResp struct {
+	ExportedAt   time.Time                 `json:"exported_at"`
+	Account      AccountExportAccount      `json:"account"`
+	Person       *AccountExportPerson      `json:"person"`
+	Borrowings   []BorrowingDetailResp     `json:"borrowings"`
+	LoginHistory []AccountLoginHistoryResp `json:"login_history"`
+}
+
+type AccountExportAccount struct {
+	ID          int        `json:"id"`
+	Username    string     `json:"username"`
+	Role        string     `json:"role"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	TOTPEnabled bool       `json:"totp_enabled"`
+	LockedAt    *time.Time `json:"locked_at"`
+}
+
+func (o *AccountExportAccount) FromEntity(item *dao.Account) {
+	o.ID = int(item.ID)
+	o.Username = item.Username
+	o.Role = string(item.Role)
+	o.CreatedAt = item.CreatedAt
+	o.UpdatedAt = item.UpdatedAt
+	o.TOTPEnabled = item.HasTOTP()
+	o.LockedAt = item.LockedAt
+}
+
+// AccountExportPerson holds the person unmasked, unlike PersonDetailResp.
+type AccountExportPerson struct {
+	ID              int        `json:"id"`
+	Fullname        string     `json:"fullname"`
+	Gender          string     `json:"gender"`
+	BirthDate       string     `json:"birth_date,omitempty"`
+	Email           string     `json:"email,omitempty"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	Phone           string     `json:"phone,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (o *AccountExportPerson) FromEntity(item *dao.Person) {
+	o.ID = int(item.ID)
+	o.Fullname = item.Fullname
+	o.Gender = genderText(item.Gender)
+	if item.BirthDate != nil {
+		o.BirthDate = item.BirthDate.Format("2006-01-02")
+	}
+	if item.Email != nil {
+		o.Email = *item.Email
+	}
+	o.EmailVerifiedAt = item.EmailVerifiedAt
+	if item.Phone != nil {
+		o.Phone = *item.Phone
+	}
+	o.CreatedAt = item.CreatedAt
+	o.UpdatedAt = item.UpdatedAt
 }
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"base-gin/app/domain/dao"
+	"base-gin/storage"
+	"encoding/json"
+	"time"
+)
+
+// AuditFilter narrows the audit log. From and To are inclusive dates in UTC.
+type AuditFilter struct {
+	ActorID  uint   `form:"actor_id" binding:"omitempty,min=1"`
+	Entity   string `form:"entity" binding:"omitempty,oneof=account author book borrowing person publisher"`
+	EntityID uint   `form:"entity_id" binding:"omitempty,min=1"`
+	Action   string `form:"action" binding:"omitempty,oneof=create update delete restore purge anonymize"`
+	From     string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To       string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+	Start    int    `form:"s" binding:"omitempty,min=0"`
+	Limit    int    `form:"l" binding:"omitempty,min=1"`
+}
+
+type AuditActorResp struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+}
+
+type AuditLogResp struct {
+	ID        int                            `json:"id"`
+	Time      time.Time                      `json:"time"`
+	Actor     *AuditActorResp                `json:"actor"`
+	Entity    string                         `json:"entity"`
+	EntityID  int                            `json:"entity_id"`
+	Action    string                         `json:"action"`
+	Changes   map[string]storage.AuditChange `json:"changes,omitempty"`
+	IPAddress string                         `json:"ip_address"`
+}
+
+func (o *AuditLogResp) FromEntity(item *dao.AuditLog) {
+	o.ID = int(item.ID)
+	o.Time = item.CreatedAt
+	o.Entity = string(item.Entity)
+	o.EntityID = int(item.EntityID)
+	o.Action = string(item.Action)
+	o.IPAddress = item.IPAddress
+
+	if item.Actor != nil {
+		o.Actor = &AuditActorResp{
+			ID:       int(item.Actor.ID),
+			Username: item.Actor.Username,
+		}
+	}
+	if item.Diff != "" {
+		_ = json.Unmarshal([]byte(item.Diff), &o.Changes)
+	}
+}
@@ -0,0 +1,82 @@
+package dto
+
+import (
+	"base-gin/app/domain"
+	"base-gin/app/domain/dao"
+	"time"
+)
+
+type AuthorCreateReq struct {
+	Fullname     string `json:"fullname" binding:"required,min=4,max=56"`
+	Gender       string `json:"gender" binding:"required,oneof=m f"`
+	BirthDateStr string `json:"birth_date" binding:"omitempty,datetime=2006-01-02"`
+}
+
+func (o *AuthorCreateReq) GetGender() domain.TypeGender {
+	if o.Gender == "f" {
+		return domain.GenderFemale
+	}
+
+	return domain.GenderMale
+}
+
+func (o *AuthorCreateReq) ToEntity() (dao.Author, error) {
+	gender := o.GetGender()
+	item := dao.Author{
+		Fullname: o.Fullname,
+		Gender:   &gender,
+	}
+
+	if o.BirthDateStr != "" {
+		birthDate, err := time.Parse("2006-01-02", o.BirthDateStr)
+		if err != nil {
+			return item, err
+		}
+		item.BirthDate = &birthDate
+	}
+
+	return item, nil
+}
+
+type AuthorUpdateReq struct {
+	ID           uint       `json:"-"`
+	Fullname     string     `json:"fullname" binding:"required,min=4,max=56"`
+	Gender       string     `json:"gender" binding:"required,oneof=m f"`
+	BirthDateStr string     `json:"birth_date" binding:"omitempty,datetime=2006-01-02"`
+	BirthDate    *time.Time `json:"-"`
+}
+
+func (o *AuthorUpdateReq) GetGender() domain.TypeGender {
+	if o.Gender == "f" {
+		return domain.GenderFemale
+	}
+
+	return domain.GenderMale
+}
+
+func (o *AuthorUpdateReq) GetBirthDate() (*time.Time, error) {
+	if o.BirthDateStr == "" {
+		return nil, nil
+	}
+
+	birthDate, err := time.Parse("2006-01-02", o.BirthDateStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &birthDate, nil
+}
+
+type AuthorDetailResp struct {
+	ID       int    `json:"id"`
+	Fullname string `json:"fullname"`
+	Gender   string `json:"gender"`
+	Age      int    `json:"age"`
+}
+
+func (o *AuthorDetailResp) FromEntity(item *dao.Author) {
+	o.ID = int(item.ID)
+	o.Fullname = item.Fullname
+	o.Gender = genderText(item.Gender)
+	o.Age = ageFromBirthDate(item.BirthDate)
+}
@@ -0,0 +1,83 @@
+package dto
+
+import "base-gin/app/domain/dao"
+
+type BookCreateReq struct {
+	Title       string `json:"title" binding:"required,min=1,max=56"`
+	Subtitle    string `json:"subtitle" binding:"omitempty,max=64"`
+	AuthorID    uint   `json:"author_id" binding:"required,min=1"`
+	PublisherID uint   `json:"publisher_id" binding:"required,min=1"`
+}
+
+func (o *BookCreateReq) ToEntity() dao.Book {
+	item := dao.Book{
+		Title:       o.Title,
+		AuthorID:    o.AuthorID,
+		PublisherID: o.PublisherID,
+	}
+	if o.Subtitle != "" {
+		item.Subtitle = &o.Subtitle
+	}
+
+	return item
+}
+
+type BookUpdateReq struct {
+	ID          uint   `json:"-"`
+	Title       string `json:"title" binding:"required,min=1,max=56"`
+	Subtitle    string `json:"subtitle" binding:"omitempty,max=64"`
+	AuthorID    uint   `json:"author_id" binding:"required,min=1"`
+	PublisherID uint   `json:"publisher_id" binding:"required,min=1"`
+}
+
+func (o *BookUpdateReq) FromEntity(item *dao.Book) {
+	o.ID = item.ID
+	o.Title = item.Title
+	o.Subtitle = ""
+	if item.Subtitle != nil {
+		o.Subtitle = *item.Subtitle
+	}
+	o.AuthorID = item.AuthorID
+	o.PublisherID = item.PublisherID
+}
+
+type BookAuthorResp struct {
+	ID       int    `json:"id"`
+	Fullname string `json:"fullname"`
+}
+
+type BookPublisherResp struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	City string `json:"city"`
+}
+
+type BookDetailResp struct {
+	ID        int                `json:"id"`
+	Title     string             `json:"title"`
+	Subtitle  string             `json:"subtitle"`
+	Author    *BookAuthorResp    `json:"author,omitempty"`
+	Publisher *BookPublisherResp `json:"publisher,omitempty"`
+}
+
+func (o *BookDetailResp) FromEntity(item *dao.Book) {
+	o.ID = int(item.ID)
+	o.Title = item.Title
+	if item.Subtitle != nil {
+		o.Subtitle = *item.Subtitle
+	}
+
+	if item.Author != nil {
+		o.Author = &BookAuthorResp{
+			ID:       int(item.Author.ID),
+			Fullname: item.Author.Fullname,
+		}
+	}
+	if item.Publisher != nil {
+		o.Publisher = &BookPublisherResp{
+			ID:   int(item.Publisher.ID),
+			Name: item.Publisher.Name,
+			City: item.Publisher.City,
+		}
+	}
+}
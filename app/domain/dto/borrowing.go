@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"base-gin/app/domain/dao"
+	"time"
+)
+
+type BorrowingCheckoutReq struct {
+	BookID   uint `json:"book_id" binding:"required,min=1"`
+	PersonID uint `json:"person_id" binding:"required,min=1"`
+}
+
+func (o *BorrowingCheckoutReq) ToEntity() dao.Borrowing {
+	return dao.Borrowing{
+		BorrowDate: time.Now().UTC(),
+		BookID:     o.BookID,
+		PersonID:   o.PersonID,
+	}
+}
+
+type BorrowingFilter struct {
+	BookID   uint `form:"book_id" binding:"omitempty,min=1"`
+	PersonID uint `form:"person_id" binding:"omitempty,min=1"`
+	Open     bool `form:"open" binding:"omitempty"`
+	Start    int  `form:"s" binding:"omitempty,min=0"`
+	Limit    int  `form:"l" binding:"omitempty,min=1"`
+}
+
+type BorrowingBookResp struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+type BorrowingPersonResp struct {
+	ID       int    `json:"id"`
+	Fullname string `json:"fullname"`
+}
+
+type BorrowingDetailResp struct {
+	ID         int                  `json:"id"`
+	BorrowDate time.Time            `json:"borrow_date"`
+	ReturnDate *time.Time           `json:"return_date"`
+	Book       *BorrowingBookResp   `json:"book,omitempty"`
+	Person     *BorrowingPersonResp `json:"person,omitempty"`
+}
+
+func (o *BorrowingDetailResp) FromEntity(item *dao.Borrowing) {
+	o.ID = int(item.ID)
+	o.BorrowDate = item.BorrowDate
+	o.ReturnDate = item.ReturnDate
+
+	if item.Book != nil {
+		o.Book = &BorrowingBookResp{
+			ID:    int(item.Book.ID),
+			Title: item.Book.Title,
+		}
+	}
+	if item.Person != nil {
+		o.Person = &BorrowingPersonResp{
+			ID:       int(item.Person.ID),
+			Fullname: item.Person.Fullname,
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package dto
 
+import (
+	"base-gin/app/domain"
+	"time"
+)
+
 type SuccessResponse[T any] struct {
 	Success bool   `json:"success" binding:"default:true" example:"true"`
 	Message string `json:"message"`
@@ -29,3 +34,22 @@ type Filter struct {
 	Start   int    `form:"s" binding:"omitempty,min=0"`
 	Limit   int    `form:"l" binding:"omitempty,min=1"`
 }
+
+func genderText(gender *domain.TypeGender) string {
+	if gender == nil {
+		return "-"
+	} else if *gender == domain.GenderFemale {
+		return "wanita"
+	}
+
+	return "pria"
+}
+
+func ageFromBirthDate(birthDate *time.Time) int {
+	var age float64
+	if birthDate != nil {
+		age = time.Since(*birthDate).Hours() / (24 * 365)
+	}
+
+	return int(age)
+}
@@ -3,35 +3,44 @@ package dto
 import (
 	"base-gin/app/domain"
 	"base-gin/app/domain/dao"
+	"base-gin/util"
 	"time"
 )
 
 type PersonDetailResp struct {//Resp = Respon
-	ID       int    `json:"id"`
-	Fullname string `json:"fullname"`
-	Gender   string `json:"gender"`
-	Age      int    `json:"age"`
+	ID            int    `json:"id"`
+	Fullname      string `json:"fullname"`
+	Gender        string `json:"gender"`
+	Age           int    `json:"age"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified bool   `json:"email_verified"`
+	Phone         string `json:"phone,omitempty"`
 }
 
 func (o *PersonDetailResp) FromEntity(item *dao.Person) {
-	var gender string
-	if item.Gender == nil {
-		gender = "-"
-	} else if *item.Gender == domain.GenderFemale {
-		gender = "wanita"
-	} else {
-		gender = "pria"
-	}
-
-	var age float64
-	if item.BirthDate != nil {
-		age = time.Since(*item.BirthDate).Hours() / (24 * 365)
-	}
-
 	o.Fullname = item.Fullname
-	o.Gender = gender
-	o.Age = int(age)
+	o.Gender = genderText(item.Gender)
+	o.Age = ageFromBirthDate(item.BirthDate)
 	o.ID = int(item.ID)
+	o.Email, o.EmailVerified, o.Phone = contactFromPerson(item)
+}
+
+// MaskContact hides the contact details from anyone but the person's owner
+// and staff: the email is masked and the phone left out.
+func (o *PersonDetailResp) MaskContact() {
+	o.Email = util.MaskEmailUsername(o.Email)
+	o.Phone = ""
+}
+
+// PersonTrashResp is a soft-deleted person.
+type PersonTrashResp struct {
+	PersonDetailResp
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+func (o *PersonTrashResp) FromEntity(item *dao.Person) {
+	o.PersonDetailResp.FromEntity(item)
+	o.DeletedAt = item.DeletedAt.Time
 }
 
 type PersonUpdateReq struct {//Req = Request
@@ -53,3 +62,53 @@ func (o *PersonUpdateReq) GetGender() domain.TypeGender {
 func (o *PersonUpdateReq) GetBirthDate() (time.Time, error) {
 	return time.Parse("2006-01-02", o.BirthDateStr)
 }
+
+func (o *PersonUpdateReq) FromEntity(item *dao.Person) {
+	o.ID = item.ID
+	o.Fullname = item.Fullname
+	if item.Gender != nil {
+		o.Gender = string(*item.Gender)
+	}
+	if item.BirthDate != nil {
+		o.BirthDate = *item.BirthDate
+		o.BirthDateStr = item.BirthDate.Format("2006-01-02")
+	}
+}
+
+// contactFromPerson returns the person's email, whether it is verified, and
+// phone, with empty strings for missing ones.
+func contactFromPerson(person *dao.Person) (email string, verified bool, phone string) {
+	if person.Email != nil {
+		email = *person.Email
+		verified = person.EmailVerifiedAt != nil
+	}
+	if person.Phone != nil {
+		phone = *person.Phone
+	}
+
+	return email, verified, phone
+}
+
+// PersonFilter narrows the person list. Email is an exact match on the blind
+// index, as the column itself is encrypted.
+type PersonFilter struct {
+	Filter
+	Email string `form:"email" binding:"omitempty,email"`
+}
+
+// PersonContactUpdateReq replaces a person's contact details, an empty value
+// removes one.
+type PersonContactUpdateReq struct {
+	ID    uint   `json:"-"`
+	Email string `json:"email" binding:"omitempty,email,max=255"`
+	Phone string `json:"phone" binding:"omitempty,min=8,max=16"` // with country code, e.g. 628123456789
+}
+
+func (o *PersonContactUpdateReq) FromEntity(item *dao.Person) {
+	o.ID = item.ID
+	o.Email, _, o.Phone = contactFromPerson(item)
+}
+
+type PersonEmailVerifyReq struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
@@ -1,6 +1,9 @@
 package dto
 
-import "base-gin/app/domain/dao"
+import (
+	"base-gin/app/domain/dao"
+	"time"
+)
 
 type PublisherCreateReq struct {
 	Name string `json:"name" binding:"required,min=6,max=48"`
@@ -20,8 +23,43 @@ type PublisherCreateResp struct {
 	City string `json:"city"`
 }
 
-func (o PublisherCreateResp) FromEntity(item *dao.Publisher) {
+func (o *PublisherCreateResp) FromEntity(item *dao.Publisher) {
 	o.ID = int(item.ID)
 	o.Name = item.Name
 	o.City = item.City
 }
+
+type PublisherUpdateReq struct {
+	ID   uint   `json:"-"`
+	Name string `json:"name" binding:"required,min=6,max=48"`
+	City string `json:"city" binding:"required,min=2,max=32"`
+}
+
+func (o *PublisherUpdateReq) FromEntity(item *dao.Publisher) {
+	o.ID = item.ID
+	o.Name = item.Name
+	o.City = item.City
+}
+
+type PublisherDetailResp struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	City string `json:"city"`
+}
+
+func (o *PublisherDetailResp) FromEntity(item *dao.Publisher) {
+	o.ID = int(item.ID)
+	o.Name = item.Name
+	o.City = item.City
+}
+
+// PublisherTrashResp is a soft-deleted publisher.
+type PublisherTrashResp struct {
+	PublisherDetailResp
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+func (o *PublisherTrashResp) FromEntity(item *dao.Publisher) {
+	o.PublisherDetailResp.FromEntity(item)
+	o.DeletedAt = item.DeletedAt.Time
+}
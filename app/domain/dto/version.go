@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"base-gin/app/domain/dao"
+	"time"
+)
+
+// VersionResp describes a saved version of a record: when it was saved, and
+// when and by whom it was replaced.
+type VersionResp struct {
+	Version    int             `json:"version"`
+	SavedAt    time.Time       `json:"saved_at"`
+	ReplacedAt time.Time       `json:"replaced_at"`
+	ReplacedBy *AuditActorResp `json:"replaced_by"`
+}
+
+func (o *VersionResp) FromEntity(item *dao.EntityVersion, savedAt time.Time) {
+	o.Version = int(item.Version)
+	o.SavedAt = savedAt
+	o.ReplacedAt = item.CreatedAt
+	if item.Actor != nil {
+		o.ReplacedBy = &AuditActorResp{
+			ID:       int(item.Actor.ID),
+			Username: item.Actor.Username,
+		}
+	}
+}
+
+// PersonVersionResp holds a person's detail and contact as they were, in the
+// form of the requests that restore them.
+type PersonVersionResp struct {
+	VersionResp
+	Data    PersonUpdateReq        `json:"data"`
+	Contact PersonContactUpdateReq `json:"contact"`
+}
+
+type PublisherVersionResp struct {
+	VersionResp
+	Data PublisherUpdateReq `json:"data"`
+}
+
+type BookVersionResp struct {
+	VersionResp
+	Data BookUpdateReq `json:"data"`
+}
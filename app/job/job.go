@@ -0,0 +1,94 @@
+package job
+
+import (
+	"base-gin/app/service"
+	"base-gin/config"
+	"base-gin/exception"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SetupJobs starts the background jobs. They stop when ctx is cancelled.
+func SetupJobs(ctx context.Context, cfg *config.Config) {
+	go runEvery(ctx, time.Duration(cfg.Job.TokenPurgeInterval)*time.Second,
+		"job.purgeExpiredTokens", purgeExpiredTokens)
+	go runEvery(ctx, time.Duration(cfg.Job.LoginHistoryPurgeInterval)*time.Second,
+		"job.purgeLoginHistory", purgeLoginHistory)
+	go runEvery(ctx, time.Duration(cfg.Job.TrashPurgeInterval)*time.Second,
+		"job.purgeTrash", func() error { return purgeTrash(cfg.Job.TrashRetention) })
+}
+
+func runEvery(ctx context.Context, interval time.Duration, name string, fn func() error) {
+	if interval <= 0 {
+		log.Info().Msgf("%s disabled", name)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fn(); err != nil {
+				exception.LogError(err, name)
+			}
+		}
+	}
+}
+
+func purgeExpiredTokens() error {
+	count, err := service.GetAccountService().PurgeExpiredTokens()
+	if err != nil {
+		return err
+	}
+
+	states, err := service.GetOIDCService().PurgeExpiredStates()
+	if err != nil {
+		return err
+	}
+	count += states
+
+	codes, err := service.GetPersonService().PurgeExpiredCodes()
+	if err != nil {
+		return err
+	}
+	count += codes
+
+	log.Info().Int64("count", count).Msg("job.purgeExpiredTokens")
+	return nil
+}
+
+func purgeLoginHistory() error {
+	count, err := service.GetAccountService().PurgeLoginHistory()
+	if err != nil {
+		return err
+	}
+
+	log.Info().Int64("count", count).Msg("job.purgeLoginHistory")
+	return nil
+}
+
+// purgeTrash removes the persons & publishers deleted more than retention
+// days ago.
+func purgeTrash(retention int) error {
+	cutoff := time.Now().UTC().AddDate(0, 0, -retention)
+
+	count, err := service.GetPersonService().PurgeDeleted(cutoff)
+	if err != nil {
+		return err
+	}
+
+	publishers, err := service.GetPublisherServide().PurgeDeleted(cutoff)
+	if err != nil {
+		return err
+	}
+	count += publishers
+
+	log.Info().Int64("count", count).Msg("job.purgeTrash")
+	return nil
+}
@@ -1,12 +1,15 @@
 package repository
 
 import (
+	"base-gin/app/domain"
 	"base-gin/app/domain/dao"
 	"base-gin/exception"
 	"base-gin/storage"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AccountRepository struct {
@@ -29,6 +32,445 @@ func (r *AccountRepository) Create(newItem *dao.Account) error {
 	return nil
 }
 
+// CreateWithPerson stores a new account and its linked person in a single
+// transaction.
+func (r *AccountRepository) CreateWithPerson(newItem *dao.Account, person *dao.Person) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(newItem).Error; err != nil {
+			if isDuplicateEntry(err) {
+				return exception.ErrUserConflict
+			}
+			return err
+		}
+
+		person.AccountID = &newItem.ID
+		return tx.Create(person).Error
+	})
+}
+
+// CreateWithIdentity creates an account for a user of an external identity
+// provider, together with its person and the link to the provider. It returns
+// exception.ErrUserConflict when the username or the identity is taken.
+func (r *AccountRepository) CreateWithIdentity(
+	newItem *dao.Account,
+	person *dao.Person,
+	identity *dao.ExternalIdentity,
+) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(newItem).Error; err != nil {
+			if isDuplicateEntry(err) {
+				return exception.ErrUserConflict
+			}
+			return err
+		}
+
+		person.AccountID = &newItem.ID
+		if err := tx.Create(person).Error; err != nil {
+			return err
+		}
+
+		identity.AccountID = newItem.ID
+		if err := tx.Create(identity).Error; err != nil {
+			if isDuplicateEntry(err) {
+				return exception.ErrUserConflict
+			}
+			return err
+		}
+
+		return nil
+	})
+}
+
+// Anonymize stores an account scrubbed by dao.Account.Anonymize and scrubs its
+// person, whose versions are dropped, in one transaction with the audit
+// record. Borrowings stay linked to the person so they still count in
+// statistics; everything else kept about the account, from sessions to login
+// history, is removed, and every token issued so far is revoked until
+// tokenExpiry. oldUsername is the username before anonymization, to remove
+// failed logins recorded under it.
+func (r *AccountRepository) Anonymize(
+	account *dao.Account,
+	oldUsername string,
+	tokenExpiry time.Time,
+	audit *dao.AuditLog,
+) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&dao.Account{}).
+			Where("id = ?", account.ID).
+			Select("username", "password", "role", "totp_secret", "totp_enabled_at",
+				"totp_last_step", "failed_logins", "locked_at", "updated_at").
+			Updates(account)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return exception.ErrUserNotFound
+		}
+
+		var person dao.Person
+		err := tx.Unscoped().Where("account_id = ?", account.ID).First(&person).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			person.Anonymize()
+			err = tx.Unscoped().Model(&dao.Person{}).
+				Where("id = ?", person.ID).
+				Select("fullname", "birth_date", "email", "email_index",
+					"email_verified_at", "phone", "phone_index", "updated_at").
+				Updates(&person).Error
+			if err != nil {
+				return err
+			}
+
+			err = tx.Where("person_id = ?", person.ID).
+				Delete(&dao.EmailVerification{}).Error
+			if err != nil {
+				return err
+			}
+
+			// Earlier versions still hold the name and contact details.
+			err = tx.Where("entity = ? AND entity_id = ?", domain.EntityPerson, person.ID).
+				Delete(&dao.EntityVersion{}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		// Before the sessions are deleted, as their families get revoked.
+		if err := revokeAccountTokens(tx, account.ID, tokenExpiry); err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{
+			&dao.APIKey{},
+			&dao.ExternalIdentity{},
+			&dao.PasswordHistory{},
+			&dao.PasswordReset{},
+			&dao.RecoveryCode{},
+			&dao.RefreshToken{},
+			&dao.Session{},
+		} {
+			if err := tx.Where("account_id = ?", account.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		err = tx.Where("account_id = ? OR (account_id IS NULL AND username = ?)",
+			account.ID, oldUsername).
+			Delete(&dao.LoginHistory{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(audit).Error
+	})
+}
+
+// UpdateRole assigns a role to an account. Taking the admin role away from
+// the last admin is rejected.
+func (r *AccountRepository) UpdateRole(id uint, role domain.TypeRole) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock every admin so two demotions can not pass the check at once.
+		var admins []dao.Account
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("role = ?", domain.RoleAdmin).
+			Find(&admins).Error
+		if err != nil {
+			return err
+		}
+
+		if role != domain.RoleAdmin && len(admins) == 1 && admins[0].ID == id {
+			return exception.ErrAccountLastAdmin
+		}
+
+		res := tx.Model(&dao.Account{}).Where("id = ?", id).Update("role", role)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return exception.ErrUserNotFound
+		}
+
+		return nil
+	})
+}
+
+// UpdatePassword stores a new password hash. The replaced hash is moved to
+// the account's password history, which keeps at most historySize entries.
+func (r *AccountRepository) UpdatePassword(id uint, paswdHash string, historySize int) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var item dao.Account
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&item, id).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return exception.ErrUserNotFound
+			}
+			return err
+		}
+
+		err = tx.Model(&item).Update("password", paswdHash).Error
+		if err != nil {
+			return err
+		}
+
+		if historySize <= 0 {
+			return nil
+		}
+
+		err = tx.Create(&dao.PasswordHistory{AccountID: id, Password: item.Password}).Error
+		if err != nil {
+			return err
+		}
+
+		var ids []uint
+		err = tx.Model(&dao.PasswordHistory{}).
+			Where("account_id = ?", id).
+			Order("id DESC").
+			Offset(historySize).
+			Limit(1).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		return tx.Where("account_id = ? AND id <= ?", id, ids[0]).
+			Delete(&dao.PasswordHistory{}).Error
+	})
+}
+
+// GetPasswordHistory returns the account's previous password hashes, newest
+// first.
+func (r *AccountRepository) GetPasswordHistory(id uint, limit int) ([]dao.PasswordHistory, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var items []dao.PasswordHistory
+	tx := r.db.WithContext(ctx).
+		Where("account_id = ?", id).
+		Order("id DESC").
+		Limit(limit).
+		Find(&items)
+
+	return items, tx.Error
+}
+
+// SetTOTPSecret stores a new, not yet enabled, TOTP secret.
+func (r *AccountRepository) SetTOTPSecret(id uint, secret string) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Model(&dao.Account{}).
+		Where("id = ? AND totp_enabled_at IS NULL", id).
+		Update("totp_secret", secret)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return exception.ErrTOTPEnabled
+	}
+
+	return nil
+}
+
+// EnableTOTP turns on TOTP login and replaces the account's recovery codes.
+// Step is the time step of the code used to confirm enrollment.
+func (r *AccountRepository) EnableTOTP(id uint, step int64, codeHashes []string) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&dao.Account{}).
+			Where("id = ? AND totp_enabled_at IS NULL", id).
+			Updates(map[string]any{
+				"totp_enabled_at": time.Now().UTC(),
+				"totp_last_step":  step,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return exception.ErrTOTPEnabled
+		}
+
+		err := tx.Where("account_id = ?", id).Delete(&dao.RecoveryCode{}).Error
+		if err != nil {
+			return err
+		}
+
+		codes := make([]dao.RecoveryCode, len(codeHashes))
+		for i, hash := range codeHashes {
+			codes[i] = dao.RecoveryCode{AccountID: id, CodeHash: hash}
+		}
+
+		return tx.Create(&codes).Error
+	})
+}
+
+// DisableTOTP removes the TOTP secret and every recovery code.
+func (r *AccountRepository) DisableTOTP(id uint) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&dao.Account{}).
+			Where("id = ?", id).
+			Updates(map[string]any{
+				"totp_secret":     nil,
+				"totp_enabled_at": nil,
+				"totp_last_step":  0,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("account_id = ?", id).Delete(&dao.RecoveryCode{}).Error
+	})
+}
+
+// UseTOTPStep records the time step of an accepted code. It fails with
+// ErrTOTPInvalid when that step or a later one was used already, so a code
+// can not be replayed.
+func (r *AccountRepository) UseTOTPStep(id uint, step int64) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Model(&dao.Account{}).
+		Where("id = ? AND totp_last_step < ?", id, step).
+		Update("totp_last_step", step)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return exception.ErrTOTPInvalid
+	}
+
+	return nil
+}
+
+func (r *AccountRepository) GetRecoveryCodes(id uint) ([]dao.RecoveryCode, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var items []dao.RecoveryCode
+	tx := r.db.WithContext(ctx).
+		Where("account_id = ? AND used_at IS NULL", id).
+		Find(&items)
+
+	return items, tx.Error
+}
+
+// UseRecoveryCode spends a recovery code. It fails with ErrTOTPInvalid when
+// the code was spent already.
+func (r *AccountRepository) UseRecoveryCode(codeID uint) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Model(&dao.RecoveryCode{}).
+		Where("id = ? AND used_at IS NULL", codeID).
+		Update("used_at", time.Now().UTC())
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return exception.ErrTOTPInvalid
+	}
+
+	return nil
+}
+
+// AddFailedLogin counts a failed login and locks the account once the count
+// reaches threshold. A threshold of 0 never locks. It reports whether this
+// call locked the account.
+func (r *AccountRepository) AddFailedLogin(id uint, threshold int) (bool, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var locked bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&dao.Account{}).
+			Where("id = ?", id).
+			Update("failed_logins", gorm.Expr("failed_logins + 1")).Error
+		if err != nil || threshold <= 0 {
+			return err
+		}
+
+		res := tx.Model(&dao.Account{}).
+			Where("id = ? AND locked_at IS NULL AND failed_logins >= ?", id, threshold).
+			Update("locked_at", time.Now().UTC())
+		locked = res.RowsAffected > 0
+
+		return res.Error
+	})
+
+	return locked, err
+}
+
+func (r *AccountRepository) ResetFailedLogins(id uint) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Model(&dao.Account{}).
+		Where("id = ? AND failed_logins > 0", id).
+		Update("failed_logins", 0)
+
+	return tx.Error
+}
+
+func (r *AccountRepository) Unlock(id uint) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Model(&dao.Account{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"failed_logins": 0,
+			"locked_at":     nil,
+		})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return exception.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *AccountRepository) GetByID(id uint) (dao.Account, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var item dao.Account
+	tx := r.db.WithContext(ctx).First(&item, id)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return item, exception.ErrUserNotFound
+		}
+
+		return item, tx.Error
+	}
+
+	return item, nil
+}
+
 func (r *AccountRepository) GetByUsername(uname string) (dao.Account, error) {
 	ctx, cancelFunc := storage.NewDBContext()
 	defer cancelFunc()
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"base-gin/app/domain/dao"
+	"base-gin/exception"
+	"base-gin/storage"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// apiKeyTouchInterval keeps busy keys from writing last_used_at on every
+// request.
+const apiKeyTouchInterval = time.Minute
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func newAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) Create(newItem *dao.APIKey) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Create(newItem)
+
+	return tx.Error
+}
+
+func (r *APIKeyRepository) GetByID(id uint) (dao.APIKey, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var item dao.APIKey
+	tx := r.db.WithContext(ctx).First(&item, id)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return item, exception.ErrDataNotFound
+		}
+
+		return item, tx.Error
+	}
+
+	return item, nil
+}
+
+// GetByHash returns the key with the given hash together with its account.
+func (r *APIKeyRepository) GetByHash(keyHash string) (dao.APIKey, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var item dao.APIKey
+	tx := r.db.WithContext(ctx).Preload("Account").
+		Where("key_hash = ?", keyHash).
+		First(&item)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return item, exception.ErrDataNotFound
+		}
+
+		return item, tx.Error
+	}
+
+	return item, nil
+}
+
+// GetActiveByAccountID returns the account's keys that are neither revoked
+// nor expired, newest first.
+func (r *APIKeyRepository) GetActiveByAccountID(accountID uint) ([]dao.APIKey, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var items []dao.APIKey
+	tx := r.db.WithContext(ctx).
+		Where("account_id = ? AND revoked_at IS NULL AND expired_at > ?",
+			accountID, time.Now().UTC()).
+		Order("id DESC").
+		Find(&items)
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		return nil, tx.Error
+	}
+
+	return items, nil
+}
+
+// Touch records the use of a key, at most once per apiKeyTouchInterval.
+func (r *APIKeyRepository) Touch(id uint) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	now := time.Now().UTC()
+	tx := r.db.WithContext(ctx).Model(&dao.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)",
+			id, now.Add(-apiKeyTouchInterval)).
+		Update("last_used_at", now)
+
+	return tx.Error
+}
+
+func (r *APIKeyRepository) Revoke(id uint) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Model(&dao.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now().UTC())
+
+	return tx.Error
+}
+
+// PurgeExpired removes keys that have expired.
+func (r *APIKeyRepository) PurgeExpired() (int64, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).
+		Where("expired_at < ?", time.Now().UTC()).
+		Delete(&dao.APIKey{})
+
+	return tx.RowsAffected, tx.Error
+}
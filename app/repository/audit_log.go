@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"base-gin/app/domain/dao"
+	"base-gin/app/domain/dto"
+	"base-gin/storage"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+func newAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+func (r *AuditLogRepository) Create(newItem *dao.AuditLog) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Create(newItem)
+
+	return tx.Error
+}
+
+// GetList returns the audit records matching params, newest first.
+func (r *AuditLogRepository) GetList(params *dto.AuditFilter) ([]dao.AuditLog, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var items []dao.AuditLog
+	tx := r.db.WithContext(ctx).Preload("Actor")
+
+	if params.ActorID > 0 {
+		tx = tx.Where("actor_id = ?", params.ActorID)
+	}
+	if params.Entity != "" {
+		tx = tx.Where("entity = ?", params.Entity)
+	}
+	if params.EntityID > 0 {
+		tx = tx.Where("entity_id = ?", params.EntityID)
+	}
+	if params.Action != "" {
+		tx = tx.Where("action = ?", params.Action)
+	}
+	if params.From != "" {
+		from, _ := time.Parse("2006-01-02", params.From)
+		tx = tx.Where("created_at >= ?", from)
+	}
+	if params.To != "" {
+		to, _ := time.Parse("2006-01-02", params.To)
+		tx = tx.Where("created_at < ?", to.AddDate(0, 0, 1))
+	}
+	if params.Start >= 0 {
+		tx = tx.Offset(params.Start)
+	}
+	if params.Limit > 0 {
+		tx = tx.Limit(params.Limit)
+	}
+
+	tx = tx.Order("id DESC").Find(&items)
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		return nil, tx.Error
+	}
+
+	return items, nil
+}
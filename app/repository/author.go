@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"base-gin/app/domain/dao"
+	"base-gin/app/domain/dto"
+	"base-gin/exception"
+	"base-gin/storage"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AuthorRepository struct {
+	db *gorm.DB
+}
+
+func newAuthorRepository(db *gorm.DB) *AuthorRepository {
+	return &AuthorRepository{db: db}
+}
+
+func (r *AuthorRepository) Create(newItem *dao.Author) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Create(&newItem)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	return nil
+}
+
+func (r *AuthorRepository) GetByID(id uint) (*dao.Author, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var item dao.Author
+	tx := r.db.WithContext(ctx).First(&item, id)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, exception.ErrDataNotFound
+		}
+
+		return nil, tx.Error
+	}
+
+	return &item, nil
+}
+
+func (r *AuthorRepository) GetList(params *dto.Filter) ([]dao.Author, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var items []dao.Author
+	tx := r.db.WithContext(ctx)
+
+	if params.Keyword != "" {
+		q := fmt.Sprintf("%%%s%%", params.Keyword)
+		tx = tx.Where("fullname LIKE ?", q)
+	}
+	if params.Start >= 0 {
+		tx = tx.Offset(params.Start)
+	}
+	if params.Limit > 0 {
+		tx = tx.Limit(params.Limit)
+	}
+
+	tx = tx.Order("fullname ASC").Find(&items)
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		return nil, tx.Error
+	}
+
+	return items, nil
+}
+
+func (r *AuthorRepository) Update(params *dto.AuthorUpdateReq) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Model(&dao.Author{}).
+		Where("id = ?", params.ID).
+		Updates(map[string]interface{}{
+			"fullname":   params.Fullname,
+			"gender":     params.GetGender(),
+			"birth_date": params.BirthDate,
+		})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return exception.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// Delete soft-deletes an author that no book refers to anymore.
+func (r *AuthorRepository) Delete(id uint) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var item dao.Author
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&item, id).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return exception.ErrDataNotFound
+			}
+			return err
+		}
+
+		var bookCount int64
+		err = tx.Model(&dao.Book{}).Where("author_id = ?", id).Count(&bookCount).Error
+		if err != nil {
+			return err
+		}
+		if bookCount > 0 {
+			return exception.ErrAuthorHasBooks
+		}
+
+		return tx.Delete(&item).Error
+	})
+}
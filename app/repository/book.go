@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"base-gin/app/domain/dao"
+	"base-gin/app/domain/dto"
+	"base-gin/exception"
+	"base-gin/storage"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BookRepository struct {
+	db *gorm.DB
+}
+
+func newBookRepository(db *gorm.DB) *BookRepository {
+	return &BookRepository{db: db}
+}
+
+func (r *BookRepository) Create(newItem *dao.Book) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Create(&newItem)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	return nil
+}
+
+func (r *BookRepository) GetByID(id uint) (*dao.Book, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var item dao.Book
+	tx := r.db.WithContext(ctx).
+		Preload("Author").
+		Preload("Publisher").
+		First(&item, id)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, exception.ErrDataNotFound
+		}
+
+		return nil, tx.Error
+	}
+
+	return &item, nil
+}
+
+func (r *BookRepository) GetList(params *dto.Filter) ([]dao.Book, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	return r.find(r.db.WithContext(ctx), params)
+}
+
+func (r *BookRepository) GetListByPublisherID(publisherID uint, params *dto.Filter) ([]dao.Book, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Where("publisher_id = ?", publisherID)
+	return r.find(tx, params)
+}
+
+func (r *BookRepository) find(tx *gorm.DB, params *dto.Filter) ([]dao.Book, error) {
+	var items []dao.Book
+	tx = tx.Preload("Author").Preload("Publisher")
+
+	if params.Keyword != "" {
+		q := fmt.Sprintf("%%%s%%", params.Keyword)
+		tx = tx.Where("title LIKE ? OR subtitle LIKE ?", q, q)
+	}
+	if params.Start >= 0 {
+		tx = tx.Offset(params.Start)
+	}
+	if params.Limit > 0 {
+		tx = tx.Limit(params.Limit)
+	}
+
+	tx = tx.Order("title ASC").Find(&items)
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		return nil, tx.Error
+	}
+
+	return items, nil
+}
+
+// Update saves a book together with version, the book as it was.
+func (r *BookRepository) Update(params *dto.BookUpdateReq, version *dao.EntityVersion) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var subtitle *string
+	if params.Subtitle != "" {
+		subtitle = &params.Subtitle
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&dao.Book{}).
+			Where("id = ?", params.ID).
+			Updates(map[string]interface{}{
+				"title":        params.Title,
+				"subtitle":     subtitle,
+				"author_id":    params.AuthorID,
+				"publisher_id": params.PublisherID,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return exception.ErrDataNotFound
+		}
+
+		return createVersion(tx, version)
+	})
+}
+
+// Delete soft-deletes a book that is not borrowed. The book is locked like in
+// BorrowingRepository.Checkout, so it can not be lent out meanwhile.
+func (r *BookRepository) Delete(id uint) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var item dao.Book
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&item, id).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return exception.ErrDataNotFound
+			}
+			return err
+		}
+
+		var openCount int64
+		err = tx.Model(&dao.Borrowing{}).
+			Where("book_id = ? AND return_date IS NULL", id).
+			Count(&openCount).Error
+		if err != nil {
+			return err
+		}
+		if openCount > 0 {
+			return exception.ErrBookBorrowed
+		}
+
+		return tx.Delete(&item).Error
+	})
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"base-gin/app/domain/dao"
+	"base-gin/app/domain/dto"
+	"base-gin/exception"
+	"base-gin/storage"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BorrowingRepository struct {
+	db *gorm.DB
+}
+
+func newBorrowingRepository(db *gorm.DB) *BorrowingRepository {
+	return &BorrowingRepository{db: db}
+}
+
+// Checkout stores a new borrowing unless the book is still lent out. The book
+// row is locked for the duration of the transaction so two concurrent
+// checkouts of the same book can not both succeed.
+func (r *BorrowingRepository) Checkout(newItem *dao.Borrowing) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var book dao.Book
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&book, newItem.BookID).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return exception.ErrBookNotFound
+			}
+			return err
+		}
+
+		var openCount int64
+		err = tx.Model(&dao.Borrowing{}).
+			Where("book_id = ? AND return_date IS NULL", newItem.BookID).
+			Count(&openCount).Error
+		if err != nil {
+			return err
+		}
+		if openCount > 0 {
+			return exception.ErrBookBorrowed
+		}
+
+		return tx.Create(newItem).Error
+	})
+}
+
+// Return sets the return date of an open borrowing.
+func (r *BorrowingRepository) Return(id uint, returnDate time.Time) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Model(&dao.Borrowing{}).
+		Where("id = ? AND return_date IS NULL", id).
+		Update("return_date", returnDate)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected > 0 {
+		return nil
+	}
+
+	var count int64
+	tx = r.db.WithContext(ctx).Model(&dao.Borrowing{}).
+		Where("id = ?", id).
+		Count(&count)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if count == 0 {
+		return exception.ErrDataNotFound
+	}
+
+	return exception.ErrBookReturned
+}
+
+func (r *BorrowingRepository) GetByID(id uint) (*dao.Borrowing, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var item dao.Borrowing
+	tx := r.db.WithContext(ctx).
+		Preload("Book").
+		Preload("Person").
+		First(&item, id)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, exception.ErrDataNotFound
+		}
+
+		return nil, tx.Error
+	}
+
+	return &item, nil
+}
+
+func (r *BorrowingRepository) GetList(params *dto.BorrowingFilter) ([]dao.Borrowing, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var items []dao.Borrowing
+	tx := r.db.WithContext(ctx).
+		Preload("Book").
+		Preload("Person")
+
+	if params.BookID > 0 {
+		tx = tx.Where("book_id = ?", params.BookID)
+	}
+	if params.PersonID > 0 {
+		tx = tx.Where("person_id = ?", params.PersonID)
+	}
+	if params.Open {
+		tx = tx.Where("return_date IS NULL")
+	}
+	if params.Start >= 0 {
+		tx = tx.Offset(params.Start)
+	}
+	if params.Limit > 0 {
+		tx = tx.Limit(params.Limit)
+	}
+
+	tx = tx.Order("borrow_date DESC").Find(&items)
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		return nil, tx.Error
+	}
+
+	return items, nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"base-gin/app/domain/dao"
+	"base-gin/exception"
+	"base-gin/storage"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type EmailVerificationRepository struct {
+	db *gorm.DB
+}
+
+func newEmailVerificationRepository(db *gorm.DB) *EmailVerificationRepository {
+	return &EmailVerificationRepository{db: db}
+}
+
+// Replace stores a new verification code and spends every unused code the
+// person still has, so only the latest code can be redeemed.
+func (r *EmailVerificationRepository) Replace(newItem *dao.EmailVerification) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&dao.EmailVerification{}).
+			Where("person_id = ? AND used_at IS NULL", newItem.PersonID).
+			Update("used_at", time.Now().UTC()).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(newItem).Error
+	})
+}
+
+// GetActive returns the person's latest code that is neither spent nor
+// expired.
+func (r *EmailVerificationRepository) GetActive(personID uint) (dao.EmailVerification, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var item dao.EmailVerification
+	tx := r.db.WithContext(ctx).
+		Where("person_id = ? AND used_at IS NULL AND expired_at > ?",
+			personID, time.Now().UTC()).
+		Order("id DESC").
+		First(&item)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return item, exception.ErrDataNotFound
+		}
+
+		return item, tx.Error
+	}
+
+	return item, nil
+}
+
+func (r *EmailVerificationRepository) AddAttempt(id uint) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Model(&dao.EmailVerification{}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1"))
+
+	return tx.Error
+}
+
+// MarkUsed spends a code. It fails with ErrDataNotFound when the code was
+// already spent, so a code can not be redeemed twice.
+func (r *EmailVerificationRepository) MarkUsed(id uint) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Model(&dao.EmailVerification{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now().UTC())
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return exception.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// PurgeExpired removes codes that can no longer be redeemed.
+func (r *EmailVerificationRepository) PurgeExpired() (int64, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).
+		Where("expired_at < ?", time.Now().UTC()).
+		Delete(&dao.EmailVerification{})
+
+	return tx.RowsAffected, tx.Error
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"base-gin/app/domain"
+	"base-gin/app/domain/dao"
+	"base-gin/app/domain/dto"
+	"base-gin/exception"
+	"base-gin/storage"
+	"base-gin/util"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EntityVersionRepository struct {
+	db *gorm.DB
+}
+
+func newEntityVersionRepository(db *gorm.DB) *EntityVersionRepository {
+	return &EntityVersionRepository{db: db}
+}
+
+// Create saves newItem as the next version of its record.
+func (r *EntityVersionRepository) Create(newItem *dao.EntityVersion) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createVersion(tx, newItem)
+	})
+}
+
+// createVersion saves newItem as the next version of its record within tx, so
+// repositories can save it together with the update it comes from. A nil
+// newItem is skipped.
+func createVersion(tx *gorm.DB, newItem *dao.EntityVersion) error {
+	if newItem == nil {
+		return nil
+	}
+
+	var last uint
+	err := tx.Model(&dao.EntityVersion{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("entity = ? AND entity_id = ?", newItem.Entity, newItem.EntityID).
+		Select("COALESCE(MAX(version), 0)").Scan(&last).Error
+	if err != nil {
+		return err
+	}
+
+	newItem.Version = last + 1
+	return tx.Create(newItem).Error
+}
+
+// GetList returns the versions of a record, newest first.
+func (r *EntityVersionRepository) GetList(entity domain.TypeAuditEntity, entityID uint, params *dto.Filter) ([]dao.EntityVersion, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var items []dao.EntityVersion
+	tx := r.db.WithContext(ctx).Preload("Actor").
+		Where("entity = ? AND entity_id = ?", entity, entityID)
+
+	if params.Start >= 0 {
+		tx = tx.Offset(params.Start)
+	}
+	if params.Limit > 0 {
+		tx = tx.Limit(params.Limit)
+	}
+
+	tx = tx.Order("version DESC").Find(&items)
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		return nil, tx.Error
+	}
+
+	return items, nil
+}
+
+func (r *EntityVersionRepository) GetByVersion(entity domain.TypeAuditEntity, entityID, version uint) (*dao.EntityVersion, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var item dao.EntityVersion
+	tx := r.db.WithContext(ctx).
+		Where("entity = ? AND entity_id = ? AND version = ?", entity, entityID, version).
+		First(&item)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, exception.ErrVersionNotFound
+		}
+
+		return nil, tx.Error
+	}
+
+	return &item, nil
+}
+
+// Reencrypt rewrites the data of every version with the current key,
+// batchSize versions at a time, like PersonRepository.Reencrypt. It returns
+// the number of versions rewritten.
+func (r *EntityVersionRepository) Reencrypt(batchSize int) (int64, error) {
+	var count int64
+	var lastID uint
+
+	for {
+		items, err := r.getRawBatch(lastID, batchSize)
+		if err != nil {
+			return count, err
+		}
+
+		cipher := util.GetPIICipher()
+		for _, raw := range items {
+			lastID = raw.ID
+			if cipher.IsCurrent(raw.Data) {
+				continue
+			}
+
+			if err := r.reencrypt(raw.ID); err != nil {
+				return count, err
+			}
+			count++
+		}
+
+		if len(items) < batchSize {
+			return count, nil
+		}
+	}
+}
+
+// rawVersionData holds the encrypted data as it is stored.
+type rawVersionData struct {
+	ID   uint
+	Data string
+}
+
+func (r *EntityVersionRepository) getRawBatch(afterID uint, batchSize int) ([]rawVersionData, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var items []rawVersionData
+	tx := r.db.WithContext(ctx).Table("entity_versions").
+		Select("id", "data").
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(batchSize).
+		Find(&items)
+
+	return items, tx.Error
+}
+
+func (r *EntityVersionRepository) reencrypt(id uint) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var item dao.EntityVersion
+	if err := r.db.WithContext(ctx).First(&item, id).Error; err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).Model(&dao.EntityVersion{}).
+		Where("id = ?", id).
+		Select("data").
+		Updates(&item).Error
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"base-gin/app/domain/dao"
+	"base-gin/exception"
+	"base-gin/storage"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ExternalIdentityRepository struct {
+	db *gorm.DB
+}
+
+func newExternalIdentityRepository(db *gorm.DB) *ExternalIdentityRepository {
+	return &ExternalIdentityRepository{db: db}
+}
+
+// GetByIssuerSubject returns the identity together with its account.
+func (r *ExternalIdentityRepository) GetByIssuerSubject(issuer, subject string) (dao.ExternalIdentity, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var item dao.ExternalIdentity
+	tx := r.db.WithContext(ctx).Preload("Account").
+		Where("issuer = ? AND subject = ?", issuer, subject).
+		First(&item)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return item, exception.ErrDataNotFound
+		}
+
+		return item, tx.Error
+	}
+
+	return item, nil
+}
+
+// Touch records a login through the identity and the email it came with, if
+// any.
+func (r *ExternalIdentityRepository) Touch(id uint, email string) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	updates := map[string]any{"last_login_at": time.Now().UTC()}
+	if email != "" {
+		updates["email"] = email
+	}
+
+	tx := r.db.WithContext(ctx).Model(&dao.ExternalIdentity{}).
+		Where("id = ?", id).
+		Updates(updates)
+
+	return tx.Error
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"base-gin/app/domain/dao"
+	"base-gin/storage"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LoginAttemptRepository is a storage.AttemptStore backed by the database, so
+// the counters are shared by every instance of the app.
+type LoginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func newLoginAttemptRepository(db *gorm.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db}
+}
+
+func (r *LoginAttemptRepository) Hit(key string, ttl time.Duration) (int, time.Time, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	now := time.Now().UTC()
+	tx := r.db.WithContext(ctx).Exec(
+		"INSERT INTO login_attempts (throttle_key, count, expired_at) VALUES (?, 1, ?) "+
+			"ON DUPLICATE KEY UPDATE "+
+			"count = IF(expired_at <= ?, 1, count + 1), "+
+			"expired_at = IF(expired_at <= ?, VALUES(expired_at), expired_at)",
+		key, now.Add(ttl), now, now,
+	)
+	if tx.Error != nil {
+		return 0, time.Time{}, tx.Error
+	}
+
+	var item dao.LoginAttempt
+	tx = r.db.WithContext(ctx).Where(dao.LoginAttempt{ThrottleKey: key}).
+		First(&item)
+	if tx.Error != nil {
+		return 0, time.Time{}, tx.Error
+	}
+
+	return item.Count, item.ExpiredAt, nil
+}
+
+func (r *LoginAttemptRepository) Get(key string) (int, time.Time, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var item dao.LoginAttempt
+	tx := r.db.WithContext(ctx).
+		Where("throttle_key = ? AND expired_at > ?", key, time.Now().UTC()).
+		Limit(1).Find(&item)
+	if tx.Error != nil {
+		return 0, time.Time{}, tx.Error
+	}
+
+	return item.Count, item.ExpiredAt, nil
+}
+
+func (r *LoginAttemptRepository) Reset(key string) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Where(dao.LoginAttempt{ThrottleKey: key}).
+		Delete(&dao.LoginAttempt{})
+
+	return tx.Error
+}
+
+// PurgeExpired removes counters whose window has ended.
+func (r *LoginAttemptRepository) PurgeExpired() (int64, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).
+		Where("expired_at < ?", time.Now().UTC()).
+		Delete(&dao.LoginAttempt{})
+
+	return tx.RowsAffected, tx.Error
+}
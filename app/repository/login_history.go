@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"base-gin/app/domain/dao"
+	"base-gin/app/domain/dto"
+	"base-gin/storage"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LoginHistoryRepository struct {
+	db *gorm.DB
+}
+
+func newLoginHistoryRepository(db *gorm.DB) *LoginHistoryRepository {
+	return &LoginHistoryRepository{db: db}
+}
+
+func (r *LoginHistoryRepository) Create(newItem *dao.LoginHistory) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Create(newItem)
+
+	return tx.Error
+}
+
+// GetListByAccountID returns an account's login attempts, newest first.
+func (r *LoginHistoryRepository) GetListByAccountID(accountID uint, params *dto.Filter) ([]dao.LoginHistory, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var items []dao.LoginHistory
+	tx := r.db.WithContext(ctx).Where("account_id = ?", accountID)
+
+	if params.Start >= 0 {
+		tx = tx.Offset(params.Start)
+	}
+	if params.Limit > 0 {
+		tx = tx.Limit(params.Limit)
+	}
+
+	tx = tx.Order("id DESC").Find(&items)
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		return nil, tx.Error
+	}
+
+	return items, nil
+}
+
+// PurgeBefore removes attempts recorded before t.
+func (r *LoginHistoryRepository) PurgeBefore(t time.Time) (int64, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).
+		Where("created_at < ?", t).
+		Delete(&dao.LoginHistory{})
+
+	return tx.RowsAffected, tx.Error
+}
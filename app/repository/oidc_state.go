@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"base-gin/app/domain/dao"
+	"base-gin/exception"
+	"base-gin/storage"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type OIDCStateRepository struct {
+	db *gorm.DB
+}
+
+func newOIDCStateRepository(db *gorm.DB) *OIDCStateRepository {
+	return &OIDCStateRepository{db: db}
+}
+
+func (r *OIDCStateRepository) Create(newItem *dao.OIDCState) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Create(newItem)
+
+	return tx.Error
+}
+
+// Consume deletes a state and returns it. A state that is unknown, expired,
+// already consumed or started by another client gives
+// exception.ErrOIDCStateInvalid. A wrong binding leaves the state for its
+// own client.
+func (r *OIDCStateRepository) Consume(state, bindingHash string) (dao.OIDCState, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var item dao.OIDCState
+	tx := r.db.WithContext(ctx).Where("state = ? AND binding_hash = ?", state, bindingHash).Limit(1).Find(&item)
+	if tx.Error != nil {
+		return item, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return item, exception.ErrOIDCStateInvalid
+	}
+
+	// Only the request that deletes the row may use it.
+	tx = r.db.WithContext(ctx).Delete(&dao.OIDCState{}, item.ID)
+	if tx.Error != nil {
+		return item, tx.Error
+	}
+	if tx.RowsAffected == 0 || item.ExpiredAt.Before(time.Now().UTC()) {
+		return item, exception.ErrOIDCStateInvalid
+	}
+
+	return item, nil
+}
+
+// PurgeExpired removes states whose callback never arrived.
+func (r *OIDCStateRepository) PurgeExpired() (int64, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).
+		Where("expired_at < ?", time.Now().UTC()).
+		Delete(&dao.OIDCState{})
+
+	return tx.RowsAffected, tx.Error
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"base-gin/app/domain/dao"
+	"base-gin/exception"
+	"base-gin/storage"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository struct {
+	db *gorm.DB
+}
+
+func newPasswordResetRepository(db *gorm.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+// Replace stores a new reset code and spends every unused code the account
+// still has, so only the latest code can be redeemed.
+func (r *PasswordResetRepository) Replace(newItem *dao.PasswordReset) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&dao.PasswordReset{}).
+			Where("account_id = ? AND used_at IS NULL", newItem.AccountID).
+			Update("used_at", time.Now().UTC()).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(newItem).Error
+	})
+}
+
+// GetActive returns the account's latest code that is neither spent nor
+// expired.
+func (r *PasswordResetRepository) GetActive(accountID uint) (dao.PasswordReset, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var item dao.PasswordReset
+	tx := r.db.WithContext(ctx).
+		Where("account_id = ? AND used_at IS NULL AND expired_at > ?",
+			accountID, time.Now().UTC()).
+		Order("id DESC").
+		First(&item)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return item, exception.ErrDataNotFound
+		}
+
+		return item, tx.Error
+	}
+
+	return item, nil
+}
+
+func (r *PasswordResetRepository) AddAttempt(id uint) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Model(&dao.PasswordReset{}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1"))
+
+	return tx.Error
+}
+
+// MarkUsed spends a code. It fails with ErrDataNotFound when the code was
+// already spent, so a code can not be redeemed twice.
+func (r *PasswordResetRepository) MarkUsed(id uint) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Model(&dao.PasswordReset{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now().UTC())
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return exception.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// PurgeExpired removes codes that can no longer be redeemed.
+func (r *PasswordResetRepository) PurgeExpired() (int64, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).
+		Where("expired_at < ?", time.Now().UTC()).
+		Delete(&dao.PasswordReset{})
+
+	return tx.RowsAffected, tx.Error
+}
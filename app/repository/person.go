@@ -1,14 +1,18 @@
 package repository
 
 import (
+	"base-gin/app/domain"
 	"base-gin/app/domain/dao"
 	"base-gin/app/domain/dto"
 	"base-gin/exception"
 	"base-gin/storage"
+	"base-gin/util"
 	"errors"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PersonRepository struct {
@@ -66,7 +70,7 @@ func (r *PersonRepository) GetByID(id uint) (*dao.Person, error) {
 	return &item, nil
 }
 
-func (r *PersonRepository) GetList(params *dto.Filter) ([]dao.Person, error) {
+func (r *PersonRepository) GetList(params *dto.PersonFilter) ([]dao.Person, error) {
 	ctx, cancelFunc := storage.NewDBContext()
 	defer cancelFunc()
 
@@ -77,6 +81,9 @@ func (r *PersonRepository) GetList(params *dto.Filter) ([]dao.Person, error) {
 		q := fmt.Sprintf("%%%s%%", params.Keyword)
 		tx = tx.Where("fullname LIKE ?", q)
 	}
+	if params.Email != "" {
+		tx = tx.Where("email_index = ?", util.PIIBlindIndex(params.Email))
+	}
 	if params.Start >= 0 {
 		tx = tx.Offset(params.Start)
 	}
@@ -92,17 +99,321 @@ func (r *PersonRepository) GetList(params *dto.Filter) ([]dao.Person, error) {
 	return items, nil
 }
 
-func (r *PersonRepository) Update(params *dto.PersonUpdateReq) error {
+// Update saves a person's detail together with version, the person as it
+// was. Version may be nil.
+func (r *PersonRepository) Update(params *dto.PersonUpdateReq, version *dao.EntityVersion) error {
 	ctx, cancelFunc := storage.NewDBContext()
 	defer cancelFunc()
 
-	tx := r.db.WithContext(ctx).Model(&dao.Person{}).
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := updatePersonDetail(tx, params); err != nil {
+			return err
+		}
+
+		return createVersion(tx, version)
+	})
+}
+
+// UpdateContact saves a person's email & phone, where nil clears them, like
+// Update. A changed email is no longer verified.
+func (r *PersonRepository) UpdateContact(id uint, email, phone *string, emailChanged bool, version *dao.EntityVersion) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var contact dao.Person
+	contact.SetContact(email, phone)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := updatePersonContact(tx, id, &contact, emailChanged); err != nil {
+			return err
+		}
+
+		return createVersion(tx, version)
+	})
+}
+
+// RestoreVersion saves the detail of an earlier version and, unless contact is nil,
+// its email & phone set with dao.Person.SetContact, in one transaction with
+// version, the person as it was.
+func (r *PersonRepository) RestoreVersion(params *dto.PersonUpdateReq, contact *dao.Person, emailChanged bool, version *dao.EntityVersion) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := updatePersonDetail(tx, params); err != nil {
+			return err
+		}
+		if contact != nil {
+			if err := updatePersonContact(tx, params.ID, contact, emailChanged); err != nil {
+				return err
+			}
+		}
+
+		return createVersion(tx, version)
+	})
+}
+
+func updatePersonDetail(tx *gorm.DB, params *dto.PersonUpdateReq) error {
+	// A struct, not a map, so the birth date goes through the pii serializer.
+	gender := params.GetGender()
+	res := tx.Model(&dao.Person{}).
 		Where("id = ?", params.ID).
-		Updates(map[string]interface{}{
-			"fullname":   params.Fullname,
-			"gender":     params.GetGender(),
-			"birth_date": params.BirthDate,
+		Select("fullname", "gender", "birth_date", "updated_at").
+		Updates(&dao.Person{
+			Model:     gorm.Model{UpdatedAt: time.Now()},
+			Fullname:  params.Fullname,
+			Gender:    &gender,
+			BirthDate: &params.BirthDate,
 		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return exception.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func updatePersonContact(tx *gorm.DB, id uint, contact *dao.Person, emailChanged bool) error {
+	contact.UpdatedAt = time.Now()
+
+	columns := []interface{}{"email_index", "phone", "phone_index", "updated_at"}
+	if emailChanged {
+		columns = append(columns, "email_verified_at")
+	}
+
+	res := tx.Model(&dao.Person{}).
+		Where("id = ?", id).
+		Select("email", columns...).
+		Updates(contact)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return exception.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// SetEmailVerified marks the person's email, given by its blind index, as
+// verified, like Update. It fails with ErrDataNotFound when the email has
+// changed in the meantime.
+func (r *PersonRepository) SetEmailVerified(id uint, emailIndex string, version *dao.EntityVersion) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&dao.Person{}).
+			Where("id = ? AND email_index = ?", id, emailIndex).
+			Update("email_verified_at", time.Now().UTC())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return exception.ErrDataNotFound
+		}
+
+		return createVersion(tx, version)
+	})
+}
+
+// Delete soft-deletes a person. A person linked to an account is anonymized
+// with the account instead, and one with a book still borrowed is kept.
+func (r *PersonRepository) Delete(id uint) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var item dao.Person
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&item, id).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return exception.ErrUserNotFound
+			}
+			return err
+		}
+		if item.AccountID != nil {
+			return exception.ErrPersonHasAccount
+		}
+
+		var openCount int64
+		err = tx.Model(&dao.Borrowing{}).
+			Where("person_id = ? AND return_date IS NULL", id).
+			Count(&openCount).Error
+		if err != nil {
+			return err
+		}
+		if openCount > 0 {
+			return exception.ErrPersonBorrowing
+		}
+
+		return tx.Delete(&item).Error
+	})
+}
+
+// GetTrash returns the soft-deleted persons, most recently deleted first.
+func (r *PersonRepository) GetTrash(params *dto.Filter) ([]dao.Person, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var items []dao.Person
+	tx := r.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL")
+
+	if params.Keyword != "" {
+		q := fmt.Sprintf("%%%s%%", params.Keyword)
+		tx = tx.Where("fullname LIKE ?", q)
+	}
+	if params.Start >= 0 {
+		tx = tx.Offset(params.Start)
+	}
+	if params.Limit > 0 {
+		tx = tx.Limit(params.Limit)
+	}
+
+	tx = tx.Order("deleted_at DESC").Find(&items)
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		return nil, tx.Error
+	}
+
+	return items, nil
+}
+
+// Restore takes a soft-deleted person out of the trash.
+func (r *PersonRepository) Restore(id uint) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Unscoped().Model(&dao.Person{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return exception.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// PurgeDeletedBefore removes the persons soft-deleted before t for good,
+// together with their verification codes and versions. Persons with
+// borrowings are kept, returned or not, as the borrowings refer to them. It
+// returns the IDs of the persons removed.
+func (r *PersonRepository) PurgeDeletedBefore(t time.Time) ([]uint, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var ids []uint
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&dao.Person{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("deleted_at < ?", t).
+			Where("NOT EXISTS (SELECT 1 FROM borrowings WHERE borrowings.person_id = persons.id)").
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		err = tx.Where("person_id IN ?", ids).Delete(&dao.EmailVerification{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("entity = ? AND entity_id IN ?", domain.EntityPerson, ids).
+			Delete(&dao.EntityVersion{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(&dao.Person{}, ids).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// Reencrypt rewrites the encrypted fields of every person, soft-deleted ones
+// included, with the current key, batchSize persons at a time. Persons
+// already encrypted with the current key are skipped. It returns the number
+// of persons rewritten.
+func (r *PersonRepository) Reencrypt(batchSize int) (int64, error) {
+	var count int64
+	var lastID uint
+
+	for {
+		items, err := r.getRawBatch(lastID, batchSize)
+		if err != nil {
+			return count, err
+		}
+
+		for _, raw := range items {
+			lastID = raw.ID
+			if raw.isCurrent() {
+				continue
+			}
+
+			if err := r.reencrypt(raw.ID); err != nil {
+				return count, err
+			}
+			count++
+		}
+
+		if len(items) < batchSize {
+			return count, nil
+		}
+	}
+}
+
+// rawPersonPII holds the encrypted columns as they are stored.
+type rawPersonPII struct {
+	ID        uint
+	BirthDate *string
+	Email     *string
+	Phone     *string
+}
+
+func (p *rawPersonPII) isCurrent() bool {
+	cipher := util.GetPIICipher()
+	for _, value := range []*string{p.BirthDate, p.Email, p.Phone} {
+		if value != nil && !cipher.IsCurrent(*value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *PersonRepository) getRawBatch(afterID uint, batchSize int) ([]rawPersonPII, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var items []rawPersonPII
+	tx := r.db.WithContext(ctx).Table(dao.Person{}.TableName()).
+		Select("id", "birth_date", "email", "phone").
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(batchSize).
+		Find(&items)
+
+	return items, tx.Error
+}
+
+func (r *PersonRepository) reencrypt(id uint) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var item dao.Person
+	if err := r.db.WithContext(ctx).Unscoped().First(&item, id).Error; err != nil {
+		return err
+	}
+	item.SetContact(item.Email, item.Phone)
 
-	return tx.Error
+	return r.db.WithContext(ctx).Unscoped().Model(&dao.Person{}).
+		Where("id = ?", id).
+		Select("birth_date", "email", "email_index", "phone", "phone_index").
+		Updates(&item).Error
 }
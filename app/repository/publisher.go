@@ -1,10 +1,18 @@
 package repository
 
 import (
+	"base-gin/app/domain"
 	"base-gin/app/domain/dao"
+	"base-gin/app/domain/dto"
+	"base-gin/exception"
 	"base-gin/storage"
+	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PublisherRepository struct {
@@ -21,8 +29,208 @@ func (r *PublisherRepository) Create(newItem *dao.Publisher) error {
 
 	tx := r.db.WithContext(ctx).Create(&newItem)
 	if tx.Error != nil {
+		if isDuplicateEntry(tx.Error) {
+			return r.nameConflict(ctx, newItem.Name)
+		}
+
 		return tx.Error
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+func (r *PublisherRepository) GetByID(id uint) (*dao.Publisher, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var item dao.Publisher
+	tx := r.db.WithContext(ctx).First(&item, id)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, exception.ErrDataNotFound
+		}
+
+		return nil, tx.Error
+	}
+
+	return &item, nil
+}
+
+func (r *PublisherRepository) GetList(params *dto.Filter) ([]dao.Publisher, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var items []dao.Publisher
+	tx := r.db.WithContext(ctx)
+
+	if params.Keyword != "" {
+		q := fmt.Sprintf("%%%s%%", params.Keyword)
+		tx = tx.Where("name LIKE ? OR city LIKE ?", q, q)
+	}
+	if params.Start >= 0 {
+		tx = tx.Offset(params.Start)
+	}
+	if params.Limit > 0 {
+		tx = tx.Limit(params.Limit)
+	}
+
+	tx = tx.Order("name ASC").Find(&items)
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		return nil, tx.Error
+	}
+
+	return items, nil
+}
+
+// Update saves a publisher together with version, the publisher as it was.
+func (r *PublisherRepository) Update(params *dto.PublisherUpdateReq, version *dao.EntityVersion) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&dao.Publisher{}).
+			Where("id = ?", params.ID).
+			Updates(map[string]interface{}{
+				"name": params.Name,
+				"city": params.City,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return exception.ErrDataNotFound
+		}
+
+		return createVersion(tx, version)
+	})
+	if err != nil && isDuplicateEntry(err) {
+		return r.nameConflict(ctx, params.Name)
+	}
+
+	return err
+}
+
+// nameConflict tells whether a duplicate name belongs to a publisher in the
+// trash. Names stay unique across the trash, so that one has to be restored
+// instead of created again.
+func (r *PublisherRepository) nameConflict(ctx context.Context, name string) error {
+	var count int64
+	err := r.db.WithContext(ctx).Unscoped().Model(&dao.Publisher{}).
+		Where("name = ? AND deleted_at IS NOT NULL", name).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return exception.ErrPublisherTrashed
+	}
+
+	return exception.ErrPublisherConflict
+}
+
+// Delete soft-deletes a publisher that no book refers to anymore.
+func (r *PublisherRepository) Delete(id uint) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var item dao.Publisher
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&item, id).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return exception.ErrDataNotFound
+			}
+			return err
+		}
+
+		var bookCount int64
+		err = tx.Model(&dao.Book{}).Where("publisher_id = ?", id).Count(&bookCount).Error
+		if err != nil {
+			return err
+		}
+		if bookCount > 0 {
+			return exception.ErrPublisherHasBooks
+		}
+
+		return tx.Delete(&item).Error
+	})
+}
+
+// GetTrash returns the soft-deleted publishers, most recently deleted first.
+func (r *PublisherRepository) GetTrash(params *dto.Filter) ([]dao.Publisher, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var items []dao.Publisher
+	tx := r.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL")
+
+	if params.Keyword != "" {
+		q := fmt.Sprintf("%%%s%%", params.Keyword)
+		tx = tx.Where("name LIKE ? OR city LIKE ?", q, q)
+	}
+	if params.Start >= 0 {
+		tx = tx.Offset(params.Start)
+	}
+	if params.Limit > 0 {
+		tx = tx.Limit(params.Limit)
+	}
+
+	tx = tx.Order("deleted_at DESC").Find(&items)
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		return nil, tx.Error
+	}
+
+	return items, nil
+}
+
+// Restore takes a soft-deleted publisher out of the trash.
+func (r *PublisherRepository) Restore(id uint) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Unscoped().Model(&dao.Publisher{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return exception.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// PurgeDeletedBefore removes the publishers soft-deleted before t for good,
+// together with their versions. Publishers of a book are kept, even a
+// deleted one, as the book refers to them. It returns the IDs of the
+// publishers removed.
+func (r *PublisherRepository) PurgeDeletedBefore(t time.Time) ([]uint, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var ids []uint
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&dao.Publisher{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("deleted_at < ?", t).
+			Where("NOT EXISTS (SELECT 1 FROM books WHERE books.publisher_id = publishers.id)").
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		err = tx.Where("entity = ? AND entity_id IN ?", domain.EntityPublisher, ids).
+			Delete(&dao.EntityVersion{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(&dao.Publisher{}, ids).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
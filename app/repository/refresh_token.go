@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"base-gin/app/domain/dao"
+	"base-gin/exception"
+	"base-gin/storage"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func newRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+func (r *RefreshTokenRepository) Create(newItem *dao.RefreshToken) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Create(&newItem)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	return nil
+}
+
+// Consume marks a refresh token as used. It returns exception.ErrTokenReused
+// when the token has been used before, and exception.ErrTokenRevoked when the
+// token is unknown, revoked or expired.
+func (r *RefreshTokenRepository) Consume(tokenID string) (dao.RefreshToken, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var item dao.RefreshToken
+	tx := r.db.WithContext(ctx).Where(dao.RefreshToken{TokenID: tokenID}).
+		Limit(1).Find(&item)
+	if tx.Error != nil {
+		return item, tx.Error
+	}
+	if tx.RowsAffected == 0 || item.RevokedAt != nil ||
+		item.ExpiredAt.Before(time.Now().UTC()) {
+		return item, exception.ErrTokenRevoked
+	}
+
+	tx = r.db.WithContext(ctx).Model(&dao.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", item.ID).
+		Update("used_at", time.Now().UTC())
+	if tx.Error != nil {
+		return item, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return item, exception.ErrTokenReused
+	}
+
+	return item, nil
+}
+
+// RevokeFamily revokes every refresh token that descends from the same login.
+func (r *RefreshTokenRepository) RevokeFamily(family string) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Model(&dao.RefreshToken{}).
+		Where("family = ? AND revoked_at IS NULL", family).
+		Update("revoked_at", time.Now().UTC())
+
+	return tx.Error
+}
+
+// RevokeAccount revokes every refresh token of an account.
+func (r *RefreshTokenRepository) RevokeAccount(accountID uint) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Model(&dao.RefreshToken{}).
+		Where("account_id = ? AND revoked_at IS NULL", accountID).
+		Update("revoked_at", time.Now().UTC())
+
+	return tx.Error
+}
+
+// PurgeExpired removes refresh tokens that can no longer be used.
+func (r *RefreshTokenRepository) PurgeExpired() (int64, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).
+		Where("expired_at < ?", time.Now().UTC()).
+		Delete(&dao.RefreshToken{})
+
+	return tx.RowsAffected, tx.Error
+}
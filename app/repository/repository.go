@@ -1,11 +1,33 @@
 package repository
 
-import "base-gin/storage"
+import (
+	"base-gin/storage"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const mysqlErrDuplicateEntry = 1062
 
 var (
-	accountRepo *AccountRepository
-	personRepo  *PersonRepository
+	accountRepo   *AccountRepository
+	personRepo    *PersonRepository
 	publisherRepo *PublisherRepository
+	authorRepo    *AuthorRepository
+	bookRepo      *BookRepository
+	borrowingRepo *BorrowingRepository
+	refreshRepo   *RefreshTokenRepository
+	revokedRepo   *RevokedTokenRepository
+	attemptRepo   *LoginAttemptRepository
+	resetRepo     *PasswordResetRepository
+	historyRepo   *LoginHistoryRepository
+	sessionRepo   *SessionRepository
+	apiKeyRepo    *APIKeyRepository
+	identityRepo  *ExternalIdentityRepository
+	oidcStateRepo *OIDCStateRepository
+	verifyRepo    *EmailVerificationRepository
+	auditRepo     *AuditLogRepository
+	versionRepo   *EntityVersionRepository
 )
 
 func SetupRepositories() {
@@ -13,6 +35,21 @@ func SetupRepositories() {
 	accountRepo = newAccountRepository(db)
 	personRepo = newPersonRepository(db)
 	publisherRepo = newPublisherRepo(db)
+	authorRepo = newAuthorRepository(db)
+	bookRepo = newBookRepository(db)
+	borrowingRepo = newBorrowingRepository(db)
+	refreshRepo = newRefreshTokenRepository(db)
+	revokedRepo = newRevokedTokenRepository(db)
+	attemptRepo = newLoginAttemptRepository(db)
+	resetRepo = newPasswordResetRepository(db)
+	historyRepo = newLoginHistoryRepository(db)
+	sessionRepo = newSessionRepository(db)
+	apiKeyRepo = newAPIKeyRepository(db)
+	identityRepo = newExternalIdentityRepository(db)
+	oidcStateRepo = newOIDCStateRepository(db)
+	verifyRepo = newEmailVerificationRepository(db)
+	auditRepo = newAuditLogRepository(db)
+	versionRepo = newEntityVersionRepository(db)
 }
 
 func GetAccountRepo() *AccountRepository {
@@ -25,4 +62,70 @@ func GetPersonRepo() *PersonRepository {
 
 func GetPublisherRepo() *PublisherRepository {
 	return publisherRepo
-}
\ No newline at end of file
+}
+
+func GetAuthorRepo() *AuthorRepository {
+	return authorRepo
+}
+
+func GetBookRepo() *BookRepository {
+	return bookRepo
+}
+
+func GetBorrowingRepo() *BorrowingRepository {
+	return borrowingRepo
+}
+
+func GetRefreshTokenRepo() *RefreshTokenRepository {
+	return refreshRepo
+}
+
+func GetRevokedTokenRepo() *RevokedTokenRepository {
+	return revokedRepo
+}
+
+func GetLoginAttemptRepo() *LoginAttemptRepository {
+	return attemptRepo
+}
+
+func GetPasswordResetRepo() *PasswordResetRepository {
+	return resetRepo
+}
+
+func GetLoginHistoryRepo() *LoginHistoryRepository {
+	return historyRepo
+}
+
+func GetSessionRepo() *SessionRepository {
+	return sessionRepo
+}
+
+func GetAPIKeyRepo() *APIKeyRepository {
+	return apiKeyRepo
+}
+
+func GetExternalIdentityRepo() *ExternalIdentityRepository {
+	return identityRepo
+}
+
+func GetOIDCStateRepo() *OIDCStateRepository {
+	return oidcStateRepo
+}
+
+func GetEmailVerificationRepo() *EmailVerificationRepository {
+	return verifyRepo
+}
+
+func GetAuditLogRepo() *AuditLogRepository {
+	return auditRepo
+}
+
+func GetEntityVersionRepo() *EntityVersionRepository {
+	return versionRepo
+}
+
+// isDuplicateEntry reports whether err is a MySQL unique constraint violation.
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"base-gin/app/domain/dao"
+	"base-gin/exception"
+	"base-gin/storage"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+func newSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (r *SessionRepository) Create(newItem *dao.Session) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Create(newItem)
+
+	return tx.Error
+}
+
+// Touch records the use of a session's refresh token.
+func (r *SessionRepository) Touch(family, ip string, expiredAt time.Time) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Model(&dao.Session{}).
+		Where("family = ? AND revoked_at IS NULL", family).
+		Updates(map[string]any{
+			"ip_address":   ip,
+			"last_used_at": time.Now().UTC(),
+			"expired_at":   expiredAt,
+		})
+
+	return tx.Error
+}
+
+func (r *SessionRepository) GetByID(id uint) (dao.Session, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var item dao.Session
+	tx := r.db.WithContext(ctx).First(&item, id)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return item, exception.ErrDataNotFound
+		}
+
+		return item, tx.Error
+	}
+
+	return item, nil
+}
+
+// GetActiveByAccountID returns the account's sessions that are neither
+// revoked nor expired, most recently used first.
+func (r *SessionRepository) GetActiveByAccountID(accountID uint) ([]dao.Session, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	var items []dao.Session
+	tx := r.db.WithContext(ctx).
+		Where("account_id = ? AND revoked_at IS NULL AND expired_at > ?",
+			accountID, time.Now().UTC()).
+		Order("last_used_at DESC").
+		Find(&items)
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		return nil, tx.Error
+	}
+
+	return items, nil
+}
+
+func (r *SessionRepository) RevokeFamily(family string) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Model(&dao.Session{}).
+		Where("family = ? AND revoked_at IS NULL", family).
+		Update("revoked_at", time.Now().UTC())
+
+	return tx.Error
+}
+
+func (r *SessionRepository) RevokeAccount(accountID uint) error {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).Model(&dao.Session{}).
+		Where("account_id = ? AND revoked_at IS NULL", accountID).
+		Update("revoked_at", time.Now().UTC())
+
+	return tx.Error
+}
+
+// PurgeExpired removes sessions whose refresh tokens have expired.
+func (r *SessionRepository) PurgeExpired() (int64, error) {
+	ctx, cancelFunc := storage.NewDBContext()
+	defer cancelFunc()
+
+	tx := r.db.WithContext(ctx).
+		Where("expired_at < ?", time.Now().UTC()).
+		Delete(&dao.Session{})
+
+	return tx.RowsAffected, tx.Error
+}
@@ -1,12 +1,20 @@
 package rest
 
 import (
+	"archive/zip"
+	"base-gin/app/domain"
 	"base-gin/app/domain/dto"
 	"base-gin/app/service"
 	"base-gin/exception"
 	"base-gin/server"
+	"base-gin/util"
+	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -29,19 +37,47 @@ func newAccountHandler(
 func (h *AccountHandler) Route(app *gin.Engine) {
 	grp := app.Group(server.RootAccount)
 	grp.POST(server.PathLogin, h.login)
+	grp.POST(server.PathLoginTOTP, h.loginTOTP)
+	grp.POST(server.PathRegister, h.register)
+	grp.POST(server.PathRefresh, h.hr.AuthRefresh(), h.refresh)
+	grp.POST(server.PathLogout, h.hr.AuthAccess(), h.logout)
+	grp.POST(server.PathLogoutAll, h.hr.AuthAccess(), h.logoutAll)
+	grp.PUT(server.PathPassword, h.hr.AuthAccess(), h.changePassword)
+	grp.POST(server.PathForgot, h.forgotPassword)
+	grp.POST(server.PathReset, h.resetPassword)
+	grp.POST(server.PathTOTPSetup, h.hr.AuthAccess(), h.setupTOTP)
+	grp.POST(server.PathTOTPEnable, h.hr.AuthAccess(), h.enableTOTP)
+	grp.POST(server.PathTOTPDisable, h.hr.AuthAccess(), h.disableTOTP)
 	grp.GET("", h.hr.AuthAccess(), h.getProfile)
+	grp.GET(server.PathLogins, h.hr.AuthAccess(), h.getLogins)
+	grp.GET(server.PathExport, h.hr.AuthAccess(), h.export)
+	grp.GET(server.PathSessions, h.hr.AuthAccess(), h.getSessions)
+	grp.DELETE(server.PathSession, h.hr.AuthAccess(), h.revokeSession)
+	grp.POST(server.PathAPIKeys, h.hr.AuthAccess(), h.createAPIKey)
+	grp.GET(server.PathAPIKeys, h.hr.AuthAccess(), h.getAPIKeys)
+	grp.DELETE(server.PathAPIKey, h.hr.AuthAccess(), h.revokeAPIKey)
+	grp.PUT(server.PathRole, h.hr.AuthAccess(),
+		h.hr.RequireRole(domain.RoleAdmin), h.updateRole)
+	grp.POST(server.PathUnlock, h.hr.AuthAccess(),
+		h.hr.RequireRole(domain.RoleAdmin), h.unlock)
+	grp.POST(server.PathAnonymize, h.hr.AuthAccess(),
+		h.hr.RequireRole(domain.RoleAdmin), h.anonymize)
 }
 
 // login godoc
 //
 //	@Summary Account login
 //	@Description Account login using username & password combination.
+//	@Description When two-factor authentication is enabled, only a challenge
+//	@Description token is returned; exchange it at /accounts/login/2fa.
 //	@Accept json
 //	@Produce json
 //	@Param cred body dto.AccountLoginReq true "Credential"
 //	@Success 200 {object} dto.SuccessResponse[dto.AccountLoginResp]
 //	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
 //	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 429 {object} dto.ErrorResponse
 //	@Failure 500 {object} dto.ErrorResponse
 //	@Router /accounts/login [post]
 func (h *AccountHandler) login(c *gin.Context) {
@@ -51,12 +87,78 @@ func (h *AccountHandler) login(c *gin.Context) {
 		return
 	}
 
-	data, err := h.service.Login(req)
+	data, err := h.service.Login(req, h.hr.ClientInfo(c))
 	if err != nil {
+		var throttleErr *exception.ThrottleError
 		switch {
+		case errors.As(err, &throttleErr):
+			retryAfter := int(math.Ceil(throttleErr.RetryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(http.StatusTooManyRequests, h.hr.ErrorResponse(err.Error()))
 		case errors.Is(err, exception.ErrUserNotFound),
 			errors.Is(err, exception.ErrUserLoginFailed):
 			c.JSON(http.StatusBadRequest, h.hr.ErrorResponse(exception.ErrUserLoginFailed.Error()))
+		case errors.Is(err, exception.ErrUserLocked):
+			c.JSON(http.StatusForbidden, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+		return
+	}
+
+	message := "Login berhasil"
+	if data.ChallengeToken != "" {
+		message = "Masukkan kode autentikasi dua faktor"
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[dto.AccountLoginResp]{
+		Success: true,
+		Message: message,
+		Data:    data,
+	})
+}
+
+// loginTOTP godoc
+//
+//	@Summary Account login, second step
+//	@Description Exchange a login challenge token and a TOTP or recovery code
+//	@Description for an access & refresh token pair. A challenge token can only
+//	@Description be used once.
+//	@Accept json
+//	@Produce json
+//	@Param cred body dto.AccountLoginTOTPReq true "Challenge token & code"
+//	@Success 200 {object} dto.SuccessResponse[dto.AccountLoginResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 429 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/login/2fa [post]
+func (h *AccountHandler) loginTOTP(c *gin.Context) {
+	var req dto.AccountLoginTOTPReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	data, err := h.service.LoginTOTP(&req, h.hr.ClientInfo(c))
+	if err != nil {
+		var throttleErr *exception.ThrottleError
+		switch {
+		case errors.As(err, &throttleErr):
+			retryAfter := int(math.Ceil(throttleErr.RetryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(http.StatusTooManyRequests, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrTOTPInvalid):
+			c.JSON(http.StatusBadRequest, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrUserLocked):
+			c.JSON(http.StatusForbidden, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, util.ErrChallengeTokenFailedToVerify),
+			errors.Is(err, exception.ErrTokenRevoked),
+			errors.Is(err, exception.ErrTOTPNotEnrolled),
+			errors.Is(err, exception.ErrUserNotFound):
+			c.JSON(http.StatusUnauthorized, h.hr.ErrorResponse(err.Error()))
 		default:
 			h.hr.ErrorInternalServer(c, err)
 		}
@@ -70,6 +172,395 @@ func (h *AccountHandler) login(c *gin.Context) {
 	})
 }
 
+// refresh godoc
+//
+//	@Summary Refresh account's tokens
+//	@Description Exchange a refresh token for a new access & refresh token pair.
+//	@Description Every refresh token can only be used once; reusing one revokes
+//	@Description all tokens issued from the same login.
+//	@Produce json
+//	@Security BearerAuth
+//	@Success 200 {object} dto.SuccessResponse[dto.AccountLoginResp]
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/refresh [post]
+func (h *AccountHandler) refresh(c *gin.Context) {
+	username := c.GetString(server.ParamTokenUsername)
+	tokenID := c.GetString(server.ParamTokenID)
+	family := c.GetString(server.ParamTokenFamily)
+
+	data, err := h.service.Refresh(username, tokenID, family, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrTokenReused),
+			errors.Is(err, exception.ErrTokenRevoked),
+			errors.Is(err, exception.ErrUserNotFound):
+			c.JSON(http.StatusUnauthorized, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[dto.AccountLoginResp]{
+		Success: true,
+		Message: "Token berhasil diperbarui",
+		Data:    data,
+	})
+}
+
+// logout godoc
+//
+//	@Summary Account logout
+//	@Description End the current session, revoking its access & refresh tokens.
+//	@Produce json
+//	@Security BearerAuth
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/logout [post]
+func (h *AccountHandler) logout(c *gin.Context) {
+	accountID := c.GetUint(server.ParamTokenUserID)
+	tokenID := c.GetString(server.ParamTokenID)
+	family := c.GetString(server.ParamTokenFamily)
+
+	if err := h.service.Logout(accountID, tokenID, family); err != nil {
+		h.hr.ErrorInternalServer(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Logout berhasil",
+	})
+}
+
+// logoutAll godoc
+//
+//	@Summary Account logout from all devices
+//	@Description Revoke every token issued to the logged-in account.
+//	@Produce json
+//	@Security BearerAuth
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/logout-all [post]
+func (h *AccountHandler) logoutAll(c *gin.Context) {
+	accountID := c.GetUint(server.ParamTokenUserID)
+
+	if err := h.service.LogoutAll(accountID); err != nil {
+		h.hr.ErrorInternalServer(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Logout dari semua perangkat berhasil",
+	})
+}
+
+// changePassword godoc
+//
+//	@Summary Change account's password
+//	@Description Replace the password of the logged-in account. The current
+//	@Description password is required, and the new one must follow the password
+//	@Description policy and differ from recent passwords. Every token of the
+//	@Description account is revoked, so the account has to login again.
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Param detail body dto.AccountPasswordChangeReq true "Current & new password"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/password [put]
+func (h *AccountHandler) changePassword(c *gin.Context) {
+	var req dto.AccountPasswordChangeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	accountID := c.GetUint(server.ParamTokenUserID)
+
+	err := h.service.ChangePassword(accountID, &req)
+	if err != nil {
+		var validationErr *exception.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			c.JSON(h.hr.BindingError(err))
+		case errors.Is(err, exception.ErrPasswordMismatch):
+			c.JSON(http.StatusBadRequest, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Password berhasil diubah, silakan login kembali",
+	})
+}
+
+// forgotPassword godoc
+//
+//	@Summary Request a password reset code
+//	@Description Send a one-time reset code to the account holder. A new code
+//	@Description replaces any code sent before. The response is the same
+//	@Description whether or not the username exists.
+//	@Accept json
+//	@Produce json
+//	@Param detail body dto.AccountPasswordForgotReq true "Username"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 429 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/password/forgot [post]
+func (h *AccountHandler) forgotPassword(c *gin.Context) {
+	var req dto.AccountPasswordForgotReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	if err := h.service.ForgotPassword(&req, h.hr.ClientInfo(c)); err != nil {
+		var throttleErr *exception.ThrottleError
+		switch {
+		case errors.As(err, &throttleErr):
+			retryAfter := int(math.Ceil(throttleErr.RetryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(http.StatusTooManyRequests, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Kode reset password telah dikirim",
+	})
+}
+
+// resetPassword godoc
+//
+//	@Summary Reset account's password
+//	@Description Set a new password using a reset code. A code can only be
+//	@Description used once. The new password must follow the password policy
+//	@Description and differ from recent passwords. Every token of the account
+//	@Description is revoked.
+//	@Accept json
+//	@Produce json
+//	@Param detail body dto.AccountPasswordResetReq true "Reset code & new password"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 429 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/password/reset [post]
+func (h *AccountHandler) resetPassword(c *gin.Context) {
+	var req dto.AccountPasswordResetReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	err := h.service.ResetPassword(&req, h.hr.ClientInfo(c))
+	if err != nil {
+		var throttleErr *exception.ThrottleError
+		var validationErr *exception.ValidationError
+		switch {
+		case errors.As(err, &throttleErr):
+			retryAfter := int(math.Ceil(throttleErr.RetryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(http.StatusTooManyRequests, h.hr.ErrorResponse(err.Error()))
+		case errors.As(err, &validationErr):
+			c.JSON(h.hr.BindingError(err))
+		case errors.Is(err, exception.ErrResetCodeInvalid):
+			c.JSON(http.StatusBadRequest, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Password berhasil direset, silakan login kembali",
+	})
+}
+
+// setupTOTP godoc
+//
+//	@Summary Start two-factor authentication enrollment
+//	@Description Generate a TOTP secret and its otpauth:// provisioning URI, to be
+//	@Description shown as a QR code. Login is unchanged until the secret is
+//	@Description confirmed at /accounts/2fa/enable.
+//	@Produce json
+//	@Security BearerAuth
+//	@Success 200 {object} dto.SuccessResponse[dto.AccountTOTPSetupResp]
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 409 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/2fa/setup [post]
+func (h *AccountHandler) setupTOTP(c *gin.Context) {
+	accountID := c.GetUint(server.ParamTokenUserID)
+
+	data, err := h.service.SetupTOTP(accountID)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrTOTPEnabled):
+			c.JSON(http.StatusConflict, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[dto.AccountTOTPSetupResp]{
+		Success: true,
+		Message: "Pindai kode QR dengan aplikasi autentikator",
+		Data:    data,
+	})
+}
+
+// enableTOTP godoc
+//
+//	@Summary Enable two-factor authentication
+//	@Description Confirm enrollment with a code from the authenticator. The
+//	@Description returned recovery codes are only shown once.
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Param detail body dto.AccountTOTPEnableReq true "TOTP code"
+//	@Success 200 {object} dto.SuccessResponse[dto.AccountTOTPEnableResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 409 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/2fa/enable [post]
+func (h *AccountHandler) enableTOTP(c *gin.Context) {
+	var req dto.AccountTOTPEnableReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	accountID := c.GetUint(server.ParamTokenUserID)
+
+	data, err := h.service.EnableTOTP(accountID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrTOTPInvalid),
+			errors.Is(err, exception.ErrTOTPNotEnrolled):
+			c.JSON(http.StatusBadRequest, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrTOTPEnabled):
+			c.JSON(http.StatusConflict, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[dto.AccountTOTPEnableResp]{
+		Success: true,
+		Message: "Autentikasi dua faktor aktif, simpan kode pemulihan",
+		Data:    data,
+	})
+}
+
+// disableTOTP godoc
+//
+//	@Summary Disable two-factor authentication
+//	@Description Turn two-factor authentication off. Requires the password and a
+//	@Description TOTP or recovery code.
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Param detail body dto.AccountTOTPDisableReq true "Password & code"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/2fa/disable [post]
+func (h *AccountHandler) disableTOTP(c *gin.Context) {
+	var req dto.AccountTOTPDisableReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	accountID := c.GetUint(server.ParamTokenUserID)
+
+	err := h.service.DisableTOTP(accountID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrPasswordMismatch),
+			errors.Is(err, exception.ErrTOTPInvalid),
+			errors.Is(err, exception.ErrTOTPNotEnrolled):
+			c.JSON(http.StatusBadRequest, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Autentikasi dua faktor dinonaktifkan",
+	})
+}
+
+// register godoc
+//
+//	@Summary Account registration
+//	@Description Register a new account together with its person's profile.
+//	@Description The password must follow the password policy.
+//	@Accept json
+//	@Produce json
+//	@Param detail body dto.AccountRegisterReq true "Account & profile"
+//	@Success 201 {object} dto.SuccessResponse[dto.AccountProfileResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 409 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/register [post]
+func (h *AccountHandler) register(c *gin.Context) {
+	var req dto.AccountRegisterReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	data, err := h.service.Register(&req, h.hr.ClientInfo(c))
+	if err != nil {
+		var validationErr *exception.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			c.JSON(h.hr.BindingError(err))
+		case errors.Is(err, exception.ErrDateParsing):
+			c.JSON(http.StatusBadRequest, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrUserConflict):
+			c.JSON(http.StatusConflict, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse[dto.AccountProfileResp]{
+		Success: true,
+		Message: "Registrasi berhasil",
+		Data:    data,
+	})
+}
+
 // getProfile godoc
 //
 //	@Summary Get account's profile
@@ -103,3 +594,483 @@ func (h *AccountHandler) getProfile(c *gin.Context) {
 		Data:    data,
 	})
 }
+
+// getLogins godoc
+//
+//	@Summary Get account's login history
+//	@Description Get recent login attempts of the logged-in account, newest
+//	@Description first, including the client's IP address, user agent and OS.
+//	@Produce json
+//	@Security BearerAuth
+//	@Param s query int false "Data offset"
+//	@Param l query int false "Data limit"
+//	@Success 200 {object} dto.SuccessResponse[[]dto.AccountLoginHistoryResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/logins [get]
+func (h *AccountHandler) getLogins(c *gin.Context) {
+	var req dto.Filter
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	accountID := c.GetUint(server.ParamTokenUserID)
+
+	data, err := h.service.GetLoginHistory(accountID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[[]dto.AccountLoginHistoryResp]{
+		Success: true,
+		Message: "Riwayat login",
+		Data:    data,
+	})
+}
+
+// export godoc
+//
+//	@Summary Export account's data
+//	@Description Download everything kept about the logged-in account: the
+//	@Description account, its person, borrowings and login history. By default
+//	@Description a ZIP archive with one JSON file each is returned.
+//	@Produce json,application/zip
+//	@Security BearerAuth
+//	@Param format query string false "zip (default) or json"
+//	@Success 200 {object} dto.SuccessResponse[dto.AccountExportResp]
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/export [get]
+func (h *AccountHandler) export(c *gin.Context) {
+	var req dto.AccountExportReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	accountID := c.GetUint(server.ParamTokenUserID)
+
+	data, err := h.service.Export(accountID)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	if req.Format == "json" {
+		c.JSON(http.StatusOK, dto.SuccessResponse[dto.AccountExportResp]{
+			Success: true,
+			Message: "Data akun",
+			Data:    data,
+		})
+		return
+	}
+
+	archive, err := exportZip(&data)
+	if err != nil {
+		h.hr.ErrorInternalServer(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("account-%d-%s.zip", data.Account.ID,
+		data.ExportedAt.Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+// exportZip packs each part of an export in its own JSON file.
+func exportZip(data *dto.AccountExportResp) ([]byte, error) {
+	files := []struct {
+		name    string
+		content any
+	}{
+		{"account.json", data.Account},
+		{"person.json", data.Person},
+		{"borrowings.json", data.Borrowings},
+		{"login_history.json", data.LoginHistory},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: data.ExportedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// getSessions godoc
+//
+//	@Summary Get account's active sessions
+//	@Description Get the devices the logged-in account is signed in on, most
+//	@Description recently used first. The session of the current token is
+//	@Description flagged.
+//	@Produce json
+//	@Security BearerAuth
+//	@Success 200 {object} dto.SuccessResponse[[]dto.AccountSessionResp]
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/sessions [get]
+func (h *AccountHandler) getSessions(c *gin.Context) {
+	accountID := c.GetUint(server.ParamTokenUserID)
+	family := c.GetString(server.ParamTokenFamily)
+
+	data, err := h.service.GetSessions(accountID, family)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[[]dto.AccountSessionResp]{
+		Success: true,
+		Message: "Daftar sesi aktif",
+		Data:    data,
+	})
+}
+
+// revokeSession godoc
+//
+//	@Summary Sign a device out
+//	@Description End one of the logged-in account's sessions, revoking its
+//	@Description access & refresh tokens.
+//	@Produce json
+//	@Security BearerAuth
+//	@Param id path int true "Session's ID"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/sessions/{id} [delete]
+func (h *AccountHandler) revokeSession(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	accountID := c.GetUint(server.ParamTokenUserID)
+
+	err = h.service.RevokeSession(accountID, uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrSessionNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Sesi berhasil diakhiri",
+	})
+}
+
+// createAPIKey godoc
+//
+//	@Summary Create an API key
+//	@Description Create a key that acts as the logged-in account when sent in
+//	@Description the X-API-Key header. The key is only shown in this response.
+//	@Description Without expires_in it is valid for API_KEY_TTL days.
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Param detail body dto.AccountAPIKeyCreateReq true "Key's name, scopes & validity in days"
+//	@Success 201 {object} dto.SuccessResponse[dto.AccountAPIKeyResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/api-keys [post]
+func (h *AccountHandler) createAPIKey(c *gin.Context) {
+	var req dto.AccountAPIKeyCreateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	accountID := c.GetUint(server.ParamTokenUserID)
+
+	data, err := h.service.CreateAPIKey(accountID, &req)
+	if err != nil {
+		h.hr.ErrorInternalServer(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse[dto.AccountAPIKeyResp]{
+		Success: true,
+		Message: "API key berhasil dibuat, simpan key ini karena tidak akan ditampilkan lagi",
+		Data:    data,
+	})
+}
+
+// getAPIKeys godoc
+//
+//	@Summary Get account's API keys
+//	@Description Get the logged-in account's API keys that are neither revoked
+//	@Description nor expired, newest first. Only the prefix of each key is shown.
+//	@Produce json
+//	@Security BearerAuth
+//	@Success 200 {object} dto.SuccessResponse[[]dto.AccountAPIKeyResp]
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/api-keys [get]
+func (h *AccountHandler) getAPIKeys(c *gin.Context) {
+	accountID := c.GetUint(server.ParamTokenUserID)
+
+	data, err := h.service.GetAPIKeys(accountID)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[[]dto.AccountAPIKeyResp]{
+		Success: true,
+		Message: "Daftar API key",
+		Data:    data,
+	})
+}
+
+// revokeAPIKey godoc
+//
+//	@Summary Revoke an API key
+//	@Description Stop one of the logged-in account's API keys from being accepted.
+//	@Produce json
+//	@Security BearerAuth
+//	@Param id path int true "API key's ID"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/api-keys/{id} [delete]
+func (h *AccountHandler) revokeAPIKey(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	accountID := c.GetUint(server.ParamTokenUserID)
+
+	err = h.service.RevokeAPIKey(accountID, uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrAPIKeyNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "API key berhasil dicabut",
+	})
+}
+
+// unlock godoc
+//
+//	@Summary Unlock an account
+//	@Description Lift the lock put on an account after repeated failed logins.
+//	@Description Admin only.
+//	@Produce json
+//	@Security BearerAuth
+//	@Param id path int true "Account's ID"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/{id}/unlock [post]
+func (h *AccountHandler) unlock(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	actorID := c.GetUint(server.ParamTokenUserID)
+
+	err = h.service.Unlock(uint(id), actorID, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Akun berhasil dibuka",
+	})
+}
+
+// anonymize godoc
+//
+//	@Summary Anonymize an account
+//	@Description Scrub the personal data of an account and its person on a
+//	@Description data subject's request. Borrowings are kept for statistics,
+//	@Description sessions, API keys and login history are removed, and the
+//	@Description account can no longer be used. Admin only, not for the
+//	@Description admin's own account.
+//	@Produce json
+//	@Security BearerAuth
+//	@Param id path int true "Account's ID"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/{id}/anonymize [post]
+func (h *AccountHandler) anonymize(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	actorID := c.GetUint(server.ParamTokenUserID)
+
+	err = h.service.Anonymize(uint(id), actorID, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrAccountSelf):
+			c.JSON(http.StatusBadRequest, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Data akun berhasil dianonimkan",
+	})
+}
+
+// updateRole godoc
+//
+//	@Summary Update an account's role
+//	@Description Assign member, librarian or admin role to an account. Admin only. The last admin can not be demoted.
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Param id path int true "Account's ID"
+//	@Param role body dto.AccountRoleUpdateReq true "Role"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 409 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/{id}/role [put]
+func (h *AccountHandler) updateRole(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	var req dto.AccountRoleUpdateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+	req.ID = uint(id)
+
+	actorID := c.GetUint(server.ParamTokenUserID)
+
+	err = h.service.UpdateRole(&req, actorID, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrAccountLastAdmin):
+			c.JSON(http.StatusConflict, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Data berhasil disimpan",
+	})
+}
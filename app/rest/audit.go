@@ -0,0 +1,81 @@
+package rest
+
+import (
+	"base-gin/app/domain"
+	"base-gin/app/domain/dto"
+	"base-gin/app/service"
+	"base-gin/exception"
+	"base-gin/server"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	hr      *server.Handler
+	service *service.AuditService
+}
+
+func newAuditHandler(hr *server.Handler, auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{hr: hr, service: auditService}
+}
+
+func (h *AuditHandler) Route(app *gin.Engine) {
+	grp := app.Group(server.RootAudit)
+	grp.GET("", h.hr.AuthAccess(), h.hr.RequireRole(staffRoles...), h.getList)
+}
+
+// getList godoc
+//
+//	@Summary Get the audit log
+//	@Description Get who changed which record, when, from where and how,
+//	@Description newest first. Encrypted fields only show that they changed.
+//	@Description Admins see every record, librarians only changes to persons.
+//	@Produce json
+//	@Security BearerAuth
+//	@Param actor_id query int false "Account that made the change"
+//	@Param entity query string false "account, author, book, borrowing, person or publisher"
+//	@Param entity_id query int false "ID of the changed record"
+//	@Param action query string false "create, update, delete, restore, purge or anonymize"
+//	@Param from query string false "From date (YYYY-MM-DD)"
+//	@Param to query string false "To date (YYYY-MM-DD), inclusive"
+//	@Param s query int false "Data offset"
+//	@Param l query int false "Data limit"
+//	@Success 200 {object} dto.SuccessResponse[[]dto.AuditLogResp]
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /audit [get]
+func (h *AuditHandler) getList(c *gin.Context) {
+	var req dto.AuditFilter
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	role, _ := c.Get(server.ParamTokenRole)
+	accountRole, _ := role.(domain.TypeRole)
+
+	data, err := h.service.GetList(&req, accountRole)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrForbidden):
+			c.JSON(http.StatusForbidden, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[[]dto.AuditLogResp]{
+		Success: true,
+		Message: "Log audit",
+		Data:    data,
+	})
+}
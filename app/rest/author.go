@@ -0,0 +1,260 @@
+package rest
+
+import (
+	"base-gin/app/domain/dto"
+	"base-gin/app/service"
+	"base-gin/exception"
+	"base-gin/server"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthorHandler struct {
+	hr      *server.Handler
+	service *service.AuthorService
+}
+
+func newAuthorHandler(
+	hr *server.Handler,
+	authorService *service.AuthorService,
+) *AuthorHandler {
+	return &AuthorHandler{hr: hr, service: authorService}
+}
+
+func (h *AuthorHandler) Route(app *gin.Engine) {
+	grp := app.Group(server.RootAuthor)
+	grp.GET("", h.getList)
+	grp.GET("/:id", h.getByID)
+	grp.POST("", h.hr.AuthAccessOrAPIKey(), h.hr.RequireRole(staffRoles...), h.create)
+	grp.PUT("/:id", h.hr.AuthAccessOrAPIKey(), h.hr.RequireRole(staffRoles...), h.update)
+	grp.DELETE("/:id", h.hr.AuthAccessOrAPIKey(), h.hr.RequireRole(staffRoles...), h.delete)
+}
+
+// create godoc
+//
+//	@Summary Create a new author
+//	@Description Create a new author.
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param detail body dto.AuthorCreateReq true "Author's detail"
+//	@Success 201 {object} dto.SuccessResponse[dto.AuthorDetailResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /authors [post]
+func (h *AuthorHandler) create(c *gin.Context) {
+	var req dto.AuthorCreateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	actorID := c.GetUint(server.ParamTokenUserID)
+
+	data, err := h.service.Create(&req, actorID, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDateParsing):
+			c.JSON(http.StatusBadRequest, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse[*dto.AuthorDetailResp]{
+		Success: true,
+		Message: "Data penulis berhasil disimpan",
+		Data:    data,
+	})
+}
+
+// getList godoc
+//
+//	@Summary Get a list of author
+//	@Description Get a list of author.
+//	@Produce json
+//	@Param q query string false "Author's name"
+//	@Param s query int false "Data offset"
+//	@Param l query int false "Data limit"
+//	@Success 200 {object} dto.SuccessResponse[[]dto.AuthorDetailResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /authors [get]
+func (h *AuthorHandler) getList(c *gin.Context) {
+	var req dto.Filter
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	data, err := h.service.GetList(&req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[[]dto.AuthorDetailResp]{
+		Success: true,
+		Message: "Daftar penulis",
+		Data:    data,
+	})
+}
+
+// getByID godoc
+//
+//	@Summary Get an author's detail
+//	@Description Get an author's detail.
+//	@Produce json
+//	@Param id path int true "Author's ID"
+//	@Success 200 {object} dto.SuccessResponse[dto.AuthorDetailResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /authors/{id} [get]
+func (h *AuthorHandler) getByID(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	data, err := h.service.GetByID(uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[dto.AuthorDetailResp]{
+		Success: true,
+		Message: "Detail penulis",
+		Data:    data,
+	})
+}
+
+// update godoc
+//
+//	@Summary Update an author's detail
+//	@Description Update an author's detail.
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param id path int true "Author's ID"
+//	@Param detail body dto.AuthorUpdateReq true "Author's detail"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /authors/{id} [put]
+func (h *AuthorHandler) update(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	var req dto.AuthorUpdateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+	req.ID = uint(id)
+
+	actorID := c.GetUint(server.ParamTokenUserID)
+
+	err = h.service.Update(&req, actorID, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDateParsing):
+			c.JSON(http.StatusBadRequest, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Data berhasil disimpan",
+	})
+}
+
+// delete godoc
+//
+//	@Summary Delete an author
+//	@Description Soft-delete an author. It is rejected while books still refer to it.
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param id path int true "Author's ID"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 409 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /authors/{id} [delete]
+func (h *AuthorHandler) delete(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	actorID := c.GetUint(server.ParamTokenUserID)
+
+	err = h.service.Delete(uint(id), actorID, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrAuthorHasBooks):
+			c.JSON(http.StatusConflict, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Data berhasil dihapus",
+	})
+}
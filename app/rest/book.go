@@ -0,0 +1,393 @@
+package rest
+
+import (
+	"base-gin/app/domain/dto"
+	"base-gin/app/service"
+	"base-gin/exception"
+	"base-gin/server"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type BookHandler struct {
+	hr      *server.Handler
+	service *service.BookService
+}
+
+func newBookHandler(
+	hr *server.Handler,
+	bookService *service.BookService,
+) *BookHandler {
+	return &BookHandler{hr: hr, service: bookService}
+}
+
+func (h *BookHandler) Route(app *gin.Engine) {
+	grp := app.Group(server.RootBook)
+	grp.GET("", h.getList)
+	grp.GET("/:id", h.getByID)
+	grp.POST("", h.hr.AuthAccessOrAPIKey(), h.hr.RequireRole(staffRoles...), h.create)
+	grp.PUT("/:id", h.hr.AuthAccessOrAPIKey(), h.hr.RequireRole(staffRoles...), h.update)
+	grp.GET(server.PathVersions, h.hr.AuthAccessOrAPIKey(), h.hr.RequireRole(staffRoles...), h.getVersions)
+	grp.POST(server.PathRestore, h.hr.AuthAccessOrAPIKey(), h.hr.RequireRole(staffRoles...), h.restoreVersion)
+	grp.DELETE("/:id", h.hr.AuthAccessOrAPIKey(), h.hr.RequireRole(staffRoles...), h.delete)
+}
+
+// create godoc
+//
+//	@Summary Create a new book
+//	@Description Create a new book.
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param detail body dto.BookCreateReq true "Book's detail"
+//	@Success 201 {object} dto.SuccessResponse[dto.BookDetailResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /books [post]
+func (h *BookHandler) create(c *gin.Context) {
+	var req dto.BookCreateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	actorID := c.GetUint(server.ParamTokenUserID)
+
+	data, err := h.service.Create(&req, actorID, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrAuthorNotFound),
+			errors.Is(err, exception.ErrPublisherNotFound):
+			c.JSON(http.StatusBadRequest, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse[*dto.BookDetailResp]{
+		Success: true,
+		Message: "Data buku berhasil disimpan",
+		Data:    data,
+	})
+}
+
+// getList godoc
+//
+//	@Summary Get a list of book
+//	@Description Get a list of book.
+//	@Produce json
+//	@Param q query string false "Book's title"
+//	@Param s query int false "Data offset"
+//	@Param l query int false "Data limit"
+//	@Success 200 {object} dto.SuccessResponse[[]dto.BookDetailResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /books [get]
+func (h *BookHandler) getList(c *gin.Context) {
+	var req dto.Filter
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	data, err := h.service.GetList(&req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[[]dto.BookDetailResp]{
+		Success: true,
+		Message: "Daftar buku",
+		Data:    data,
+	})
+}
+
+// getByID godoc
+//
+//	@Summary Get a book's detail
+//	@Description Get a book's detail including its author and publisher.
+//	@Produce json
+//	@Param id path int true "Book's ID"
+//	@Success 200 {object} dto.SuccessResponse[dto.BookDetailResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /books/{id} [get]
+func (h *BookHandler) getByID(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	data, err := h.service.GetByID(uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[*dto.BookDetailResp]{
+		Success: true,
+		Message: "Detail buku",
+		Data:    data,
+	})
+}
+
+// update godoc
+//
+//	@Summary Update a book's detail
+//	@Description Update a book's detail.
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param id path int true "Book's ID"
+//	@Param detail body dto.BookUpdateReq true "Book's detail"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /books/{id} [put]
+func (h *BookHandler) update(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	var req dto.BookUpdateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+	req.ID = uint(id)
+
+	actorID := c.GetUint(server.ParamTokenUserID)
+
+	err = h.service.Update(&req, actorID, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrAuthorNotFound),
+			errors.Is(err, exception.ErrPublisherNotFound):
+			c.JSON(http.StatusBadRequest, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Data berhasil disimpan",
+	})
+}
+
+// getVersions godoc
+//
+//	@Summary Get a book's earlier versions
+//	@Description Get the versions a book had before each update, newest
+//	@Description first.
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param id path int true "Book's ID"
+//	@Param s query int false "Data offset"
+//	@Param l query int false "Data limit"
+//	@Success 200 {object} dto.SuccessResponse[[]dto.BookVersionResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /books/{id}/versions [get]
+func (h *BookHandler) getVersions(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	var req dto.Filter
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	data, err := h.service.GetVersions(uint(id), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrBookNotFound),
+			errors.Is(err, exception.ErrVersionNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[[]dto.BookVersionResp]{
+		Success: true,
+		Message: "Riwayat versi buku",
+		Data:    data,
+	})
+}
+
+// restoreVersion godoc
+//
+//	@Summary Restore an earlier version of a book
+//	@Description Save an earlier version as the book's detail. It is
+//	@Description validated like any update, and the replaced detail is kept as
+//	@Description a new version.
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param id path int true "Book's ID"
+//	@Param version path int true "Version"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /books/{id}/versions/{version}/restore [post]
+func (h *BookHandler) restoreVersion(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	version, err := strconv.ParseUint(c.Param("version"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("Versi tidak valid"))
+		return
+	}
+
+	req, err := h.service.GetVersion(uint(id), uint(version))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrBookNotFound),
+			errors.Is(err, exception.ErrVersionNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	// The version goes through the same checks as an update sent by hand.
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	actorID := c.GetUint(server.ParamTokenUserID)
+
+	err = h.service.Update(req, actorID, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrAuthorNotFound),
+			errors.Is(err, exception.ErrPublisherNotFound):
+			c.JSON(http.StatusBadRequest, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Versi data berhasil dipulihkan",
+	})
+}
+
+// delete godoc
+//
+//	@Summary Delete a book
+//	@Description Soft-delete a book. It is rejected while the book is borrowed.
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param id path int true "Book's ID"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 409 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /books/{id} [delete]
+func (h *BookHandler) delete(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	actorID := c.GetUint(server.ParamTokenUserID)
+
+	err = h.service.Delete(uint(id), actorID, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrBookBorrowed):
+			c.JSON(http.StatusConflict, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Data berhasil dihapus",
+	})
+}
@@ -0,0 +1,220 @@
+package rest
+
+import (
+	"base-gin/app/domain/dto"
+	"base-gin/app/service"
+	"base-gin/exception"
+	"base-gin/server"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BorrowingHandler struct {
+	hr      *server.Handler
+	service *service.BorrowingService
+}
+
+func newBorrowingHandler(
+	hr *server.Handler,
+	borrowingService *service.BorrowingService,
+) *BorrowingHandler {
+	return &BorrowingHandler{hr: hr, service: borrowingService}
+}
+
+func (h *BorrowingHandler) Route(app *gin.Engine) {
+	grp := app.Group(server.RootBorrowing,
+		h.hr.AuthAccessOrAPIKey(), h.hr.RequireRole(staffRoles...))
+	grp.POST("", h.checkout)
+	grp.GET("", h.getList)
+	grp.GET("/:id", h.getByID)
+	grp.POST(server.PathReturn, h.giveBack)
+}
+
+// checkout godoc
+//
+//	@Summary Check out a book
+//	@Description Lend a book to a person. A book can only be lent once at a time.
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param detail body dto.BorrowingCheckoutReq true "Borrowing's detail"
+//	@Success 201 {object} dto.SuccessResponse[dto.BorrowingDetailResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 409 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /borrowings [post]
+func (h *BorrowingHandler) checkout(c *gin.Context) {
+	var req dto.BorrowingCheckoutReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	actorID := c.GetUint(server.ParamTokenUserID)
+
+	data, err := h.service.Checkout(&req, actorID, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrPersonNotFound),
+			errors.Is(err, exception.ErrBookNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrBookBorrowed):
+			c.JSON(http.StatusConflict, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse[*dto.BorrowingDetailResp]{
+		Success: true,
+		Message: "Peminjaman berhasil disimpan",
+		Data:    data,
+	})
+}
+
+// giveBack godoc
+//
+//	@Summary Return a borrowed book
+//	@Description Mark a borrowing as returned. A borrowing can only be returned once.
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param id path int true "Borrowing's ID"
+//	@Success 200 {object} dto.SuccessResponse[dto.BorrowingDetailResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 409 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /borrowings/{id}/return [post]
+func (h *BorrowingHandler) giveBack(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	actorID := c.GetUint(server.ParamTokenUserID)
+
+	data, err := h.service.Return(uint(id), actorID, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrBookReturned):
+			c.JSON(http.StatusConflict, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[*dto.BorrowingDetailResp]{
+		Success: true,
+		Message: "Pengembalian berhasil disimpan",
+		Data:    data,
+	})
+}
+
+// getList godoc
+//
+//	@Summary Get a list of borrowing
+//	@Description Get a list of borrowing, newest first.
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param book_id query int false "Book's ID"
+//	@Param person_id query int false "Person's ID"
+//	@Param open query bool false "Only borrowings that are not returned yet"
+//	@Param s query int false "Data offset"
+//	@Param l query int false "Data limit"
+//	@Success 200 {object} dto.SuccessResponse[[]dto.BorrowingDetailResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /borrowings [get]
+func (h *BorrowingHandler) getList(c *gin.Context) {
+	var req dto.BorrowingFilter
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	data, err := h.service.GetList(&req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[[]dto.BorrowingDetailResp]{
+		Success: true,
+		Message: "Daftar peminjaman",
+		Data:    data,
+	})
+}
+
+// getByID godoc
+//
+//	@Summary Get a borrowing's detail
+//	@Description Get a borrowing's detail.
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param id path int true "Borrowing's ID"
+//	@Success 200 {object} dto.SuccessResponse[dto.BorrowingDetailResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /borrowings/{id} [get]
+func (h *BorrowingHandler) getByID(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	data, err := h.service.GetByID(uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[*dto.BorrowingDetailResp]{
+		Success: true,
+		Message: "Detail peminjaman",
+		Data:    data,
+	})
+}
@@ -0,0 +1,29 @@
+package rest
+
+import (
+	"base-gin/server"
+	"base-gin/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type KeyHandler struct {
+	hr *server.Handler
+}
+
+func newKeyHandler(hr *server.Handler) *KeyHandler {
+	return &KeyHandler{hr: hr}
+}
+
+func (h *KeyHandler) Route(app *gin.Engine) {
+	app.GET(server.RootJWKS, h.getJWKS)
+}
+
+// getJWKS publishes the public keys of the token key set as a plain RFC 7517
+// document, not wrapped in dto.SuccessResponse, so standard JWT libraries can
+// read it. A retired key stays listed as long as it is configured.
+func (h *KeyHandler) getJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, util.GetJWKS())
+}
@@ -0,0 +1,117 @@
+package rest
+
+import (
+	"base-gin/app/domain/dto"
+	"base-gin/app/service"
+	"base-gin/exception"
+	"base-gin/server"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCHandler struct {
+	hr      *server.Handler
+	service *service.OIDCService
+}
+
+func newOIDCHandler(
+	hr *server.Handler,
+	oidcService *service.OIDCService,
+) *OIDCHandler {
+	return &OIDCHandler{hr: hr, service: oidcService}
+}
+
+func (h *OIDCHandler) Route(app *gin.Engine) {
+	grp := app.Group(server.RootAccount)
+	grp.GET(server.PathOIDCLogin, h.login)
+	grp.POST(server.PathOIDCCallback, h.callback)
+}
+
+// login godoc
+//
+//	@Summary Start an OpenID Connect login
+//	@Description Get the identity provider's login page. After login the
+//	@Description provider redirects to OIDC_REDIRECT_URL with a code & state,
+//	@Description which are then posted to /accounts/oidc/callback together
+//	@Description with the binding returned here.
+//	@Produce json
+//	@Success 200 {object} dto.SuccessResponse[dto.AccountOIDCAuthResp]
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/oidc/login [get]
+func (h *OIDCHandler) login(c *gin.Context) {
+	data, err := h.service.AuthURL()
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrOIDCDisabled):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[dto.AccountOIDCAuthResp]{
+		Success: true,
+		Message: "Lanjutkan login di penyedia identitas",
+		Data:    data,
+	})
+}
+
+// callback godoc
+//
+//	@Summary Finish an OpenID Connect login
+//	@Description Exchange the code sent by the identity provider for an access
+//	@Description & refresh token. An account is created on first login. When
+//	@Description two-factor authentication is enabled, only a challenge token
+//	@Description is returned; exchange it at /accounts/login/2fa.
+//	@Accept json
+//	@Produce json
+//	@Param detail body dto.AccountOIDCCallbackReq true "Code & state from the provider, binding from the login"
+//	@Success 200 {object} dto.SuccessResponse[dto.AccountLoginResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /accounts/oidc/callback [post]
+func (h *OIDCHandler) callback(c *gin.Context) {
+	var req dto.AccountOIDCCallbackReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	data, err := h.service.Login(&req, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrOIDCDisabled):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrOIDCStateInvalid),
+			errors.Is(err, exception.ErrOIDCLoginFailed):
+			c.JSON(http.StatusBadRequest, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrUserNotFound):
+			c.JSON(http.StatusBadRequest, h.hr.ErrorResponse(exception.ErrOIDCLoginFailed.Error()))
+		case errors.Is(err, exception.ErrUserLocked):
+			c.JSON(http.StatusForbidden, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	message := "Login berhasil"
+	if data.ChallengeToken != "" {
+		message = "Masukkan kode autentikasi dua faktor"
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[dto.AccountLoginResp]{
+		Success: true,
+		Message: message,
+		Data:    data,
+	})
+}
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"base-gin/app/domain"
 	"base-gin/app/domain/dto"
 	"base-gin/app/service"
 	"base-gin/exception"
@@ -10,6 +11,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 type PersonHandler struct {
@@ -26,35 +28,54 @@ func newPersonHandler(
 
 func (h *PersonHandler) Route(app *gin.Engine) {
 	grp := app.Group(server.RootPerson)
-	grp.GET("", h.getList)
-	grp.GET("/:id", h.getByID)
-	grp.PUT("/:id", h.hr.AuthAccess(), h.update)
+	grp.GET("", h.hr.AuthOptional(), h.getList)
+	grp.GET("/:id", h.hr.AuthOptional(), h.getByID)
+	grp.PUT("/:id", h.hr.AuthAccessOrAPIKey(), h.update)
+	grp.PUT(server.PathContact, h.hr.AuthAccessOrAPIKey(), h.updateContact)
+	grp.GET(server.PathVersions, h.hr.AuthAccessOrAPIKey(), h.getVersions)
+	grp.POST(server.PathRestore, h.hr.AuthAccessOrAPIKey(), h.restoreVersion)
+	grp.POST(server.PathEmailCode, h.hr.AuthAccessOrAPIKey(), h.sendEmailCode)
+	grp.POST(server.PathEmailVerify, h.hr.AuthAccessOrAPIKey(), h.verifyEmail)
+	grp.DELETE("/:id", h.hr.AuthAccessOrAPIKey(), h.hr.RequireRole(staffRoles...), h.delete)
+	grp.GET(server.PathTrash, h.hr.AuthAccess(), h.hr.RequireRole(domain.RoleAdmin), h.getTrash)
+	grp.POST(server.PathUndelete, h.hr.AuthAccess(), h.hr.RequireRole(domain.RoleAdmin), h.restore)
 }
 
 // getList godoc
 //
 //	@Summary Get a list of person
-//	@Description Get a list of person.
+//	@Description Get a list of person. Emails are masked and phones left out,
+//	@Description except for the caller's own person, or when the caller is staff.
 //	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
 //	@Param q query string false "Person's name"
 //	@Param s query int false "Data offset"
 //	@Param l query int false "Data limit"
+//	@Param email query string false "Person's exact email, staff only"
 //	@Success 200 {object} dto.SuccessResponse[[]dto.PersonDetailResp]
 //	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
 //	@Failure 404 {object} dto.ErrorResponse
 //	@Failure 422 {object} dto.ErrorResponse
 //	@Failure 500 {object} dto.ErrorResponse
 //	@Router /persons [get]
 func (h *PersonHandler) getList(c *gin.Context) {
-	var req dto.Filter
+	var req dto.PersonFilter
 	if err := c.ShouldBindQuery(&req); err != nil {
 		c.JSON(h.hr.BindingError(err))
 		return
 	}
 
-	data, err := h.service.GetList(&req)
+	accountID := c.GetUint(server.ParamTokenUserID)
+	role, _ := c.Get(server.ParamTokenRole)
+	accountRole, _ := role.(domain.TypeRole)
+
+	data, err := h.service.GetList(&req, accountID, accountRole)
 	if err != nil {
 		switch {
+		case errors.Is(err, exception.ErrForbidden):
+			c.JSON(http.StatusForbidden, h.hr.ErrorResponse(err.Error()))
 		case errors.Is(err, exception.ErrUserNotFound):
 			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(exception.ErrDataNotFound.Error()))
 		default:
@@ -74,8 +95,12 @@ func (h *PersonHandler) getList(c *gin.Context) {
 // getByID godoc
 //
 //	@Summary Get a person's detail
-//	@Description Get a person's detail.
+//	@Description Get a person's detail. The email is masked and the phone left
+//	@Description out, except for the caller's own person, or when the caller is
+//	@Description staff.
 //	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
 //	@Param id path int true "Person's ID"
 //	@Success 200 {object} dto.SuccessResponse[dto.PersonDetailResp]
 //	@Failure 400 {object} dto.ErrorResponse
@@ -91,7 +116,11 @@ func (h *PersonHandler) getByID(c *gin.Context) {
 		return
 	}
 
-	data, err := h.service.GetByID(uint(id))
+	accountID := c.GetUint(server.ParamTokenUserID)
+	role, _ := c.Get(server.ParamTokenRole)
+	accountRole, _ := role.(domain.TypeRole)
+
+	data, err := h.service.GetByID(uint(id), accountID, accountRole)
 	if err != nil {
 		switch {
 		case errors.Is(err, exception.ErrUserNotFound):
@@ -113,10 +142,12 @@ func (h *PersonHandler) getByID(c *gin.Context) {
 // update godoc
 //
 //	@Summary Update a person's detail
-//	@Description Update a person's detail.
+//	@Description Update a person's detail. Members can only update their own
+//	@Description detail, librarians and admins can update anyone's.
 //	@Accept json
 //	@Produce json
 //	@Security BearerAuth
+//	@Security APIKeyAuth
 //	@Param id path int true "Person's ID"
 //	@Param detail body dto.PersonUpdateReq true "Person's detail"
 //	@Success 200 {object} dto.SuccessResponse[any]
@@ -142,11 +173,80 @@ func (h *PersonHandler) update(c *gin.Context) {
 	}
 	req.ID = uint(id)
 
-	err = h.service.Update(&req)
+	accountID := c.GetUint(server.ParamTokenUserID)
+	role, _ := c.Get(server.ParamTokenRole)
+	accountRole, _ := role.(domain.TypeRole)
+
+	err = h.service.Update(&req, accountID, accountRole, h.hr.ClientInfo(c))
 	if err != nil {
 		switch {
 		case errors.Is(err, exception.ErrDateParsing):
 			c.JSON(http.StatusBadRequest, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrPersonForbidden):
+			c.JSON(http.StatusForbidden, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Data berhasil disimpan",
+	})
+}
+
+// updateContact godoc
+//
+//	@Summary Update a person's contact details
+//	@Description Replace a person's email and phone, an empty value removes
+//	@Description one. A verification code is sent to a new email. Members can
+//	@Description only update their own detail.
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param id path int true "Person's ID"
+//	@Param contact body dto.PersonContactUpdateReq true "Contact details"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /persons/{id}/contact [put]
+func (h *PersonHandler) updateContact(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	var req dto.PersonContactUpdateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+	req.ID = uint(id)
+
+	accountID := c.GetUint(server.ParamTokenUserID)
+	role, _ := c.Get(server.ParamTokenRole)
+	accountRole, _ := role.(domain.TypeRole)
+
+	err = h.service.UpdateContact(&req, accountID, accountRole, h.hr.ClientInfo(c))
+	if err != nil {
+		var validationErr *exception.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			c.JSON(h.hr.BindingError(err))
+		case errors.Is(err, exception.ErrPersonForbidden):
+			c.JSON(http.StatusForbidden, h.hr.ErrorResponse(err.Error()))
 		case errors.Is(err, exception.ErrUserNotFound):
 			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
 		default:
@@ -161,3 +261,397 @@ func (h *PersonHandler) update(c *gin.Context) {
 		Message: "Data berhasil disimpan",
 	})
 }
+
+// getVersions godoc
+//
+//	@Summary Get a person's earlier versions
+//	@Description Get the versions a person had before each update, newest
+//	@Description first. Members can only see their own.
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param id path int true "Person's ID"
+//	@Param s query int false "Data offset"
+//	@Param l query int false "Data limit"
+//	@Success 200 {object} dto.SuccessResponse[[]dto.PersonVersionResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /persons/{id}/versions [get]
+func (h *PersonHandler) getVersions(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	var req dto.Filter
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	accountID := c.GetUint(server.ParamTokenUserID)
+	role, _ := c.Get(server.ParamTokenRole)
+	accountRole, _ := role.(domain.TypeRole)
+
+	data, err := h.service.GetVersions(uint(id), accountID, accountRole, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrPersonForbidden):
+			c.JSON(http.StatusForbidden, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrUserNotFound),
+			errors.Is(err, exception.ErrVersionNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[[]dto.PersonVersionResp]{
+		Success: true,
+		Message: "Riwayat versi anggota",
+		Data:    data,
+	})
+}
+
+// restoreVersion godoc
+//
+//	@Summary Restore an earlier version of a person
+//	@Description Save an earlier version as the person's detail and contact
+//	@Description details. Both are validated like any update, a restored email
+//	@Description has to be verified again, and the replaced detail is kept as
+//	@Description a new version. Members can only restore their own.
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param id path int true "Person's ID"
+//	@Param version path int true "Version"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /persons/{id}/versions/{version}/restore [post]
+func (h *PersonHandler) restoreVersion(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	version, err := strconv.ParseUint(c.Param("version"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("Versi tidak valid"))
+		return
+	}
+
+	accountID := c.GetUint(server.ParamTokenUserID)
+	role, _ := c.Get(server.ParamTokenRole)
+	accountRole, _ := role.(domain.TypeRole)
+
+	req, contact, err := h.service.GetVersion(uint(id), uint(version), accountID, accountRole)
+	if err != nil {
+		h.restoreError(c, err)
+		return
+	}
+
+	// The version goes through the same checks as an update sent by hand.
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+	if contact != nil {
+		if err := binding.Validator.ValidateStruct(contact); err != nil {
+			c.JSON(h.hr.BindingError(err))
+			return
+		}
+	}
+
+	err = h.service.RestoreVersion(req, contact, accountID, accountRole, h.hr.ClientInfo(c))
+	if err != nil {
+		h.restoreError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Versi data berhasil dipulihkan",
+	})
+}
+
+func (h *PersonHandler) restoreError(c *gin.Context, err error) {
+	var validationErr *exception.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(h.hr.BindingError(err))
+	case errors.Is(err, exception.ErrDateParsing):
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse(err.Error()))
+	case errors.Is(err, exception.ErrPersonForbidden):
+		c.JSON(http.StatusForbidden, h.hr.ErrorResponse(err.Error()))
+	case errors.Is(err, exception.ErrUserNotFound),
+		errors.Is(err, exception.ErrVersionNotFound):
+		c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+	default:
+		h.hr.ErrorInternalServer(c, err)
+	}
+}
+
+// sendEmailCode godoc
+//
+//	@Summary Send an email verification code
+//	@Description Send a new verification code to a person's unverified email.
+//	@Description The previous code can no longer be used.
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param id path int true "Person's ID"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /persons/{id}/email/code [post]
+func (h *PersonHandler) sendEmailCode(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	accountID := c.GetUint(server.ParamTokenUserID)
+	role, _ := c.Get(server.ParamTokenRole)
+	accountRole, _ := role.(domain.TypeRole)
+
+	err = h.service.SendEmailVerification(uint(id), accountID, accountRole)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrEmailNotSet),
+			errors.Is(err, exception.ErrEmailVerified):
+			c.JSON(http.StatusBadRequest, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrPersonForbidden):
+			c.JSON(http.StatusForbidden, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Kode verifikasi telah dikirim ke email",
+	})
+}
+
+// verifyEmail godoc
+//
+//	@Summary Verify a person's email
+//	@Description Verify a person's email with the code sent to it.
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param id path int true "Person's ID"
+//	@Param code body dto.PersonEmailVerifyReq true "Verification code"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /persons/{id}/email/verify [post]
+func (h *PersonHandler) verifyEmail(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	var req dto.PersonEmailVerifyReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	accountID := c.GetUint(server.ParamTokenUserID)
+	role, _ := c.Get(server.ParamTokenRole)
+	accountRole, _ := role.(domain.TypeRole)
+
+	err = h.service.VerifyEmail(uint(id), accountID, accountRole, &req, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrEmailNotSet),
+			errors.Is(err, exception.ErrEmailVerified),
+			errors.Is(err, exception.ErrVerifyCodeInvalid):
+			c.JSON(http.StatusBadRequest, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrPersonForbidden):
+			c.JSON(http.StatusForbidden, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Email berhasil diverifikasi",
+	})
+}
+
+// delete godoc
+//
+//	@Summary Delete a person
+//	@Description Soft-delete a person. A person linked to an account is
+//	@Description anonymized with the account instead, and one with a book still
+//	@Description borrowed can not be deleted.
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param id path int true "Person's ID"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 409 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /persons/{id} [delete]
+func (h *PersonHandler) delete(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	actorID := c.GetUint(server.ParamTokenUserID)
+
+	err = h.service.Delete(uint(id), actorID, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrPersonHasAccount),
+			errors.Is(err, exception.ErrPersonBorrowing):
+			c.JSON(http.StatusConflict, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Data berhasil dihapus",
+	})
+}
+
+// getTrash godoc
+//
+//	@Summary Get the deleted persons
+//	@Description Get the soft-deleted persons, most recently deleted first.
+//	@Description They are removed for good after JOB_TRASH_RETENTION days,
+//	@Description unless they have borrowings.
+//	@Produce json
+//	@Security BearerAuth
+//	@Param q query string false "Person's name"
+//	@Param s query int false "Data offset"
+//	@Param l query int false "Data limit"
+//	@Success 200 {object} dto.SuccessResponse[[]dto.PersonTrashResp]
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /persons/trash [get]
+func (h *PersonHandler) getTrash(c *gin.Context) {
+	var req dto.Filter
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	data, err := h.service.GetTrash(&req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[[]dto.PersonTrashResp]{
+		Success: true,
+		Message: "Daftar anggota terhapus",
+		Data:    data,
+	})
+}
+
+// restore godoc
+//
+//	@Summary Restore a deleted person
+//	@Description Take a soft-deleted person out of the trash.
+//	@Produce json
+//	@Security BearerAuth
+//	@Param id path int true "Person's ID"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /persons/{id}/restore [post]
+func (h *PersonHandler) restore(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	actorID := c.GetUint(server.ParamTokenUserID)
+
+	err = h.service.Restore(uint(id), actorID, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Data berhasil dipulihkan",
+	})
+}
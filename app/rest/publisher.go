@@ -1,46 +1,537 @@
 package rest
 
 import (
-	"base-gin/app/service"
+	"base-gin/app/domain"
 	"base-gin/app/domain/dto"
+	"base-gin/app/service"
+	"base-gin/exception"
 	"base-gin/server"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 type PublisherHandler struct {
-	hr *server.Handler
+	hr      *server.Handler
 	service *service.PublisherService
 }
 
-func newPublisherHandler(hr *server.Handler, publisherService *service.PublisherService,
-	) *PublisherHandler {
-	return &PublisherHandler {hr:hr, service: publisherService}
+func newPublisherHandler(
+	hr *server.Handler,
+	publisherService *service.PublisherService,
+) *PublisherHandler {
+	return &PublisherHandler{hr: hr, service: publisherService}
 }
 
-func (h *PublisherHandler) Route(app *gin.Engine)  {
-	grp := app.Group("/v1/publishers")
-	grp.POST("", h.hr.AuthAccess(), h.create)
+func (h *PublisherHandler) Route(app *gin.Engine) {
+	grp := app.Group(server.RootPublisher)
+	grp.GET("", h.getList)
+	grp.GET("/:id", h.getByID)
+	grp.GET(server.PathBooks, h.getBooks)
+	grp.POST("", h.hr.AuthAccessOrAPIKey(), h.hr.RequireRole(staffRoles...), h.create)
+	grp.PUT("/:id", h.hr.AuthAccessOrAPIKey(), h.hr.RequireRole(staffRoles...), h.update)
+	grp.GET(server.PathVersions, h.hr.AuthAccessOrAPIKey(), h.hr.RequireRole(staffRoles...), h.getVersions)
+	grp.POST(server.PathRestore, h.hr.AuthAccessOrAPIKey(), h.hr.RequireRole(staffRoles...), h.restoreVersion)
+	grp.DELETE("/:id", h.hr.AuthAccessOrAPIKey(), h.hr.RequireRole(staffRoles...), h.delete)
+	grp.GET(server.PathTrash, h.hr.AuthAccess(), h.hr.RequireRole(domain.RoleAdmin), h.getTrash)
+	grp.POST(server.PathUndelete, h.hr.AuthAccess(), h.hr.RequireRole(domain.RoleAdmin), h.restore)
 }
 
-func (h *PublisherHandler) create(c *gin.Context)  {
+// create godoc
+//
+//	@Summary Create a new publisher
+//	@Description Create a new publisher. Publisher's name must be unique.
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param detail body dto.PublisherCreateReq true "Publisher's detail"
+//	@Success 201 {object} dto.SuccessResponse[dto.PublisherCreateResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 409 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /publishers [post]
+func (h *PublisherHandler) create(c *gin.Context) {
 	var req dto.PublisherCreateReq
-	err := c.ShouldBindJSON(&req)
-	if err != nil {
-		h.hr.BindingError(err)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
 		return
 	}
 
-	data, err := h.service.Create(&req)
+	actorID := c.GetUint(server.ParamTokenUserID)
+
+	data, err := h.service.Create(&req, actorID, h.hr.ClientInfo(c))
 	if err != nil {
-		h.hr.ErrorInternalServer(c, err)
+		switch {
+		case errors.Is(err, exception.ErrPublisherConflict),
+			errors.Is(err, exception.ErrPublisherTrashed):
+			c.JSON(http.StatusConflict, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
 		return
 	}
 
 	c.JSON(http.StatusCreated, dto.SuccessResponse[*dto.PublisherCreateResp]{
 		Success: true,
 		Message: "Data penerbit berhasil disimpan",
-		Data: data,
+		Data:    data,
+	})
+}
+
+// getList godoc
+//
+//	@Summary Get a list of publisher
+//	@Description Get a list of publisher.
+//	@Produce json
+//	@Param q query string false "Publisher's name or city"
+//	@Param s query int false "Data offset"
+//	@Param l query int false "Data limit"
+//	@Success 200 {object} dto.SuccessResponse[[]dto.PublisherDetailResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /publishers [get]
+func (h *PublisherHandler) getList(c *gin.Context) {
+	var req dto.Filter
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	data, err := h.service.GetList(&req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[[]dto.PublisherDetailResp]{
+		Success: true,
+		Message: "Daftar penerbit",
+		Data:    data,
+	})
+}
+
+// getByID godoc
+//
+//	@Summary Get a publisher's detail
+//	@Description Get a publisher's detail.
+//	@Produce json
+//	@Param id path int true "Publisher's ID"
+//	@Success 200 {object} dto.SuccessResponse[dto.PublisherDetailResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /publishers/{id} [get]
+func (h *PublisherHandler) getByID(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	data, err := h.service.GetByID(uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[dto.PublisherDetailResp]{
+		Success: true,
+		Message: "Detail penerbit",
+		Data:    data,
+	})
+}
+
+// getBooks godoc
+//
+//	@Summary Get a list of book by publisher
+//	@Description Get a list of book published by a publisher.
+//	@Produce json
+//	@Param id path int true "Publisher's ID"
+//	@Param q query string false "Book's title"
+//	@Param s query int false "Data offset"
+//	@Param l query int false "Data limit"
+//	@Success 200 {object} dto.SuccessResponse[[]dto.BookDetailResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /publishers/{id}/books [get]
+func (h *PublisherHandler) getBooks(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	var req dto.Filter
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	data, err := h.service.GetBooks(uint(id), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrPublisherNotFound),
+			errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[[]dto.BookDetailResp]{
+		Success: true,
+		Message: "Daftar buku penerbit",
+		Data:    data,
+	})
+}
+
+// update godoc
+//
+//	@Summary Update a publisher's detail
+//	@Description Update a publisher's detail. Publisher's name must be unique.
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param id path int true "Publisher's ID"
+//	@Param detail body dto.PublisherUpdateReq true "Publisher's detail"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 409 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /publishers/{id} [put]
+func (h *PublisherHandler) update(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	var req dto.PublisherUpdateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+	req.ID = uint(id)
+
+	actorID := c.GetUint(server.ParamTokenUserID)
+
+	err = h.service.Update(&req, actorID, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrPublisherConflict),
+			errors.Is(err, exception.ErrPublisherTrashed):
+			c.JSON(http.StatusConflict, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Data berhasil disimpan",
+	})
+}
+
+// getVersions godoc
+//
+//	@Summary Get a publisher's earlier versions
+//	@Description Get the versions a publisher had before each update, newest
+//	@Description first.
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param id path int true "Publisher's ID"
+//	@Param s query int false "Data offset"
+//	@Param l query int false "Data limit"
+//	@Success 200 {object} dto.SuccessResponse[[]dto.PublisherVersionResp]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /publishers/{id}/versions [get]
+func (h *PublisherHandler) getVersions(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	var req dto.Filter
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	data, err := h.service.GetVersions(uint(id), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrPublisherNotFound),
+			errors.Is(err, exception.ErrVersionNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[[]dto.PublisherVersionResp]{
+		Success: true,
+		Message: "Riwayat versi penerbit",
+		Data:    data,
+	})
+}
+
+// restoreVersion godoc
+//
+//	@Summary Restore an earlier version of a publisher
+//	@Description Save an earlier version as the publisher's detail. It is
+//	@Description validated like any update, and the replaced detail is kept as
+//	@Description a new version.
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param id path int true "Publisher's ID"
+//	@Param version path int true "Version"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 409 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /publishers/{id}/versions/{version}/restore [post]
+func (h *PublisherHandler) restoreVersion(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	version, err := strconv.ParseUint(c.Param("version"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("Versi tidak valid"))
+		return
+	}
+
+	req, err := h.service.GetVersion(uint(id), uint(version))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrPublisherNotFound),
+			errors.Is(err, exception.ErrVersionNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	// The version goes through the same checks as an update sent by hand.
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	actorID := c.GetUint(server.ParamTokenUserID)
+
+	err = h.service.Update(req, actorID, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrPublisherConflict),
+			errors.Is(err, exception.ErrPublisherTrashed):
+			c.JSON(http.StatusConflict, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Versi data berhasil dipulihkan",
+	})
+}
+
+// delete godoc
+//
+//	@Summary Delete a publisher
+//	@Description Soft-delete a publisher. It is rejected while books still refer to it.
+//	@Produce json
+//	@Security BearerAuth
+//	@Security APIKeyAuth
+//	@Param id path int true "Publisher's ID"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 409 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /publishers/{id} [delete]
+func (h *PublisherHandler) delete(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	actorID := c.GetUint(server.ParamTokenUserID)
+
+	err = h.service.Delete(uint(id), actorID, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		case errors.Is(err, exception.ErrPublisherHasBooks):
+			c.JSON(http.StatusConflict, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Data berhasil dihapus",
+	})
+}
+
+// getTrash godoc
+//
+//	@Summary Get the deleted publishers
+//	@Description Get the soft-deleted publishers, most recently deleted first.
+//	@Description They are removed for good after JOB_TRASH_RETENTION days,
+//	@Description unless a book still refers to them.
+//	@Produce json
+//	@Security BearerAuth
+//	@Param q query string false "Publisher's name or city"
+//	@Param s query int false "Data offset"
+//	@Param l query int false "Data limit"
+//	@Success 200 {object} dto.SuccessResponse[[]dto.PublisherTrashResp]
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 422 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /publishers/trash [get]
+func (h *PublisherHandler) getTrash(c *gin.Context) {
+	var req dto.Filter
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(h.hr.BindingError(err))
+		return
+	}
+
+	data, err := h.service.GetTrash(&req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[[]dto.PublisherTrashResp]{
+		Success: true,
+		Message: "Daftar penerbit terhapus",
+		Data:    data,
 	})
-}
\ No newline at end of file
+}
+
+// restore godoc
+//
+//	@Summary Restore a deleted publisher
+//	@Description Take a soft-deleted publisher out of the trash.
+//	@Produce json
+//	@Security BearerAuth
+//	@Param id path int true "Publisher's ID"
+//	@Success 200 {object} dto.SuccessResponse[any]
+//	@Failure 400 {object} dto.ErrorResponse
+//	@Failure 401 {object} dto.ErrorResponse
+//	@Failure 403 {object} dto.ErrorResponse
+//	@Failure 404 {object} dto.ErrorResponse
+//	@Failure 500 {object} dto.ErrorResponse
+//	@Router /publishers/{id}/restore [post]
+func (h *PublisherHandler) restore(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.hr.ErrorResponse("ID tidak valid"))
+		return
+	}
+
+	actorID := c.GetUint(server.ParamTokenUserID)
+
+	err = h.service.Restore(uint(id), actorID, h.hr.ClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrDataNotFound):
+			c.JSON(http.StatusNotFound, h.hr.ErrorResponse(err.Error()))
+		default:
+			h.hr.ErrorInternalServer(c, err)
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse[any]{
+		Success: true,
+		Message: "Data berhasil dipulihkan",
+	})
+}
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"base-gin/app/domain"
 	"base-gin/app/service"
 	"base-gin/server"
 
@@ -8,9 +9,18 @@ import (
 )
 
 var (
-	accountHandler *AccountHandler
-	personHandler  *PersonHandler
+	// staffRoles may manage the catalog and lend books.
+	staffRoles = []domain.TypeRole{domain.RoleLibrarian, domain.RoleAdmin}
+
+	accountHandler   *AccountHandler
+	personHandler    *PersonHandler
 	publisherHandler *PublisherHandler
+	bookHandler      *BookHandler
+	authorHandler    *AuthorHandler
+	borrowingHandler *BorrowingHandler
+	keyHandler       *KeyHandler
+	oidcHandler      *OIDCHandler
+	auditHandler     *AuditHandler
 )
 
 func SetupRestHandlers(app *gin.Engine) {
@@ -20,7 +30,13 @@ func SetupRestHandlers(app *gin.Engine) {
 		handler, service.GetAccountService(), service.GetPersonService())
 	personHandler = newPersonHandler(handler, service.GetPersonService())
 	publisherHandler = newPublisherHandler(handler, service.GetPublisherServide())
-	
+	bookHandler = newBookHandler(handler, service.GetBookService())
+	authorHandler = newAuthorHandler(handler, service.GetAuthorService())
+	borrowingHandler = newBorrowingHandler(handler, service.GetBorrowingService())
+	keyHandler = newKeyHandler(handler)
+	oidcHandler = newOIDCHandler(handler, service.GetOIDCService())
+	auditHandler = newAuditHandler(handler, service.GetAuditService())
+
 	setupRoutes(app)
 }
 
@@ -28,4 +44,10 @@ func setupRoutes(app *gin.Engine) {
 	accountHandler.Route(app)
 	personHandler.Route(app)
 	publisherHandler.Route(app)
+	bookHandler.Route(app)
+	authorHandler.Route(app)
+	borrowingHandler.Route(app)
+	keyHandler.Route(app)
+	oidcHandler.Route(app)
+	auditHandler.Route(app)
 }
@@ -1,43 +1,773 @@
 package service
 
 import (
+	"base-gin/app/domain"
+	"base-gin/app/domain/dao"
 	"base-gin/app/domain/dto"
 	"base-gin/app/repository"
 	"base-gin/config"
 	"base-gin/exception"
 	"base-gin/util"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	resetCodeLength          = 6
+	defaultLoginHistoryLimit = 20
+	maxUserAgentLength       = 255
 )
 
 type AccountService struct {
-	cfg  *config.Config
-	repo *repository.AccountRepository
+	cfg         *config.Config
+	repo        *repository.AccountRepository
+	refreshRepo *repository.RefreshTokenRepository
+	revokedRepo *repository.RevokedTokenRepository
+	resetRepo   *repository.PasswordResetRepository
+	historyRepo *repository.LoginHistoryRepository
+	sessionRepo *repository.SessionRepository
+	apiKeyRepo  *repository.APIKeyRepository
+	personRepo  *repository.PersonRepository
+	borrowRepo  *repository.BorrowingRepository
+	throttle    *LoginThrottle
+	resetLimit  *LoginThrottle
+	notifier    Notifier
+	policy      *util.PasswordPolicy
+	audit       *AuditService
+	authn       Authenticator
 }
 
 func newAccountService(
 	cfg *config.Config,
 	accountRepo *repository.AccountRepository,
+	refreshRepo *repository.RefreshTokenRepository,
+	revokedRepo *repository.RevokedTokenRepository,
+	resetRepo *repository.PasswordResetRepository,
+	historyRepo *repository.LoginHistoryRepository,
+	sessionRepo *repository.SessionRepository,
+	apiKeyRepo *repository.APIKeyRepository,
+	personRepo *repository.PersonRepository,
+	borrowRepo *repository.BorrowingRepository,
+	throttle *LoginThrottle,
+	resetLimit *LoginThrottle,
+	notifier Notifier,
+	policy *util.PasswordPolicy,
+	audit *AuditService,
+	authn Authenticator,
 ) *AccountService {
-	return &AccountService{cfg: cfg, repo: accountRepo}
+	return &AccountService{
+		cfg:         cfg,
+		repo:        accountRepo,
+		refreshRepo: refreshRepo,
+		revokedRepo: revokedRepo,
+		resetRepo:   resetRepo,
+		historyRepo: historyRepo,
+		sessionRepo: sessionRepo,
+		apiKeyRepo:  apiKeyRepo,
+		personRepo:  personRepo,
+		borrowRepo:  borrowRepo,
+		throttle:    throttle,
+		resetLimit:  resetLimit,
+		notifier:    notifier,
+		policy:      policy,
+		audit:       audit,
+		authn:       authn,
+	}
 }
 
-func (s *AccountService) Login(p dto.AccountLoginReq) (dto.AccountLoginResp, error) {
-	var resp dto.AccountLoginResp
+func (s *AccountService) Register(p *dto.AccountRegisterReq, client dto.ClientInfo) (dto.AccountProfileResp, error) {
+	var resp dto.AccountProfileResp
 
-	item, err := s.repo.GetByUsername(p.Username)
+	person, err := p.ToPerson()
 	if err != nil {
+		exception.LogError(err, "AccountService.Register")
+		return resp, exception.ErrDateParsing
+	}
+
+	if err := s.checkPassword(nil, "paswd", p.Password); err != nil {
 		return resp, err
 	}
 
-	if paswdOk := item.VerifyPassword(p.Password); !paswdOk {
+	account, err := dao.NewUser(p.Username, p.Password, s.cfg.AuthN.PasswordEncryptionSecret)
+	if err != nil {
+		return resp, err
+	}
+
+	if err := s.repo.CreateWithPerson(&account, &person); err != nil {
+		return resp, err
+	}
+	s.audit.record(account.ID, client, domain.EntityAccount, account.ID,
+		domain.AuditCreate, nil, &account)
+	s.audit.record(account.ID, client, domain.EntityPerson, person.ID,
+		domain.AuditCreate, nil, &person)
+
+	resp.FromPerson(&person)
+
+	return resp, nil
+}
+
+func (s *AccountService) Login(p dto.AccountLoginReq, client dto.ClientInfo) (dto.AccountLoginResp, error) {
+	var resp dto.AccountLoginResp
+
+	if err := s.throttle.Check(p.Username, client.IPAddress); err != nil {
+		s.recordLogin(p.Username, nil, domain.LoginThrottled, client)
+		return resp, err
+	}
+
+	item, err := s.authn.Authenticate(p.Username, p.Password)
+	if errors.Is(err, exception.ErrUserNotFound) {
+		s.recordLogin(p.Username, nil, domain.LoginUnknownUser, client)
+		if errThrottle := s.throttle.Fail(p.Username, client.IPAddress); errThrottle != nil {
+			return resp, errThrottle
+		}
+		return resp, err
+	}
+	if err != nil && !errors.Is(err, exception.ErrUserLoginFailed) {
+		return resp, err
+	}
+
+	// A directory user without an account yet has nothing to lock.
+	if item.ID == 0 {
+		s.recordLogin(p.Username, nil, domain.LoginBadPassword, client)
+		if errThrottle := s.throttle.Fail(p.Username, client.IPAddress); errThrottle != nil {
+			return resp, errThrottle
+		}
+		return resp, err
+	}
+
+	if item.IsLocked() {
+		s.recordLogin(p.Username, &item, domain.LoginLocked, client)
+		return resp, exception.ErrUserLocked
+	}
+
+	if err != nil {
+		s.recordLogin(p.Username, &item, domain.LoginBadPassword, client)
+		if err := s.failLogin(&item, client); err != nil {
+			return resp, err
+		}
 		return resp, exception.ErrUserLoginFailed
 	}
 
-	aToken, err := util.CreateAuthAccessToken(*s.cfg, item.Username)
+	if err := s.throttle.Succeed(p.Username); err != nil {
+		return resp, err
+	}
+
+	return s.afterFirstFactor(&item, client)
+}
+
+// GetLoginHistory returns the account's recent login attempts.
+func (s *AccountService) GetLoginHistory(accountID uint, params *dto.Filter) ([]dto.AccountLoginHistoryResp, error) {
+	var resp []dto.AccountLoginHistoryResp
+
+	if params.Limit < 1 {
+		params.Limit = defaultLoginHistoryLimit
+	}
+
+	items, err := s.historyRepo.GetListByAccountID(accountID, params)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) < 1 {
+		return nil, exception.ErrDataNotFound
+	}
+
+	for _, item := range items {
+		var t dto.AccountLoginHistoryResp
+		t.FromEntity(&item)
+
+		resp = append(resp, t)
+	}
+
+	return resp, nil
+}
+
+// Export bundles the account, its person, borrowings and login history for
+// the account holder.
+func (s *AccountService) Export(accountID uint) (dto.AccountExportResp, error) {
+	resp := dto.AccountExportResp{ExportedAt: time.Now().UTC()}
+
+	account, err := s.repo.GetByID(accountID)
+	if err != nil {
+		return resp, err
+	}
+	resp.Account.FromEntity(&account)
+
+	history, err := s.historyRepo.GetListByAccountID(accountID, &dto.Filter{})
+	if err != nil {
+		return resp, err
+	}
+	for _, item := range history {
+		var t dto.AccountLoginHistoryResp
+		t.FromEntity(&item)
+
+		resp.LoginHistory = append(resp.LoginHistory, t)
+	}
+
+	person, err := s.personRepo.GetByAccountID(accountID)
+	if errors.Is(err, exception.ErrUserNotFound) {
+		return resp, nil
+	}
+	if err != nil {
+		return resp, err
+	}
+	resp.Person = &dto.AccountExportPerson{}
+	resp.Person.FromEntity(&person)
+
+	borrowings, err := s.borrowRepo.GetList(&dto.BorrowingFilter{PersonID: person.ID})
+	if err != nil {
+		return resp, err
+	}
+	for _, item := range borrowings {
+		var t dto.BorrowingDetailResp
+		t.FromEntity(&item)
+		t.Person = nil
+
+		resp.Borrowings = append(resp.Borrowings, t)
+	}
+
+	return resp, nil
+}
+
+// Anonymize scrubs the personal data of an account and its person on a data
+// subject's request, see AccountRepository.Anonymize. The account can no
+// longer be used afterwards. Admins can not anonymize their own account.
+func (s *AccountService) Anonymize(id, actorID uint, client dto.ClientInfo) error {
+	if id <= 0 {
+		return exception.ErrUserNotFound
+	}
+	if id == actorID {
+		return exception.ErrAccountSelf
+	}
+
+	account, err := s.repo.GetByID(id)
 	if err != nil {
+		return err
+	}
+
+	oldUsername := account.Username
+	if err := account.Anonymize(); err != nil {
+		return err
+	}
+
+	return s.repo.Anonymize(&account, oldUsername, s.revokeAllExpiry(), &dao.AuditLog{
+		ActorID:   &actorID,
+		Entity:    domain.EntityAccount,
+		EntityID:  account.ID,
+		Action:    domain.AuditAnonymize,
+		IPAddress: client.IPAddress,
+	})
+}
+
+// Unlock lifts a lockout caused by repeated failed logins.
+func (s *AccountService) Unlock(id uint, actorID uint, client dto.ClientInfo) error {
+	if id <= 0 {
+		return exception.ErrUserNotFound
+	}
+
+	before, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Unlock(id); err != nil {
+		return err
+	}
+
+	return s.recordUpdate(&before, actorID, client)
+}
+
+// PurgeLoginHistory removes login attempts older than the retention period.
+func (s *AccountService) PurgeLoginHistory() (int64, error) {
+	cutoff := time.Now().UTC().
+		AddDate(0, 0, -s.cfg.AuthN.LoginHistoryRetention)
+
+	return s.historyRepo.PurgeBefore(cutoff)
+}
+
+// Refresh rotates a refresh token: the presented token is consumed and a new
+// access/refresh pair of the same family is issued. Presenting a token that
+// was already consumed revokes the whole family.
+func (s *AccountService) Refresh(username, tokenID, family string, client dto.ClientInfo) (dto.AccountLoginResp, error) {
+	var resp dto.AccountLoginResp
+
+	item, err := s.refreshRepo.Consume(tokenID)
+	if err != nil {
+		if errors.Is(err, exception.ErrTokenReused) {
+			if errRevoke := s.revokeFamily(item.AccountID, item.Family); errRevoke != nil {
+				return resp, errRevoke
+			}
+		}
+
 		return resp, err
 	}
+	if item.Family != family {
+		return resp, exception.ErrTokenRevoked
+	}
 
-	rToken, err := util.CreateAuthRefreshToken(*s.cfg, item.Username)
+	account, err := s.repo.GetByUsername(username)
+	if err != nil {
+		return resp, err
+	}
+	if account.ID != item.AccountID {
+		return resp, exception.ErrTokenRevoked
+	}
+
+	resp, err = s.issueTokens(&account, item.Family)
+	if err != nil {
+		return resp, err
+	}
+
+	err = s.sessionRepo.Touch(item.Family, client.IPAddress, s.refreshExpiry())
+	return resp, err
+}
+
+func (s *AccountService) UpdateRole(params *dto.AccountRoleUpdateReq, actorID uint, client dto.ClientInfo) error {
+	if params.ID <= 0 {
+		return exception.ErrUserNotFound
+	}
+
+	before, err := s.repo.GetByID(params.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateRole(params.ID, domain.TypeRole(params.Role)); err != nil {
+		return err
+	}
+
+	return s.recordUpdate(&before, actorID, client)
+}
+
+// ChangePassword replaces the password of a logged-in account after checking
+// the current one. Every token issued so far is revoked afterwards.
+func (s *AccountService) ChangePassword(accountID uint, p *dto.AccountPasswordChangeReq) error {
+	account, err := s.repo.GetByID(accountID)
+	if err != nil {
+		return err
+	}
+
+	if paswdOk := account.VerifyPassword(p.Password); !paswdOk {
+		return exception.ErrPasswordMismatch
+	}
+
+	if err := s.checkPassword(&account, "new_paswd", p.NewPassword); err != nil {
+		return err
+	}
+
+	return s.setPassword(&account, p.NewPassword)
+}
+
+// ForgotPassword sends a reset code to the account holder. Any code sent
+// earlier stops working. Unknown usernames are ignored silently so the
+// endpoint can not be used to probe for accounts. Requests are limited per
+// username and IP apart from logins.
+func (s *AccountService) ForgotPassword(p *dto.AccountPasswordForgotReq, client dto.ClientInfo) error {
+	if err := s.resetLimit.Check(p.Username, client.IPAddress); err != nil {
+		return err
+	}
+	if err := s.resetLimit.Fail(p.Username, client.IPAddress); err != nil {
+		return err
+	}
+
+	account, err := s.repo.GetByUsername(p.Username)
+	if err != nil {
+		if errors.Is(err, exception.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	code := util.RandomNumber(resetCodeLength)
+	codeHash, err := util.PasswordHash(code)
+	if err != nil {
+		return err
+	}
+
+	err = s.resetRepo.Replace(&dao.PasswordReset{
+		AccountID: account.ID,
+		CodeHash:  codeHash,
+		ExpiredAt: time.Now().UTC().
+			Add(time.Duration(s.cfg.AuthN.PasswordResetTTL) * time.Second),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.notifier.SendCode(account.Username, "password-reset", code)
+}
+
+// ResetPassword redeems a reset code and sets a new password. A code is
+// spent after a successful reset, and after too many wrong guesses. Wrong
+// codes count against the same limit as reset requests. Every token issued so
+// far is revoked afterwards.
+func (s *AccountService) ResetPassword(p *dto.AccountPasswordResetReq, client dto.ClientInfo) error {
+	if err := s.resetLimit.Check(p.Username, client.IPAddress); err != nil {
+		return err
+	}
+
+	err := s.resetPassword(p)
+	if errors.Is(err, exception.ErrResetCodeInvalid) {
+		if errThrottle := s.resetLimit.Fail(p.Username, client.IPAddress); errThrottle != nil {
+			return errThrottle
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.resetLimit.Succeed(p.Username)
+}
+
+func (s *AccountService) resetPassword(p *dto.AccountPasswordResetReq) error {
+	account, err := s.repo.GetByUsername(p.Username)
+	if err != nil {
+		if errors.Is(err, exception.ErrUserNotFound) {
+			return exception.ErrResetCodeInvalid
+		}
+		return err
+	}
+
+	item, err := s.resetRepo.GetActive(account.ID)
+	if err != nil {
+		if errors.Is(err, exception.ErrDataNotFound) {
+			return exception.ErrResetCodeInvalid
+		}
+		return err
+	}
+
+	if item.Attempts >= s.cfg.AuthN.PasswordResetMaxAttempt {
+		return exception.ErrResetCodeInvalid
+	}
+	if codeOk := util.VerifyPasswordHash(item.CodeHash, p.Code); !codeOk {
+		if err := s.resetRepo.AddAttempt(item.ID); err != nil {
+			return err
+		}
+		return exception.ErrResetCodeInvalid
+	}
+
+	if err := s.checkPassword(&account, "new_paswd", p.NewPassword); err != nil {
+		return err
+	}
+
+	if err := s.resetRepo.MarkUsed(item.ID); err != nil {
+		if errors.Is(err, exception.ErrDataNotFound) {
+			return exception.ErrResetCodeInvalid
+		}
+		return err
+	}
+
+	return s.setPassword(&account, p.NewPassword)
+}
+
+// Logout revokes the presented access token and ends its session.
+func (s *AccountService) Logout(accountID uint, tokenID, family string) error {
+	expiredAt := time.Now().UTC().
+		Add(time.Duration(s.cfg.AuthN.JWTAuthTTL) * time.Second)
+	if err := s.revokedRepo.RevokeToken(accountID, tokenID, expiredAt); err != nil {
+		return err
+	}
+
+	if family == "" {
+		return nil
+	}
+
+	return s.revokeFamily(accountID, family)
+}
+
+// GetSessions lists the account's active sessions. The session of the
+// presented token is flagged as current.
+func (s *AccountService) GetSessions(accountID uint, family string) ([]dto.AccountSessionResp, error) {
+	var resp []dto.AccountSessionResp
+
+	items, err := s.sessionRepo.GetActiveByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) < 1 {
+		return nil, exception.ErrDataNotFound
+	}
+
+	for _, item := range items {
+		var t dto.AccountSessionResp
+		t.FromEntity(&item)
+		t.Current = item.Family == family
+
+		resp = append(resp, t)
+	}
+
+	return resp, nil
+}
+
+// RevokeSession signs one of the account's devices out.
+func (s *AccountService) RevokeSession(accountID, sessionID uint) error {
+	item, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		if errors.Is(err, exception.ErrDataNotFound) {
+			return exception.ErrSessionNotFound
+		}
+		return err
+	}
+	if item.AccountID != accountID || item.RevokedAt != nil {
+		return exception.ErrSessionNotFound
+	}
+
+	return s.revokeFamily(accountID, item.Family)
+}
+
+// LogoutAll revokes every token issued to an account so far.
+func (s *AccountService) LogoutAll(accountID uint) error {
+	if err := s.revokedRepo.RevokeAccount(accountID, s.revokeAllExpiry()); err != nil {
+		return err
+	}
+
+	if err := s.refreshRepo.RevokeAccount(accountID); err != nil {
+		return err
+	}
+
+	return s.sessionRepo.RevokeAccount(accountID)
+}
+
+// PurgeExpiredTokens removes token records that are past their expiry.
+func (s *AccountService) PurgeExpiredTokens() (int64, error) {
+	revoked, err := s.revokedRepo.PurgeExpired()
+	if err != nil {
+		return 0, err
+	}
+
+	resets, err := s.resetRepo.PurgeExpired()
+	if err != nil {
+		return revoked, err
+	}
+	revoked += resets
+
+	refresh, err := s.refreshRepo.PurgeExpired()
+	if err != nil {
+		return revoked, err
+	}
+
+	sessions, err := s.sessionRepo.PurgeExpired()
+	if err != nil {
+		return revoked + refresh, err
+	}
+	refresh += sessions
+
+	apiKeys, err := s.apiKeyRepo.PurgeExpired()
+	if err != nil {
+		return revoked + refresh, err
+	}
+	refresh += apiKeys
+
+	attempts, err := s.throttle.PurgeExpired()
+	if err != nil {
+		return revoked + refresh, err
+	}
+
+	return revoked + refresh + attempts, nil
+}
+
+// checkPassword applies the password policy to a new password. For an
+// existing account it also rejects the last HistorySize passwords, counting
+// the current one.
+// Field is the JSON name of the request field, as in binding errors, so the
+// error can be shown next to it.
+func (s *AccountService) checkPassword(account *dao.Account, field, paswd string) error {
+	messages := s.policy.Check(field, paswd)
+
+	if account != nil && s.policy.HistorySize > 0 {
+		reused := account.VerifyPassword(paswd)
+		if !reused && s.policy.HistorySize > 1 {
+			history, err := s.repo.GetPasswordHistory(account.ID, s.policy.HistorySize-1)
+			if err != nil {
+				return err
+			}
+			for _, item := range history {
+				if util.VerifyPasswordHash(item.Password, paswd) {
+					reused = true
+					break
+				}
+			}
+		}
+		if reused {
+			messages = append(messages, s.policy.ReuseMessage(field))
+		}
+	}
+
+	if len(messages) > 0 {
+		return &exception.ValidationError{Field: field, Messages: messages}
+	}
+
+	return nil
+}
+
+// failLogin counts a failed password or second factor against both the
+// throttle and the account's lockout counter.
+func (s *AccountService) failLogin(account *dao.Account, client dto.ClientInfo) error {
+	if err := s.throttle.Fail(account.Username, client.IPAddress); err != nil {
+		return err
+	}
+
+	locked, err := s.repo.AddFailedLogin(account.ID, s.cfg.AuthN.LoginLockoutThreshold)
+	if err != nil {
+		return err
+	}
+	if locked {
+		log.Warn().Uint("account_id", account.ID).Msg("AccountService: account locked")
+	}
+
+	return nil
+}
+
+// afterFirstFactor continues a login whose password, or external identity,
+// was accepted. Accounts with TOTP get a challenge token, every other account
+// is logged in.
+func (s *AccountService) afterFirstFactor(account *dao.Account, client dto.ClientInfo) (dto.AccountLoginResp, error) {
+	var resp dto.AccountLoginResp
+
+	if account.HasTOTP() {
+		s.recordLogin(account.Username, account, domain.LoginChallenge, client)
+
+		var err error
+		resp.ChallengeToken, err = util.CreateAuthChallengeToken(
+			*s.cfg, account.Username, util.NewTokenID())
+		return resp, err
+	}
+
+	return s.completeLogin(account, client)
+}
+
+// completeLogin ends a login that passed every factor.
+func (s *AccountService) completeLogin(account *dao.Account, client dto.ClientInfo) (dto.AccountLoginResp, error) {
+	if err := s.repo.ResetFailedLogins(account.ID); err != nil {
+		return dto.AccountLoginResp{}, err
+	}
+
+	s.recordLogin(account.Username, account, domain.LoginOK, client)
+
+	family := util.NewTokenID()
+	resp, err := s.issueTokens(account, family)
+	if err != nil {
+		return resp, err
+	}
+
+	err = s.sessionRepo.Create(&dao.Session{
+		AccountID:  account.ID,
+		Family:     family,
+		IPAddress:  client.IPAddress,
+		UserAgent:  truncateUserAgent(client.UserAgent),
+		UserOS:     client.UserOS,
+		LastUsedAt: time.Now().UTC(),
+		ExpiredAt:  s.refreshExpiry(),
+	})
+
+	return resp, err
+}
+
+// revokeFamily ends a session: its access tokens, refresh tokens and the
+// session record are all revoked.
+func (s *AccountService) revokeFamily(accountID uint, family string) error {
+	expiredAt := time.Now().UTC().
+		Add(time.Duration(s.cfg.AuthN.JWTAuthTTL) * time.Second)
+	if err := s.revokedRepo.RevokeFamily(accountID, family, expiredAt); err != nil {
+		return err
+	}
+
+	if err := s.refreshRepo.RevokeFamily(family); err != nil {
+		return err
+	}
+
+	return s.sessionRepo.RevokeFamily(family)
+}
+
+// recordUpdate reads the account back after a change made by an admin and
+// records the change in the audit log.
+func (s *AccountService) recordUpdate(before *dao.Account, actorID uint, client dto.ClientInfo) error {
+	after, err := s.repo.GetByID(before.ID)
+	if err != nil {
+		return err
+	}
+	s.audit.record(actorID, client, domain.EntityAccount, before.ID,
+		domain.AuditUpdate, before, &after)
+
+	return nil
+}
+
+// revokeAllExpiry is how long an account-wide revocation must be kept: until
+// the last token it covers has expired.
+func (s *AccountService) revokeAllExpiry() time.Time {
+	ttl := s.cfg.AuthN.JWTAuthTTL
+	if s.cfg.AuthN.JWTRefreshTTL > ttl {
+		ttl = s.cfg.AuthN.JWTRefreshTTL
+	}
+
+	return time.Now().UTC().Add(time.Duration(ttl) * time.Second)
+}
+
+func (s *AccountService) refreshExpiry() time.Time {
+	return time.Now().UTC().
+		Add(time.Duration(s.cfg.AuthN.JWTRefreshTTL) * time.Second)
+}
+
+// recordLogin writes an attempt to the login history. A failure is only
+// logged, it should not decide the outcome of the login.
+func (s *AccountService) recordLogin(
+	username string,
+	account *dao.Account,
+	result domain.TypeLoginResult,
+	client dto.ClientInfo,
+) {
+	item := dao.LoginHistory{
+		Username:  username,
+		Result:    result,
+		IPAddress: client.IPAddress,
+		UserAgent: truncateUserAgent(client.UserAgent),
+		UserOS:    client.UserOS,
+	}
+	if account != nil {
+		item.AccountID = &account.ID
+	}
+
+	if err := s.historyRepo.Create(&item); err != nil {
+		exception.LogError(err, "AccountService.recordLogin")
+	}
+}
+
+func (s *AccountService) setPassword(account *dao.Account, paswd string) error {
+	if err := account.SetPassword(paswd, s.cfg.AuthN.PasswordEncryptionSecret); err != nil {
+		return err
+	}
+
+	err := s.repo.UpdatePassword(account.ID, account.Password, s.policy.HistorySize-1)
+	if err != nil {
+		return err
+	}
+
+	return s.LogoutAll(account.ID)
+}
+
+func (s *AccountService) issueTokens(account *dao.Account, family string) (dto.AccountLoginResp, error) {
+	var resp dto.AccountLoginResp
+
+	aToken, err := util.CreateAuthAccessToken(*s.cfg, account.Username,
+		util.NewTokenID(), family, string(account.Role))
+	if err != nil {
+		return resp, err
+	}
+
+	tokenID := util.NewTokenID()
+	rToken, err := util.CreateAuthRefreshToken(*s.cfg, account.Username, tokenID, family)
+	if err != nil {
+		return resp, err
+	}
+
+	err = s.refreshRepo.Create(&dao.RefreshToken{
+		TokenID:   tokenID,
+		Family:    family,
+		AccountID: account.ID,
+		ExpiredAt: s.refreshExpiry(),
+	})
 	if err != nil {
 		return resp, err
 	}
@@ -47,3 +777,11 @@ func (s *AccountService) Login(p dto.AccountLoginReq) (dto.AccountLoginResp, err
 
 	return resp, nil
 }
+
+func truncateUserAgent(userAgent string) string {
+	if len(userAgent) > maxUserAgentLength {
+		return userAgent[:maxUserAgentLength]
+	}
+
+	return userAgent
+}
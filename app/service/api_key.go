@@ -0,0 +1,94 @@
+package service
+
+import (
+	"base-gin/app/domain/dao"
+	"base-gin/app/domain/dto"
+	"base-gin/exception"
+	"base-gin/util"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	apiKeyPrefix       = "bgk_"
+	apiKeyLength       = 40
+	apiKeyPrefixLength = 12
+)
+
+// CreateAPIKey issues a key acting as the account. The key itself is only
+// returned here; afterwards only its prefix is shown.
+func (s *AccountService) CreateAPIKey(accountID uint, p *dto.AccountAPIKeyCreateReq) (dto.AccountAPIKeyResp, error) {
+	var resp dto.AccountAPIKeyResp
+
+	days := p.ExpiresIn
+	if days == 0 {
+		days = s.cfg.AuthN.APIKeyTTL
+	}
+
+	// Drop duplicates, keeping the order the scopes were given in.
+	var scopes []string
+	seen := map[string]bool{}
+	for _, scope := range p.Scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	key := apiKeyPrefix + util.RandomString(apiKeyLength)
+	item := dao.APIKey{
+		AccountID: accountID,
+		Name:      p.Name,
+		Prefix:    key[:apiKeyPrefixLength],
+		KeyHash:   util.TokenHash(key),
+		Scopes:    strings.Join(scopes, ","),
+		ExpiredAt: time.Now().UTC().AddDate(0, 0, days),
+	}
+	if err := s.apiKeyRepo.Create(&item); err != nil {
+		return resp, err
+	}
+
+	resp.FromEntity(&item)
+	resp.Key = key
+
+	return resp, nil
+}
+
+// GetAPIKeys returns the account's keys that can still be used.
+func (s *AccountService) GetAPIKeys(accountID uint) ([]dto.AccountAPIKeyResp, error) {
+	var resp []dto.AccountAPIKeyResp
+
+	items, err := s.apiKeyRepo.GetActiveByAccountID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) < 1 {
+		return nil, exception.ErrDataNotFound
+	}
+
+	for _, item := range items {
+		var t dto.AccountAPIKeyResp
+		t.FromEntity(&item)
+
+		resp = append(resp, t)
+	}
+
+	return resp, nil
+}
+
+// RevokeAPIKey stops one of the account's keys from being accepted.
+func (s *AccountService) RevokeAPIKey(accountID, keyID uint) error {
+	item, err := s.apiKeyRepo.GetByID(keyID)
+	if err != nil {
+		if errors.Is(err, exception.ErrDataNotFound) {
+			return exception.ErrAPIKeyNotFound
+		}
+		return err
+	}
+	if item.AccountID != accountID || item.RevokedAt != nil {
+		return exception.ErrAPIKeyNotFound
+	}
+
+	return s.apiKeyRepo.Revoke(item.ID)
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"base-gin/app/domain"
+	"base-gin/app/domain/dao"
+	"base-gin/app/domain/dto"
+	"base-gin/app/repository"
+	"base-gin/exception"
+	"base-gin/storage"
+	"encoding/json"
+
+	"github.com/rs/zerolog/log"
+)
+
+const defaultAuditLimit = 50
+
+// AuditService keeps the audit log. The other services record every change
+// they make on behalf of an account, with the row before and after it.
+type AuditService struct {
+	repo *repository.AuditLogRepository
+}
+
+func newAuditService(repo *repository.AuditLogRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// GetList returns the audit records matching params, newest first. Admins
+// see the whole log, librarians only the changes to persons, to trace edits
+// of member records.
+func (s *AuditService) GetList(params *dto.AuditFilter, role domain.TypeRole) ([]dto.AuditLogResp, error) {
+	var resp []dto.AuditLogResp
+
+	if role != domain.RoleAdmin {
+		if params.Entity != "" && params.Entity != string(domain.EntityPerson) {
+			return nil, exception.ErrForbidden
+		}
+		params.Entity = string(domain.EntityPerson)
+	}
+
+	if params.Limit < 1 {
+		params.Limit = defaultAuditLimit
+	}
+
+	items, err := s.repo.GetList(params)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) < 1 {
+		return nil, exception.ErrDataNotFound
+	}
+
+	for _, item := range items {
+		var t dto.AuditLogResp
+		t.FromEntity(&item)
+
+		resp = append(resp, t)
+	}
+
+	return resp, nil
+}
+
+// record writes an audit record of a change made by the account actorID,
+// zero for the system. Before is nil for a created row and after for a
+// deleted one, see storage.AuditDiff. A failure is only logged, the change
+// is already made.
+func (s *AuditService) record(
+	actorID uint,
+	client dto.ClientInfo,
+	entity domain.TypeAuditEntity,
+	entityID uint,
+	action domain.TypeAuditAction,
+	before, after interface{},
+) {
+	item := dao.AuditLog{
+		Entity:    entity,
+		EntityID:  entityID,
+		Action:    action,
+		IPAddress: client.IPAddress,
+	}
+	if actorID != 0 {
+		item.ActorID = &actorID
+	}
+
+	changes, err := storage.AuditDiff(before, after)
+	if err == nil && len(changes) > 0 {
+		var diff []byte
+		diff, err = json.Marshal(changes)
+		item.Diff = string(diff)
+	}
+	if err != nil {
+		exception.LogError(err, "AuditService.record")
+	}
+
+	if err := s.repo.Create(&item); err != nil {
+		log.Error().Err(err).Str("entity", string(entity)).Uint("id", entityID).
+			Str("action", string(action)).Msg("AuditService.record: failed to save")
+	}
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"base-gin/app/domain"
+	"base-gin/app/domain/dao"
+	"base-gin/app/repository"
+	"base-gin/config"
+	"base-gin/exception"
+	"base-gin/util"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Authenticator checks a username & password. It returns
+// exception.ErrUserNotFound when it does not know the user, and
+// exception.ErrUserLoginFailed for a wrong password, together with the account
+// when there is one, so the failure counts against its lockout.
+type Authenticator interface {
+	Authenticate(username, paswd string) (dao.Account, error)
+}
+
+// newAuthenticator chains the backends listed in LOGIN_BACKENDS.
+func newAuthenticator(cfg *config.Config, accountRepo *repository.AccountRepository,
+	identityRepo *repository.ExternalIdentityRepository,
+) Authenticator {
+	var chain chainAuthenticator
+	for _, backend := range cfg.AuthN.LoginBackends {
+		switch backend {
+		case "local":
+			chain = append(chain, newLocalAuthenticator(accountRepo))
+		case "ldap":
+			chain = append(chain, newLDAPAuthenticator(cfg,
+				util.NewLDAPDirectory(cfg.LDAP), accountRepo, identityRepo))
+		}
+	}
+
+	if len(chain) == 1 {
+		return chain[0]
+	}
+
+	return chain
+}
+
+// chainAuthenticator asks each backend in turn until one accepts the
+// password. A backend that fails to answer is skipped, so a directory outage
+// does not block local accounts; its error is only returned when no other
+// backend knows the user.
+type chainAuthenticator []Authenticator
+
+func (c chainAuthenticator) Authenticate(username, paswd string) (dao.Account, error) {
+	var failed *dao.Account
+	var backendErr error
+	for _, backend := range c {
+		account, err := backend.Authenticate(username, paswd)
+		switch {
+		case err == nil:
+			return account, nil
+		case errors.Is(err, exception.ErrUserLoginFailed):
+			if failed == nil || failed.ID == 0 {
+				failed = &account
+			}
+		case !errors.Is(err, exception.ErrUserNotFound):
+			log.Warn().Err(err).Msg("chainAuthenticator: login backend failed, trying the next one")
+			backendErr = err
+		}
+	}
+
+	if failed != nil {
+		return *failed, exception.ErrUserLoginFailed
+	}
+	if backendErr != nil {
+		return dao.Account{}, backendErr
+	}
+
+	return dao.Account{}, exception.ErrUserNotFound
+}
+
+// localAuthenticator checks the bcrypt hash stored with the account.
+type localAuthenticator struct {
+	repo *repository.AccountRepository
+}
+
+func newLocalAuthenticator(repo *repository.AccountRepository) *localAuthenticator {
+	return &localAuthenticator{repo: repo}
+}
+
+func (a *localAuthenticator) Authenticate(username, paswd string) (dao.Account, error) {
+	account, err := a.repo.GetByUsername(username)
+	if err != nil {
+		return account, err
+	}
+
+	if paswdOk := account.VerifyPassword(paswd); !paswdOk {
+		return account, exception.ErrUserLoginFailed
+	}
+
+	return account, nil
+}
+
+// ldapAuthenticator binds to the directory as the user. Directory users are
+// linked to their account by DN, and an account is created on first login.
+type ldapAuthenticator struct {
+	cfg          *config.Config
+	directory    *util.LDAPDirectory
+	accountRepo  *repository.AccountRepository
+	identityRepo *repository.ExternalIdentityRepository
+}
+
+func newLDAPAuthenticator(
+	cfg *config.Config,
+	directory *util.LDAPDirectory,
+	accountRepo *repository.AccountRepository,
+	identityRepo *repository.ExternalIdentityRepository,
+) *ldapAuthenticator {
+	return &ldapAuthenticator{
+		cfg:          cfg,
+		directory:    directory,
+		accountRepo:  accountRepo,
+		identityRepo: identityRepo,
+	}
+}
+
+func (a *ldapAuthenticator) Authenticate(username, paswd string) (dao.Account, error) {
+	user, err := a.directory.Authenticate(username, paswd)
+	if err != nil {
+		switch {
+		case errors.Is(err, util.ErrLDAPUserNotFound):
+			return dao.Account{}, exception.ErrUserNotFound
+		case errors.Is(err, util.ErrLDAPInvalidCredentials):
+			identity, errGet := a.identityRepo.GetByIssuerSubject(a.cfg.LDAP.URL, user.DN)
+			if errGet == nil && identity.Account != nil {
+				return *identity.Account, exception.ErrUserLoginFailed
+			}
+			return dao.Account{}, exception.ErrUserLoginFailed
+		}
+		return dao.Account{}, err
+	}
+
+	account, err := a.getAccount(username, &user)
+	if err != nil {
+		return account, err
+	}
+
+	if role, ok := a.groupRole(user.Groups); ok && role != account.Role {
+		err := a.accountRepo.UpdateRole(account.ID, role)
+		switch {
+		case err == nil:
+			account.Role = role
+		case errors.Is(err, exception.ErrAccountLastAdmin):
+			// The last admin keeps the role, the login itself goes on.
+			log.Warn().Uint("account_id", account.ID).
+				Msg("ldapAuthenticator: group role would demote the last admin")
+		default:
+			return account, err
+		}
+	}
+
+	return account, nil
+}
+
+// getAccount returns the account linked to the directory user, creating one
+// on first login.
+func (a *ldapAuthenticator) getAccount(username string, user *util.LDAPUser) (dao.Account, error) {
+	identity, err := a.identityRepo.GetByIssuerSubject(a.cfg.LDAP.URL, user.DN)
+	if err == nil && identity.Account != nil {
+		if err := a.identityRepo.Touch(identity.ID, ""); err != nil {
+			exception.LogError(err, "ldapAuthenticator.getAccount")
+		}
+		return *identity.Account, nil
+	}
+	if err != nil && !errors.Is(err, exception.ErrDataNotFound) {
+		return dao.Account{}, err
+	}
+
+	// The account can only be reached through the directory, or after a
+	// password reset.
+	account, err := dao.NewUser(username, util.RandomString(32),
+		a.cfg.AuthN.PasswordEncryptionSecret)
+	if err != nil {
+		return account, err
+	}
+
+	person := dao.Person{Fullname: provisionFullname(user.Name, account.Username)}
+	newIdentity := dao.ExternalIdentity{
+		Issuer:      a.cfg.LDAP.URL,
+		Subject:     user.DN,
+		LastLoginAt: time.Now().UTC(),
+	}
+
+	err = a.accountRepo.CreateWithIdentity(&account, &person, &newIdentity)
+	if errors.Is(err, exception.ErrUserConflict) {
+		// Linking a local account of the same name would hand it to whoever
+		// holds the directory entry, so it is left to the next backend.
+		log.Warn().Str("username", account.Username).Str("dn", user.DN).
+			Msg("ldapAuthenticator: username taken by an unlinked account")
+		return dao.Account{}, exception.ErrUserNotFound
+	}
+
+	return account, err
+}
+
+// groupRole returns the highest role mapped to one of the groups. It returns
+// false when LDAP_GROUP_ROLES is empty and roles are managed locally.
+func (a *ldapAuthenticator) groupRole(groups []string) (domain.TypeRole, bool) {
+	if len(a.cfg.LDAP.GroupRoles) == 0 {
+		return "", false
+	}
+
+	role := domain.RoleMember
+	for mapped, mappedRole := range a.cfg.LDAP.GroupRoles {
+		for _, group := range groups {
+			if strings.EqualFold(mapped, group) &&
+				roleRank[domain.TypeRole(mappedRole)] > roleRank[role] {
+				role = domain.TypeRole(mappedRole)
+			}
+		}
+	}
+
+	return role, true
+}
+
+var roleRank = map[domain.TypeRole]int{
+	domain.RoleMember:    0,
+	domain.RoleLibrarian: 1,
+	domain.RoleAdmin:     2,
+}
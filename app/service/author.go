@@ -0,0 +1,120 @@
+package service
+
+import (
+	"base-gin/app/domain"
+	"base-gin/app/domain/dto"
+	"base-gin/app/repository"
+	"base-gin/exception"
+)
+
+type AuthorService struct {
+	repo  *repository.AuthorRepository
+	audit *AuditService
+}
+
+func newAuthorService(authorRepo *repository.AuthorRepository, audit *AuditService) *AuthorService {
+	return &AuthorService{repo: authorRepo, audit: audit}
+}
+
+func (s *AuthorService) Create(params *dto.AuthorCreateReq, actorID uint, client dto.ClientInfo) (*dto.AuthorDetailResp, error) {
+	newItem, err := params.ToEntity()
+	if err != nil {
+		exception.LogError(err, "AuthorService.Create")
+		return nil, exception.ErrDateParsing
+	}
+
+	if err := s.repo.Create(&newItem); err != nil {
+		return nil, err
+	}
+	s.audit.record(actorID, client, domain.EntityAuthor, newItem.ID,
+		domain.AuditCreate, nil, &newItem)
+
+	var resp dto.AuthorDetailResp
+	resp.FromEntity(&newItem)
+
+	return &resp, nil
+}
+
+func (s *AuthorService) GetByID(id uint) (dto.AuthorDetailResp, error) {
+	var resp dto.AuthorDetailResp
+
+	item, err := s.repo.GetByID(id)
+	if err != nil {
+		return resp, err
+	}
+
+	resp.FromEntity(item)
+
+	return resp, nil
+}
+
+func (s *AuthorService) GetList(params *dto.Filter) ([]dto.AuthorDetailResp, error) {
+	var resp []dto.AuthorDetailResp
+
+	items, err := s.repo.GetList(params)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) < 1 {
+		return nil, exception.ErrDataNotFound
+	}
+
+	for _, item := range items {
+		var t dto.AuthorDetailResp
+		t.FromEntity(&item)
+
+		resp = append(resp, t)
+	}
+
+	return resp, nil
+}
+
+func (s *AuthorService) Update(params *dto.AuthorUpdateReq, actorID uint, client dto.ClientInfo) error {
+	if params.ID <= 0 {
+		return exception.ErrDataNotFound
+	}
+
+	birthDate, err := params.GetBirthDate()
+	if err != nil {
+		exception.LogError(err, "AuthorService.Update")
+		return exception.ErrDateParsing
+	}
+	params.BirthDate = birthDate
+
+	before, err := s.repo.GetByID(params.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Update(params); err != nil {
+		return err
+	}
+
+	after, err := s.repo.GetByID(params.ID)
+	if err != nil {
+		return err
+	}
+	s.audit.record(actorID, client, domain.EntityAuthor, params.ID,
+		domain.AuditUpdate, before, after)
+
+	return nil
+}
+
+func (s *AuthorService) Delete(id uint, actorID uint, client dto.ClientInfo) error {
+	if id <= 0 {
+		return exception.ErrDataNotFound
+	}
+
+	before, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.audit.record(actorID, client, domain.EntityAuthor, id,
+		domain.AuditDelete, before, nil)
+
+	return nil
+}
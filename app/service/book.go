@@ -0,0 +1,207 @@
+package service
+
+import (
+	"base-gin/app/domain"
+	"base-gin/app/domain/dao"
+	"base-gin/app/domain/dto"
+	"base-gin/app/repository"
+	"base-gin/exception"
+	"errors"
+)
+
+type BookService struct {
+	repo          *repository.BookRepository
+	authorRepo    *repository.AuthorRepository
+	publisherRepo *repository.PublisherRepository
+	audit         *AuditService
+	versions      *VersionService
+}
+
+func newBookService(
+	bookRepo *repository.BookRepository,
+	authorRepo *repository.AuthorRepository,
+	publisherRepo *repository.PublisherRepository,
+	audit *AuditService,
+	versions *VersionService,
+) *BookService {
+	return &BookService{
+		repo:          bookRepo,
+		authorRepo:    authorRepo,
+		publisherRepo: publisherRepo,
+		audit:         audit,
+		versions:      versions,
+	}
+}
+
+func (s *BookService) checkReferences(authorID, publisherID uint) error {
+	if _, err := s.authorRepo.GetByID(authorID); err != nil {
+		if errors.Is(err, exception.ErrDataNotFound) {
+			return exception.ErrAuthorNotFound
+		}
+		return err
+	}
+
+	if _, err := s.publisherRepo.GetByID(publisherID); err != nil {
+		if errors.Is(err, exception.ErrDataNotFound) {
+			return exception.ErrPublisherNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *BookService) Create(params *dto.BookCreateReq, actorID uint, client dto.ClientInfo) (*dto.BookDetailResp, error) {
+	if err := s.checkReferences(params.AuthorID, params.PublisherID); err != nil {
+		return nil, err
+	}
+
+	newItem := params.ToEntity()
+	if err := s.repo.Create(&newItem); err != nil {
+		return nil, err
+	}
+	s.audit.record(actorID, client, domain.EntityBook, newItem.ID,
+		domain.AuditCreate, nil, &newItem)
+
+	return s.GetByID(newItem.ID)
+}
+
+func (s *BookService) GetByID(id uint) (*dto.BookDetailResp, error) {
+	item, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp dto.BookDetailResp
+	resp.FromEntity(item)
+
+	return &resp, nil
+}
+
+func (s *BookService) GetList(params *dto.Filter) ([]dto.BookDetailResp, error) {
+	var resp []dto.BookDetailResp
+
+	items, err := s.repo.GetList(params)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) < 1 {
+		return nil, exception.ErrDataNotFound
+	}
+
+	for _, item := range items {
+		var t dto.BookDetailResp
+		t.FromEntity(&item)
+
+		resp = append(resp, t)
+	}
+
+	return resp, nil
+}
+
+func (s *BookService) Update(params *dto.BookUpdateReq, actorID uint, client dto.ClientInfo) error {
+	if params.ID <= 0 {
+		return exception.ErrDataNotFound
+	}
+
+	if err := s.checkReferences(params.AuthorID, params.PublisherID); err != nil {
+		return err
+	}
+
+	before, err := s.repo.GetByID(params.ID)
+	if err != nil {
+		return err
+	}
+
+	// Only the book's own columns are kept, not its author & publisher.
+	row := *before
+	row.Author, row.Publisher = nil, nil
+	version, err := s.versions.newVersion(domain.EntityBook, params.ID, &row, actorID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Update(params, version); err != nil {
+		return err
+	}
+
+	after, err := s.repo.GetByID(params.ID)
+	if err != nil {
+		return err
+	}
+	s.audit.record(actorID, client, domain.EntityBook, params.ID,
+		domain.AuditUpdate, before, after)
+
+	return nil
+}
+
+// GetVersions returns the earlier versions of a book, newest first.
+func (s *BookService) GetVersions(id uint, params *dto.Filter) ([]dto.BookVersionResp, error) {
+	var resp []dto.BookVersionResp
+
+	if _, err := s.repo.GetByID(id); err != nil {
+		if errors.Is(err, exception.ErrDataNotFound) {
+			return nil, exception.ErrBookNotFound
+		}
+		return nil, err
+	}
+
+	items, err := s.versions.list(domain.EntityBook, id, params)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		var row dao.Book
+		if err := s.versions.decode(&item, &row); err != nil {
+			return nil, err
+		}
+
+		var t dto.BookVersionResp
+		t.VersionResp.FromEntity(&item, row.UpdatedAt)
+		t.Data.FromEntity(&row)
+
+		resp = append(resp, t)
+	}
+
+	return resp, nil
+}
+
+// GetVersion returns a version of a book as the update that restores it.
+func (s *BookService) GetVersion(id, version uint) (*dto.BookUpdateReq, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		if errors.Is(err, exception.ErrDataNotFound) {
+			return nil, exception.ErrBookNotFound
+		}
+		return nil, err
+	}
+
+	var row dao.Book
+	if err := s.versions.get(domain.EntityBook, id, version, &row); err != nil {
+		return nil, err
+	}
+
+	var req dto.BookUpdateReq
+	req.FromEntity(&row)
+	req.ID = id
+
+	return &req, nil
+}
+
+func (s *BookService) Delete(id uint, actorID uint, client dto.ClientInfo) error {
+	if id <= 0 {
+		return exception.ErrDataNotFound
+	}
+
+	before, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.audit.record(actorID, client, domain.EntityBook, id,
+		domain.AuditDelete, before, nil)
+
+	return nil
+}
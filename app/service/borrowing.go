@@ -0,0 +1,116 @@
+package service
+
+import (
+	"base-gin/app/domain"
+	"base-gin/app/domain/dto"
+	"base-gin/app/repository"
+	"base-gin/exception"
+	"errors"
+	"time"
+)
+
+type BorrowingService struct {
+	repo       *repository.BorrowingRepository
+	personRepo *repository.PersonRepository
+	bookRepo   *repository.BookRepository
+	audit      *AuditService
+}
+
+func newBorrowingService(
+	borrowingRepo *repository.BorrowingRepository,
+	personRepo *repository.PersonRepository,
+	bookRepo *repository.BookRepository,
+	audit *AuditService,
+) *BorrowingService {
+	return &BorrowingService{
+		repo:       borrowingRepo,
+		personRepo: personRepo,
+		bookRepo:   bookRepo,
+		audit:      audit,
+	}
+}
+
+func (s *BorrowingService) Checkout(params *dto.BorrowingCheckoutReq, actorID uint, client dto.ClientInfo) (*dto.BorrowingDetailResp, error) {
+	if _, err := s.personRepo.GetByID(params.PersonID); err != nil {
+		if errors.Is(err, exception.ErrUserNotFound) {
+			return nil, exception.ErrPersonNotFound
+		}
+		return nil, err
+	}
+
+	if _, err := s.bookRepo.GetByID(params.BookID); err != nil {
+		if errors.Is(err, exception.ErrDataNotFound) {
+			return nil, exception.ErrBookNotFound
+		}
+		return nil, err
+	}
+
+	newItem := params.ToEntity()
+	if err := s.repo.Checkout(&newItem); err != nil {
+		return nil, err
+	}
+	s.audit.record(actorID, client, domain.EntityBorrowing, newItem.ID,
+		domain.AuditCreate, nil, &newItem)
+
+	return s.GetByID(newItem.ID)
+}
+
+func (s *BorrowingService) Return(id uint, actorID uint, client dto.ClientInfo) (*dto.BorrowingDetailResp, error) {
+	if id <= 0 {
+		return nil, exception.ErrDataNotFound
+	}
+
+	before, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Return(id, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	after, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.record(actorID, client, domain.EntityBorrowing, id,
+		domain.AuditUpdate, before, after)
+
+	var resp dto.BorrowingDetailResp
+	resp.FromEntity(after)
+
+	return &resp, nil
+}
+
+func (s *BorrowingService) GetByID(id uint) (*dto.BorrowingDetailResp, error) {
+	item, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp dto.BorrowingDetailResp
+	resp.FromEntity(item)
+
+	return &resp, nil
+}
+
+func (s *BorrowingService) GetList(params *dto.BorrowingFilter) ([]dto.BorrowingDetailResp, error) {
+	var resp []dto.BorrowingDetailResp
+
+	items, err := s.repo.GetList(params)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) < 1 {
+		return nil, exception.ErrDataNotFound
+	}
+
+	for _, item := range items {
+		var t dto.BorrowingDetailResp
+		t.FromEntity(&item)
+
+		resp = append(resp, t)
+	}
+
+	return resp, nil
+}
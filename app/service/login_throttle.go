@@ -0,0 +1,92 @@
+package service
+
+import (
+	"base-gin/config"
+	"base-gin/exception"
+	"base-gin/storage"
+	"time"
+)
+
+const (
+	throttleKeyUsername = "u:"
+	throttleKeyIP       = "ip:"
+	throttleScopeReset  = "reset:"
+)
+
+// LoginThrottle limits failed logins per username and per client IP.
+type LoginThrottle struct {
+	store        storage.AttemptStore
+	scope        string // prefixes the keys of a throttle sharing the store
+	ttl          time.Duration
+	maxAttempt   int
+	maxAttemptIP int
+}
+
+func newLoginThrottle(cfg *config.Config, store storage.AttemptStore) *LoginThrottle {
+	return &LoginThrottle{
+		store:        store,
+		ttl:          time.Duration(cfg.AuthN.LoginThrottleTTL) * time.Second,
+		maxAttempt:   cfg.AuthN.LoginMaxAttempt,
+		maxAttemptIP: cfg.AuthN.LoginMaxAttemptIP,
+	}
+}
+
+// newResetThrottle limits password reset requests with the login limits, but
+// counted apart, so asking for reset codes does not lock anyone out of login.
+func newResetThrottle(cfg *config.Config, store storage.AttemptStore) *LoginThrottle {
+	t := newLoginThrottle(cfg, store)
+	t.scope = throttleScopeReset
+
+	return t
+}
+
+// Check returns an *exception.ThrottleError once either the username or the
+// IP address has reached its limit.
+func (t *LoginThrottle) Check(username, ip string) error {
+	limits := []struct {
+		key string
+		max int
+	}{
+		{t.scope + throttleKeyUsername + username, t.maxAttempt},
+		{t.scope + throttleKeyIP + ip, t.maxAttemptIP},
+	}
+
+	for _, limit := range limits {
+		count, expiredAt, err := t.store.Get(limit.key)
+		if err != nil {
+			return err
+		}
+		if limit.max > 0 && count >= limit.max {
+			return &exception.ThrottleError{
+				RetryAfter: time.Until(expiredAt),
+			}
+		}
+	}
+
+	return nil
+}
+
+// Fail records a failed login for both the username and the IP address.
+func (t *LoginThrottle) Fail(username, ip string) error {
+	if _, _, err := t.store.Hit(t.scope+throttleKeyUsername+username, t.ttl); err != nil {
+		return err
+	}
+
+	_, _, err := t.store.Hit(t.scope+throttleKeyIP+ip, t.ttl)
+	return err
+}
+
+// Succeed clears the counter of the username. The IP counter is kept so one
+// valid account can not be used to reset guesses against other accounts.
+func (t *LoginThrottle) Succeed(username string) error {
+	return t.store.Reset(t.scope + throttleKeyUsername + username)
+}
+
+// PurgeExpired removes ended windows when the store keeps them around.
+func (t *LoginThrottle) PurgeExpired() (int64, error) {
+	if p, ok := t.store.(interface{ PurgeExpired() (int64, error) }); ok {
+		return p.PurgeExpired()
+	}
+
+	return 0, nil
+}
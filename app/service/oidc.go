@@ -0,0 +1,229 @@
+package service
+
+import (
+	"base-gin/app/domain"
+	"base-gin/app/domain/dao"
+	"base-gin/app/domain/dto"
+	"base-gin/app/repository"
+	"base-gin/config"
+	"base-gin/exception"
+	"base-gin/util"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	oidcStateLength        = 32
+	oidcNonceLength        = 32
+	oidcCodeVerifierLength = 64
+	oidcBindingLength      = 32
+	oidcUsernameLength     = 12
+	oidcProvisionAttempts  = 5
+	maxFullnameLength      = 56
+)
+
+// OIDCService logs users in through an external OpenID Connect provider. An
+// account is found by the provider's `sub` claim; the `email` claim is kept
+// with the link and used to name accounts created on first login.
+type OIDCService struct {
+	cfg          *config.Config
+	provider     *util.OIDCProvider // nil when OIDC is disabled
+	stateRepo    *repository.OIDCStateRepository
+	identityRepo *repository.ExternalIdentityRepository
+	accountRepo  *repository.AccountRepository
+	accounts     *AccountService
+}
+
+func newOIDCService(
+	cfg *config.Config,
+	provider *util.OIDCProvider,
+	stateRepo *repository.OIDCStateRepository,
+	identityRepo *repository.ExternalIdentityRepository,
+	accountRepo *repository.AccountRepository,
+	accounts *AccountService,
+) *OIDCService {
+	return &OIDCService{
+		cfg:          cfg,
+		provider:     provider,
+		stateRepo:    stateRepo,
+		identityRepo: identityRepo,
+		accountRepo:  accountRepo,
+		accounts:     accounts,
+	}
+}
+
+// AuthURL starts a login: it remembers a new state, nonce & PKCE verifier and
+// returns the provider's login page to send the user to, with the binding
+// value the callback must present.
+func (s *OIDCService) AuthURL() (dto.AccountOIDCAuthResp, error) {
+	var resp dto.AccountOIDCAuthResp
+
+	if s.provider == nil {
+		return resp, exception.ErrOIDCDisabled
+	}
+
+	binding := util.RandomString(oidcBindingLength)
+	item := dao.OIDCState{
+		State:        util.RandomString(oidcStateLength),
+		Nonce:        util.RandomString(oidcNonceLength),
+		CodeVerifier: util.RandomString(oidcCodeVerifierLength),
+		BindingHash:  util.TokenHash(binding),
+		ExpiredAt: time.Now().UTC().
+			Add(time.Duration(s.cfg.OIDC.StateTTL) * time.Second),
+	}
+	if err := s.stateRepo.Create(&item); err != nil {
+		return resp, err
+	}
+
+	url, err := s.provider.AuthURL(item.State, item.Nonce, item.CodeVerifier)
+	if err != nil {
+		return resp, err
+	}
+	resp.URL = url
+	resp.Binding = binding
+
+	return resp, nil
+}
+
+// Login finishes a login with the code the provider sent to the redirect
+// URL. The account is created on first login when OIDC_AUTO_PROVISION is on.
+func (s *OIDCService) Login(p *dto.AccountOIDCCallbackReq, client dto.ClientInfo) (dto.AccountLoginResp, error) {
+	var resp dto.AccountLoginResp
+
+	if s.provider == nil {
+		return resp, exception.ErrOIDCDisabled
+	}
+
+	state, err := s.stateRepo.Consume(p.State, util.TokenHash(p.Binding))
+	if err != nil {
+		return resp, err
+	}
+
+	claims, err := s.provider.Exchange(p.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		if errors.Is(err, util.ErrOIDCTokenInvalid) {
+			exception.LogError(err, "OIDCService.Login")
+			return resp, exception.ErrOIDCLoginFailed
+		}
+		return resp, err
+	}
+
+	account, err := s.getAccount(&claims)
+	if err != nil {
+		if errors.Is(err, exception.ErrUserNotFound) {
+			s.accounts.recordLogin(claims.Subject, nil, domain.LoginUnknownUser, client)
+		}
+		return resp, err
+	}
+
+	if account.IsLocked() {
+		s.accounts.recordLogin(account.Username, &account, domain.LoginLocked, client)
+		return resp, exception.ErrUserLocked
+	}
+
+	return s.accounts.afterFirstFactor(&account, client)
+}
+
+// PurgeExpiredStates removes login requests whose callback never arrived.
+func (s *OIDCService) PurgeExpiredStates() (int64, error) {
+	return s.stateRepo.PurgeExpired()
+}
+
+// getAccount returns the account linked to the provider's user, creating one
+// on first login.
+func (s *OIDCService) getAccount(claims *util.OIDCClaims) (dao.Account, error) {
+	identity, err := s.identityRepo.GetByIssuerSubject(claims.Issuer, claims.Subject)
+	if err == nil && identity.Account != nil {
+		if err := s.identityRepo.Touch(identity.ID, claims.Email); err != nil {
+			exception.LogError(err, "OIDCService.getAccount")
+		}
+		return *identity.Account, nil
+	}
+	if err != nil && !errors.Is(err, exception.ErrDataNotFound) {
+		return dao.Account{}, err
+	}
+
+	if !s.cfg.OIDC.AutoProvision {
+		return dao.Account{}, exception.ErrUserNotFound
+	}
+
+	for i := 0; i < oidcProvisionAttempts; i++ {
+		username := oidcUsername(claims)
+		if i > 0 {
+			username += util.RandomNumber(16 - len(username))
+		}
+
+		// The account can only be reached through the provider, or after a
+		// password reset.
+		account, err := dao.NewUser(username, util.RandomString(32),
+			s.cfg.AuthN.PasswordEncryptionSecret)
+		if err != nil {
+			return account, err
+		}
+
+		person := dao.Person{Fullname: provisionFullname(claims.Name, username)}
+		identity := dao.ExternalIdentity{
+			Issuer:      claims.Issuer,
+			Subject:     claims.Subject,
+			Email:       claims.Email,
+			LastLoginAt: time.Now().UTC(),
+		}
+
+		err = s.accountRepo.CreateWithIdentity(&account, &person, &identity)
+		if err == nil {
+			return account, nil
+		}
+		if !errors.Is(err, exception.ErrUserConflict) {
+			return account, err
+		}
+
+		// A concurrent first login of the same user may have won the race.
+		existing, errGet := s.identityRepo.GetByIssuerSubject(claims.Issuer, claims.Subject)
+		if errGet == nil && existing.Account != nil {
+			return *existing.Account, nil
+		}
+	}
+
+	return dao.Account{}, exception.ErrUserConflict
+}
+
+// oidcUsername derives a username from the preferred username or the local
+// part of the email, keeping letters & digits only.
+func oidcUsername(claims *util.OIDCClaims) string {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(base) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+		if b.Len() == oidcUsernameLength {
+			break
+		}
+	}
+
+	if b.Len() < 3 {
+		return "user"
+	}
+
+	return b.String()
+}
+
+// provisionFullname returns the name an identity provider knows the user by,
+// falling back to the username.
+func provisionFullname(fullname, username string) string {
+	name := []rune(strings.TrimSpace(fullname))
+	if len(name) == 0 {
+		return username
+	}
+	if len(name) > maxFullnameLength {
+		name = name[:maxFullnameLength]
+	}
+
+	return string(name)
+}
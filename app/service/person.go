@@ -1,17 +1,44 @@
 package service
 
 import (
+	"base-gin/app/domain"
+	"base-gin/app/domain/dao"
 	"base-gin/app/domain/dto"
 	"base-gin/app/repository"
+	"base-gin/config"
 	"base-gin/exception"
+	"base-gin/util"
+	"errors"
+	"time"
 )
 
+const verifyCodeLength = 6
+
 type PersonService struct {
-	repo *repository.PersonRepository
+	cfg        *config.Config
+	repo       *repository.PersonRepository
+	verifyRepo *repository.EmailVerificationRepository
+	notifier   Notifier
+	audit      *AuditService
+	versions   *VersionService
 }
 
-func newPersonService(personRepo *repository.PersonRepository) *PersonService {
-	return &PersonService{repo: personRepo}
+func newPersonService(
+	cfg *config.Config,
+	personRepo *repository.PersonRepository,
+	verifyRepo *repository.EmailVerificationRepository,
+	notifier Notifier,
+	audit *AuditService,
+	versions *VersionService,
+) *PersonService {
+	return &PersonService{
+		cfg:        cfg,
+		repo:       personRepo,
+		verifyRepo: verifyRepo,
+		notifier:   notifier,
+		audit:      audit,
+		versions:   versions,
+	}
 }
 
 func (s *PersonService) GetAccountProfile(accountID uint) (dto.AccountProfileResp, error) {
@@ -27,7 +54,9 @@ func (s *PersonService) GetAccountProfile(accountID uint) (dto.AccountProfileRes
 	return resp, nil
 }
 
-func (s *PersonService) GetByID(id uint) (dto.PersonDetailResp, error) {
+// GetByID returns a person's detail. Only the person's owner and staff see
+// the contact details in full; accountID is 0 for anonymous callers.
+func (s *PersonService) GetByID(id, accountID uint, role domain.TypeRole) (dto.PersonDetailResp, error) {
 	var resp dto.PersonDetailResp
 
 	item, err := s.repo.GetByID(id)
@@ -39,13 +68,22 @@ func (s *PersonService) GetByID(id uint) (dto.PersonDetailResp, error) {
 	}
 
 	resp.FromEntity(item)
+	if !canManagePerson(item, accountID, role) {
+		resp.MaskContact()
+	}
 
 	return resp, nil
 }
 
-func (s *PersonService) GetList(params *dto.Filter) ([]dto.PersonDetailResp, error) {
+// GetList returns the persons matching params. Only staff may look persons up
+// by email.
+func (s *PersonService) GetList(params *dto.PersonFilter, accountID uint, role domain.TypeRole) ([]dto.PersonDetailResp, error) {
 	var resp []dto.PersonDetailResp
 
+	if params.Email != "" && role != domain.RoleLibrarian && role != domain.RoleAdmin {
+		return nil, exception.ErrForbidden
+	}
+
 	items, err := s.repo.GetList(params)
 	if err != nil {
 		return nil, err
@@ -57,6 +95,9 @@ func (s *PersonService) GetList(params *dto.Filter) ([]dto.PersonDetailResp, err
 	for _, item := range items {
 		var t dto.PersonDetailResp
 		t.FromEntity(&item)
+		if !canManagePerson(&item, accountID, role) {
+			t.MaskContact()
+		}
 
 		resp = append(resp, t)
 	}
@@ -64,11 +105,18 @@ func (s *PersonService) GetList(params *dto.Filter) ([]dto.PersonDetailResp, err
 	return resp, nil
 }
 
-func (s *PersonService) Update(params *dto.PersonUpdateReq) error {
+// Update saves a person's detail on behalf of the logged-in account. Members
+// may only update the person linked to their own account.
+func (s *PersonService) Update(params *dto.PersonUpdateReq, accountID uint, role domain.TypeRole, client dto.ClientInfo) error {
 	if params.ID <= 0 {
 		return exception.ErrUserNotFound
 	}
 
+	before, err := s.getManaged(params.ID, accountID, role)
+	if err != nil {
+		return err
+	}
+
 	birthDate, err := params.GetBirthDate()
 	if err != nil {
 		exception.LogError(err, "PersonService.Update")
@@ -76,5 +124,382 @@ func (s *PersonService) Update(params *dto.PersonUpdateReq) error {
 	}
 	params.BirthDate = birthDate
 
-	return s.repo.Update(params)
+	version, err := s.newVersion(before, accountID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Update(params, version); err != nil {
+		return err
+	}
+
+	return s.recordUpdate(before, accountID, client)
+}
+
+// UpdateContact replaces a person's email & phone. A new email has to be
+// verified again with the code sent to it.
+func (s *PersonService) UpdateContact(params *dto.PersonContactUpdateReq, accountID uint, role domain.TypeRole, client dto.ClientInfo) error {
+	item, err := s.getManaged(params.ID, accountID, role)
+	if err != nil {
+		return err
+	}
+
+	if err := checkPhone(params.Phone); err != nil {
+		return err
+	}
+	emailChanged := isEmailChanged(item, params.Email)
+
+	version, err := s.newVersion(item, accountID)
+	if err != nil {
+		return err
+	}
+	err = s.repo.UpdateContact(item.ID, optionalString(params.Email),
+		optionalString(params.Phone), emailChanged, version)
+	if err != nil {
+		return err
+	}
+	if err := s.recordUpdate(item, accountID, client); err != nil {
+		return err
+	}
+
+	if emailChanged && params.Email != "" {
+		return s.sendVerifyCode(item.ID, params.Email)
+	}
+
+	return nil
+}
+
+// RestoreVersion brings a person back to a version, given as the updates
+// returned by GetVersion, as one change: the detail and, unless contact is
+// nil, the contact details are saved together and kept as a single version.
+func (s *PersonService) RestoreVersion(params *dto.PersonUpdateReq, contact *dto.PersonContactUpdateReq, accountID uint, role domain.TypeRole, client dto.ClientInfo) error {
+	before, err := s.getManaged(params.ID, accountID, role)
+	if err != nil {
+		return err
+	}
+
+	birthDate, err := params.GetBirthDate()
+	if err != nil {
+		exception.LogError(err, "PersonService.RestoreVersion")
+		return exception.ErrDateParsing
+	}
+	params.BirthDate = birthDate
+
+	var contactRow *dao.Person
+	emailChanged := false
+	if contact != nil {
+		if err := checkPhone(contact.Phone); err != nil {
+			return err
+		}
+		emailChanged = isEmailChanged(before, contact.Email)
+		contactRow = &dao.Person{}
+		contactRow.SetContact(optionalString(contact.Email), optionalString(contact.Phone))
+	}
+
+	version, err := s.newVersion(before, accountID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.RestoreVersion(params, contactRow, emailChanged, version); err != nil {
+		return err
+	}
+	if err := s.recordUpdate(before, accountID, client); err != nil {
+		return err
+	}
+
+	if emailChanged && contact.Email != "" {
+		return s.sendVerifyCode(before.ID, contact.Email)
+	}
+
+	return nil
+}
+
+// SendEmailVerification sends a new code to the person's unverified email,
+// spending the previous one.
+func (s *PersonService) SendEmailVerification(id, accountID uint, role domain.TypeRole) error {
+	item, err := s.getManaged(id, accountID, role)
+	if err != nil {
+		return err
+	}
+	if item.Email == nil {
+		return exception.ErrEmailNotSet
+	}
+	if item.EmailVerifiedAt != nil {
+		return exception.ErrEmailVerified
+	}
+
+	return s.sendVerifyCode(item.ID, *item.Email)
+}
+
+// VerifyEmail redeems a code sent to the person's current email. A code is
+// spent once redeemed, and after too many wrong guesses.
+func (s *PersonService) VerifyEmail(id, accountID uint, role domain.TypeRole, p *dto.PersonEmailVerifyReq, client dto.ClientInfo) error {
+	item, err := s.getManaged(id, accountID, role)
+	if err != nil {
+		return err
+	}
+	if item.Email == nil {
+		return exception.ErrEmailNotSet
+	}
+	if item.EmailVerifiedAt != nil {
+		return exception.ErrEmailVerified
+	}
+
+	code, err := s.verifyRepo.GetActive(item.ID)
+	if err != nil {
+		if errors.Is(err, exception.ErrDataNotFound) {
+			return exception.ErrVerifyCodeInvalid
+		}
+		return err
+	}
+
+	if code.Attempts >= s.cfg.AuthN.EmailVerifyMaxAttempt || code.EmailIndex != util.PIIBlindIndex(*item.Email) {
+		return exception.ErrVerifyCodeInvalid
+	}
+	if codeOk := util.VerifyPasswordHash(code.CodeHash, p.Code); !codeOk {
+		if err := s.verifyRepo.AddAttempt(code.ID); err != nil {
+			return err
+		}
+		return exception.ErrVerifyCodeInvalid
+	}
+
+	if err := s.verifyRepo.MarkUsed(code.ID); err != nil {
+		if errors.Is(err, exception.ErrDataNotFound) {
+			return exception.ErrVerifyCodeInvalid
+		}
+		return err
+	}
+
+	version, err := s.newVersion(item, accountID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SetEmailVerified(item.ID, code.EmailIndex, version); err != nil {
+		if errors.Is(err, exception.ErrDataNotFound) {
+			return exception.ErrVerifyCodeInvalid
+		}
+		return err
+	}
+
+	return s.recordUpdate(item, accountID, client)
+}
+
+// PurgeExpiredCodes removes verification codes that can no longer be
+// redeemed.
+func (s *PersonService) PurgeExpiredCodes() (int64, error) {
+	return s.verifyRepo.PurgeExpired()
+}
+
+// Delete moves a person to the trash. Persons linked to an account are
+// anonymized with it instead, see AccountService.Anonymize.
+func (s *PersonService) Delete(id, actorID uint, client dto.ClientInfo) error {
+	before, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.audit.record(actorID, client, domain.EntityPerson, id,
+		domain.AuditDelete, before, nil)
+
+	return nil
+}
+
+// GetTrash returns the soft-deleted persons, most recently deleted first.
+func (s *PersonService) GetTrash(params *dto.Filter) ([]dto.PersonTrashResp, error) {
+	var resp []dto.PersonTrashResp
+
+	items, err := s.repo.GetTrash(params)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) < 1 {
+		return nil, exception.ErrUserNotFound
+	}
+
+	for _, item := range items {
+		var t dto.PersonTrashResp
+		t.FromEntity(&item)
+
+		resp = append(resp, t)
+	}
+
+	return resp, nil
+}
+
+// Restore takes a person out of the trash.
+func (s *PersonService) Restore(id, actorID uint, client dto.ClientInfo) error {
+	if err := s.repo.Restore(id); err != nil {
+		return err
+	}
+	s.audit.record(actorID, client, domain.EntityPerson, id,
+		domain.AuditRestore, nil, nil)
+
+	return nil
+}
+
+// PurgeDeleted removes the persons that have been in the trash since before
+// t for good. It returns the number of persons removed.
+func (s *PersonService) PurgeDeleted(t time.Time) (int64, error) {
+	ids, err := s.repo.PurgeDeletedBefore(t)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		s.audit.record(0, dto.ClientInfo{}, domain.EntityPerson, id,
+			domain.AuditPurge, nil, nil)
+	}
+
+	return int64(len(ids)), nil
+}
+
+func (s *PersonService) sendVerifyCode(personID uint, email string) error {
+	code := util.RandomNumber(verifyCodeLength)
+	codeHash, err := util.PasswordHash(code)
+	if err != nil {
+		return err
+	}
+
+	err = s.verifyRepo.Replace(&dao.EmailVerification{
+		PersonID:   personID,
+		EmailIndex: util.PIIBlindIndex(email),
+		CodeHash:   codeHash,
+		ExpiredAt: time.Now().UTC().
+			Add(time.Duration(s.cfg.AuthN.EmailVerifyTTL) * time.Second),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.notifier.SendCode(email, "email-verification", code)
+}
+
+// GetVersions returns the earlier versions of a person, newest first, to the
+// same accounts that may update it.
+func (s *PersonService) GetVersions(id, accountID uint, role domain.TypeRole, params *dto.Filter) ([]dto.PersonVersionResp, error) {
+	var resp []dto.PersonVersionResp
+
+	if _, err := s.getManaged(id, accountID, role); err != nil {
+		return nil, err
+	}
+
+	items, err := s.versions.list(domain.EntityPerson, id, params)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		var row dao.Person
+		if err := s.versions.decode(&item, &row); err != nil {
+			return nil, err
+		}
+
+		var t dto.PersonVersionResp
+		t.VersionResp.FromEntity(&item, row.UpdatedAt)
+		t.Data.FromEntity(&row)
+		t.Contact.FromEntity(&row)
+
+		resp = append(resp, t)
+	}
+
+	return resp, nil
+}
+
+// GetVersion returns a version of a person as the updates that restore it.
+// The contact update is nil when the contact details did not change since.
+func (s *PersonService) GetVersion(id, version, accountID uint, role domain.TypeRole) (*dto.PersonUpdateReq, *dto.PersonContactUpdateReq, error) {
+	item, err := s.getManaged(id, accountID, role)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var row dao.Person
+	if err := s.versions.get(domain.EntityPerson, id, version, &row); err != nil {
+		return nil, nil, err
+	}
+
+	var req dto.PersonUpdateReq
+	req.FromEntity(&row)
+	req.ID = id
+
+	var current, contact dto.PersonContactUpdateReq
+	current.FromEntity(item)
+	contact.FromEntity(&row)
+	contact.ID = id
+	if contact == current {
+		return &req, nil, nil
+	}
+
+	return &req, &contact, nil
+}
+
+// newVersion prepares the person as it was before a change, to be saved by the
+// repository together with the change.
+func (s *PersonService) newVersion(before *dao.Person, accountID uint) (*dao.EntityVersion, error) {
+	return s.versions.newVersion(domain.EntityPerson, before.ID, before, accountID)
+}
+
+// recordUpdate reads the person back after a change and records the change
+// in the audit log.
+func (s *PersonService) recordUpdate(before *dao.Person, accountID uint, client dto.ClientInfo) error {
+	after, err := s.repo.GetByID(before.ID)
+	if err != nil {
+		return err
+	}
+	s.audit.record(accountID, client, domain.EntityPerson, before.ID,
+		domain.AuditUpdate, before, after)
+
+	return nil
+}
+
+// getManaged returns the person if the logged-in account may change it:
+// members only their own person, librarians and admins anyone.
+func (s *PersonService) getManaged(id, accountID uint, role domain.TypeRole) (*dao.Person, error) {
+	item, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !canManagePerson(item, accountID, role) {
+		return nil, exception.ErrPersonForbidden
+	}
+
+	return item, nil
+}
+
+func canManagePerson(item *dao.Person, accountID uint, role domain.TypeRole) bool {
+	if role == domain.RoleLibrarian || role == domain.RoleAdmin {
+		return true
+	}
+
+	return accountID != 0 && item.AccountID != nil && *item.AccountID == accountID
+}
+
+func checkPhone(phone string) error {
+	if phone != "" && !util.ValidatePhoneNumber(phone) {
+		return &exception.ValidationError{
+			Field:    "phone",
+			Messages: []string{"phone harus diawali kode negara tanpa + atau 0, mis. 628123456789"},
+		}
+	}
+
+	return nil
+}
+
+// isEmailChanged tells whether email, empty for none, differs from the
+// person's current email.
+func isEmailChanged(item *dao.Person, email string) bool {
+	if item.Email == nil {
+		return email != ""
+	}
+
+	return *item.Email != email
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
 }
@@ -1,28 +1,258 @@
 package service
 
 import (
-	"base-gin/app/repository"
+	"base-gin/app/domain"
+	"base-gin/app/domain/dao"
 	"base-gin/app/domain/dto"
+	"base-gin/app/repository"
+	"base-gin/exception"
+	"errors"
+	"time"
 )
 
 type PublisherService struct {
-	repo *repository.PublisherRepository
+	repo     *repository.PublisherRepository
+	bookRepo *repository.BookRepository
+	audit    *AuditService
+	versions *VersionService
 }
 
-func newPublisherService(publisherRepo *repository.PublisherRepository) *PublisherService {
-	return &PublisherService{repo: publisherRepo}
+func newPublisherService(
+	publisherRepo *repository.PublisherRepository,
+	bookRepo *repository.BookRepository,
+	audit *AuditService,
+	versions *VersionService,
+) *PublisherService {
+	return &PublisherService{
+		repo:     publisherRepo,
+		bookRepo: bookRepo,
+		audit:    audit,
+		versions: versions,
+	}
 }
 
-func (s *PublisherService) Create(params *dto.PublisherCreateReq) (*dto.PublisherCreateResp, error) {
+func (s *PublisherService) Create(params *dto.PublisherCreateReq, actorID uint, client dto.ClientInfo) (*dto.PublisherCreateResp, error) {
 	newItem := params.ToEntity()
 
 	err := s.repo.Create(&newItem)
 	if err != nil {
 		return nil, err
 	}
+	s.audit.record(actorID, client, domain.EntityPublisher, newItem.ID,
+		domain.AuditCreate, nil, &newItem)
 
 	var resp dto.PublisherCreateResp
 	resp.FromEntity(&newItem)
 
 	return &resp, nil
-}
\ No newline at end of file
+}
+
+func (s *PublisherService) GetByID(id uint) (dto.PublisherDetailResp, error) {
+	var resp dto.PublisherDetailResp
+
+	item, err := s.repo.GetByID(id)
+	if err != nil {
+		return resp, err
+	}
+
+	resp.FromEntity(item)
+
+	return resp, nil
+}
+
+func (s *PublisherService) GetList(params *dto.Filter) ([]dto.PublisherDetailResp, error) {
+	var resp []dto.PublisherDetailResp
+
+	items, err := s.repo.GetList(params)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) < 1 {
+		return nil, exception.ErrDataNotFound
+	}
+
+	for _, item := range items {
+		var t dto.PublisherDetailResp
+		t.FromEntity(&item)
+
+		resp = append(resp, t)
+	}
+
+	return resp, nil
+}
+
+func (s *PublisherService) GetBooks(id uint, params *dto.Filter) ([]dto.BookDetailResp, error) {
+	var resp []dto.BookDetailResp
+
+	if _, err := s.repo.GetByID(id); err != nil {
+		if errors.Is(err, exception.ErrDataNotFound) {
+			return nil, exception.ErrPublisherNotFound
+		}
+		return nil, err
+	}
+
+	items, err := s.bookRepo.GetListByPublisherID(id, params)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) < 1 {
+		return nil, exception.ErrDataNotFound
+	}
+
+	for _, item := range items {
+		var t dto.BookDetailResp
+		t.FromEntity(&item)
+
+		resp = append(resp, t)
+	}
+
+	return resp, nil
+}
+
+func (s *PublisherService) Update(params *dto.PublisherUpdateReq, actorID uint, client dto.ClientInfo) error {
+	if params.ID <= 0 {
+		return exception.ErrDataNotFound
+	}
+
+	before, err := s.repo.GetByID(params.ID)
+	if err != nil {
+		return err
+	}
+
+	version, err := s.versions.newVersion(domain.EntityPublisher, params.ID, before, actorID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Update(params, version); err != nil {
+		return err
+	}
+
+	after, err := s.repo.GetByID(params.ID)
+	if err != nil {
+		return err
+	}
+	s.audit.record(actorID, client, domain.EntityPublisher, params.ID,
+		domain.AuditUpdate, before, after)
+
+	return nil
+}
+
+// GetTrash returns the soft-deleted publishers, most recently deleted first.
+func (s *PublisherService) GetTrash(params *dto.Filter) ([]dto.PublisherTrashResp, error) {
+	var resp []dto.PublisherTrashResp
+
+	items, err := s.repo.GetTrash(params)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) < 1 {
+		return nil, exception.ErrDataNotFound
+	}
+
+	for _, item := range items {
+		var t dto.PublisherTrashResp
+		t.FromEntity(&item)
+
+		resp = append(resp, t)
+	}
+
+	return resp, nil
+}
+
+// Restore takes a publisher out of the trash.
+func (s *PublisherService) Restore(id, actorID uint, client dto.ClientInfo) error {
+	if err := s.repo.Restore(id); err != nil {
+		return err
+	}
+	s.audit.record(actorID, client, domain.EntityPublisher, id,
+		domain.AuditRestore, nil, nil)
+
+	return nil
+}
+
+// PurgeDeleted removes the publishers that have been in the trash since
+// before t for good. It returns the number of publishers removed.
+func (s *PublisherService) PurgeDeleted(t time.Time) (int64, error) {
+	ids, err := s.repo.PurgeDeletedBefore(t)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		s.audit.record(0, dto.ClientInfo{}, domain.EntityPublisher, id,
+			domain.AuditPurge, nil, nil)
+	}
+
+	return int64(len(ids)), nil
+}
+
+// GetVersions returns the earlier versions of a publisher, newest first.
+func (s *PublisherService) GetVersions(id uint, params *dto.Filter) ([]dto.PublisherVersionResp, error) {
+	var resp []dto.PublisherVersionResp
+
+	if _, err := s.repo.GetByID(id); err != nil {
+		if errors.Is(err, exception.ErrDataNotFound) {
+			return nil, exception.ErrPublisherNotFound
+		}
+		return nil, err
+	}
+
+	items, err := s.versions.list(domain.EntityPublisher, id, params)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		var row dao.Publisher
+		if err := s.versions.decode(&item, &row); err != nil {
+			return nil, err
+		}
+
+		var t dto.PublisherVersionResp
+		t.VersionResp.FromEntity(&item, row.UpdatedAt)
+		t.Data.FromEntity(&row)
+
+		resp = append(resp, t)
+	}
+
+	return resp, nil
+}
+
+// GetVersion returns a version of a publisher as the update that restores it.
+func (s *PublisherService) GetVersion(id, version uint) (*dto.PublisherUpdateReq, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		if errors.Is(err, exception.ErrDataNotFound) {
+			return nil, exception.ErrPublisherNotFound
+		}
+		return nil, err
+	}
+
+	var row dao.Publisher
+	if err := s.versions.get(domain.EntityPublisher, id, version, &row); err != nil {
+		return nil, err
+	}
+
+	var req dto.PublisherUpdateReq
+	req.FromEntity(&row)
+	req.ID = id
+
+	return &req, nil
+}
+
+func (s *PublisherService) Delete(id uint, actorID uint, client dto.ClientInfo) error {
+	if id <= 0 {
+		return exception.ErrDataNotFound
+	}
+
+	before, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.audit.record(actorID, client, domain.EntityPublisher, id,
+		domain.AuditDelete, before, nil)
+
+	return nil
+}
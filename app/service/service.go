@@ -3,18 +3,65 @@ package service
 import (
 	"base-gin/app/repository"
 	"base-gin/config"
+	"base-gin/storage"
+	"base-gin/util"
+
+	"github.com/rs/zerolog/log"
 )
 
 var (
-	accountService *AccountService
-	personService  *PersonService
+	accountService   *AccountService
+	personService    *PersonService
 	publisherService *PublisherService
+	bookService      *BookService
+	authorService    *AuthorService
+	borrowingService *BorrowingService
+	oidcService      *OIDCService
+	auditService     *AuditService
 )
 
 func SetupServices(cfg *config.Config) {
-	accountService = newAccountService(cfg, repository.GetAccountRepo())
-	personService = newPersonService(repository.GetPersonRepo())
-	publisherService = newPublisherService(repository.GetPublisherRepo())
+	var attemptStore storage.AttemptStore = storage.NewMemoryAttemptStore()
+	if cfg.AuthN.LoginThrottleStore == "db" {
+		attemptStore = repository.GetLoginAttemptRepo()
+	}
+
+	policy, err := util.NewPasswordPolicy(cfg.AuthN)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load password policy")
+	}
+
+	notifier := newLogNotifier(cfg.App.Mode)
+	auditService = newAuditService(repository.GetAuditLogRepo())
+	versionService := newVersionService(repository.GetEntityVersionRepo())
+
+	accountService = newAccountService(cfg, repository.GetAccountRepo(),
+		repository.GetRefreshTokenRepo(), repository.GetRevokedTokenRepo(),
+		repository.GetPasswordResetRepo(), repository.GetLoginHistoryRepo(),
+		repository.GetSessionRepo(), repository.GetAPIKeyRepo(),
+		repository.GetPersonRepo(), repository.GetBorrowingRepo(),
+		newLoginThrottle(cfg, attemptStore), newResetThrottle(cfg, attemptStore),
+		notifier, policy, auditService,
+		newAuthenticator(cfg, repository.GetAccountRepo(),
+			repository.GetExternalIdentityRepo()))
+	var oidcProvider *util.OIDCProvider
+	if cfg.OIDC.Enabled {
+		oidcProvider = util.NewOIDCProvider(cfg.OIDC, nil)
+	}
+	oidcService = newOIDCService(cfg, oidcProvider, repository.GetOIDCStateRepo(),
+		repository.GetExternalIdentityRepo(), repository.GetAccountRepo(),
+		accountService)
+	personService = newPersonService(cfg, repository.GetPersonRepo(),
+		repository.GetEmailVerificationRepo(), notifier, auditService,
+		versionService)
+	publisherService = newPublisherService(repository.GetPublisherRepo(),
+		repository.GetBookRepo(), auditService, versionService)
+	bookService = newBookService(repository.GetBookRepo(),
+		repository.GetAuthorRepo(), repository.GetPublisherRepo(), auditService,
+		versionService)
+	authorService = newAuthorService(repository.GetAuthorRepo(), auditService)
+	borrowingService = newBorrowingService(repository.GetBorrowingRepo(),
+		repository.GetPersonRepo(), repository.GetBookRepo(), auditService)
 }
 
 func GetAccountService() *AccountService {
@@ -25,10 +72,30 @@ func GetAccountService() *AccountService {
 	return accountService
 }
 
+func GetOIDCService() *OIDCService {
+	return oidcService
+}
+
 func GetPersonService() *PersonService {
 	return personService
 }
 
 func GetPublisherServide() *PublisherService {
 	return publisherService
-}
\ No newline at end of file
+}
+
+func GetBookService() *BookService {
+	return bookService
+}
+
+func GetAuthorService() *AuthorService {
+	return authorService
+}
+
+func GetBorrowingService() *BorrowingService {
+	return borrowingService
+}
+
+func GetAuditService() *AuditService {
+	return auditService
+}
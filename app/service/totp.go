@@ -0,0 +1,217 @@
+package service
+
+import (
+	"base-gin/app/domain"
+	"base-gin/app/domain/dao"
+	"base-gin/app/domain/dto"
+	"base-gin/exception"
+	"base-gin/util"
+	"errors"
+	"strings"
+	"time"
+)
+
+const recoveryCodeLength = 10
+
+// SetupTOTP generates a new TOTP secret for the account. Login keeps working
+// without a code until the secret is confirmed with EnableTOTP.
+func (s *AccountService) SetupTOTP(accountID uint) (dto.AccountTOTPSetupResp, error) {
+	var resp dto.AccountTOTPSetupResp
+
+	account, err := s.repo.GetByID(accountID)
+	if err != nil {
+		return resp, err
+	}
+	if account.HasTOTP() {
+		return resp, exception.ErrTOTPEnabled
+	}
+
+	secret, err := util.NewTOTPSecret()
+	if err != nil {
+		return resp, err
+	}
+
+	encrypted, err := util.EncryptAESGCM(secret, s.cfg.AuthN.PasswordEncryptionSecret)
+	if err != nil {
+		return resp, err
+	}
+
+	if err := s.repo.SetTOTPSecret(account.ID, encrypted); err != nil {
+		return resp, err
+	}
+
+	resp.Secret = secret
+	resp.URI = util.TOTPProvisioningURI(s.cfg.AuthN.TOTPIssuer, account.Username, secret)
+
+	return resp, nil
+}
+
+// EnableTOTP confirms enrollment with a code from the authenticator and
+// returns a fresh set of recovery codes. The codes are only shown here.
+func (s *AccountService) EnableTOTP(accountID uint, p *dto.AccountTOTPEnableReq) (dto.AccountTOTPEnableResp, error) {
+	var resp dto.AccountTOTPEnableResp
+
+	account, err := s.repo.GetByID(accountID)
+	if err != nil {
+		return resp, err
+	}
+	if account.HasTOTP() {
+		return resp, exception.ErrTOTPEnabled
+	}
+	if account.TOTPSecret == nil {
+		return resp, exception.ErrTOTPNotEnrolled
+	}
+
+	secret, err := util.DecryptAESGCM(*account.TOTPSecret, s.cfg.AuthN.PasswordEncryptionSecret)
+	if err != nil {
+		return resp, err
+	}
+
+	step, ok := util.VerifyTOTP(secret, p.Code, time.Now())
+	if !ok {
+		return resp, exception.ErrTOTPInvalid
+	}
+
+	codes := make([]string, s.cfg.AuthN.TOTPRecoveryCodes)
+	codeHashes := make([]string, len(codes))
+	for i := range codes {
+		codes[i] = strings.ToLower(util.RandomString(recoveryCodeLength))
+		if codeHashes[i], err = util.PasswordHash(codes[i]); err != nil {
+			return resp, err
+		}
+	}
+
+	if err := s.repo.EnableTOTP(account.ID, step, codeHashes); err != nil {
+		return resp, err
+	}
+
+	resp.RecoveryCodes = codes
+
+	return resp, nil
+}
+
+// DisableTOTP turns two-factor authentication off. Both the password and a
+// TOTP or recovery code are required.
+func (s *AccountService) DisableTOTP(accountID uint, p *dto.AccountTOTPDisableReq) error {
+	account, err := s.repo.GetByID(accountID)
+	if err != nil {
+		return err
+	}
+	if !account.HasTOTP() {
+		return exception.ErrTOTPNotEnrolled
+	}
+
+	if paswdOk := account.VerifyPassword(p.Password); !paswdOk {
+		return exception.ErrPasswordMismatch
+	}
+
+	if err := s.verifySecondFactor(&account, p.Code); err != nil {
+		return err
+	}
+
+	return s.repo.DisableTOTP(account.ID)
+}
+
+// LoginTOTP completes a two-step login: the challenge token returned by Login
+// is exchanged, together with a TOTP or recovery code, for a token pair. A
+// challenge token can only be exchanged once.
+func (s *AccountService) LoginTOTP(p *dto.AccountLoginTOTPReq, client dto.ClientInfo) (dto.AccountLoginResp, error) {
+	var resp dto.AccountLoginResp
+
+	claims, err := util.VerifyAuthChallengeToken(*s.cfg, p.ChallengeToken)
+	if err != nil {
+		return resp, err
+	}
+
+	username, _ := claims["sub"].(string)
+	tokenID, _ := claims["jti"].(string)
+	issuedAt, _ := claims["iat"].(float64)
+	expiredAt, _ := claims["exp"].(float64)
+	if tokenID == "" {
+		return resp, util.ErrChallengeTokenFailedToVerify
+	}
+
+	if err := s.throttle.Check(username, client.IPAddress); err != nil {
+		s.recordLogin(username, nil, domain.LoginThrottled, client)
+		return resp, err
+	}
+
+	account, err := s.repo.GetByUsername(username)
+	if err != nil {
+		return resp, err
+	}
+	if !account.HasTOTP() {
+		return resp, exception.ErrTOTPNotEnrolled
+	}
+	if account.IsLocked() {
+		s.recordLogin(username, &account, domain.LoginLocked, client)
+		return resp, exception.ErrUserLocked
+	}
+
+	revoked, err := s.revokedRepo.IsRevoked(account.ID, tokenID, "",
+		time.Unix(int64(issuedAt), 0).UTC())
+	if err != nil {
+		return resp, err
+	}
+	if revoked {
+		return resp, exception.ErrTokenRevoked
+	}
+
+	if err := s.verifySecondFactor(&account, p.Code); err != nil {
+		if errors.Is(err, exception.ErrTOTPInvalid) {
+			s.recordLogin(username, &account, domain.LoginBadTOTP, client)
+			if errFail := s.failLogin(&account, client); errFail != nil {
+				return resp, errFail
+			}
+		}
+		return resp, err
+	}
+
+	if err := s.throttle.Succeed(username); err != nil {
+		return resp, err
+	}
+
+	err = s.revokedRepo.RevokeToken(account.ID, tokenID,
+		time.Unix(int64(expiredAt), 0).UTC())
+	if err != nil {
+		return resp, err
+	}
+
+	return s.completeLogin(&account, client)
+}
+
+// verifySecondFactor accepts either a TOTP code or an unused recovery code.
+// Both are spent on success.
+func (s *AccountService) verifySecondFactor(account *dao.Account, code string) error {
+	if account.TOTPSecret == nil {
+		return exception.ErrTOTPNotEnrolled
+	}
+
+	if len(code) != recoveryCodeLength {
+		secret, err := util.DecryptAESGCM(*account.TOTPSecret, s.cfg.AuthN.PasswordEncryptionSecret)
+		if err != nil {
+			return err
+		}
+
+		step, ok := util.VerifyTOTP(secret, code, time.Now())
+		if !ok {
+			return exception.ErrTOTPInvalid
+		}
+
+		return s.repo.UseTOTPStep(account.ID, step)
+	}
+
+	items, err := s.repo.GetRecoveryCodes(account.ID)
+	if err != nil {
+		return err
+	}
+
+	code = strings.ToLower(code)
+	for _, item := range items {
+		if util.VerifyPasswordHash(item.CodeHash, code) {
+			return s.repo.UseRecoveryCode(item.ID)
+		}
+	}
+
+	return exception.ErrTOTPInvalid
+}
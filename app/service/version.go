@@ -0,0 +1,71 @@
+package service
+
+import (
+	"base-gin/app/domain"
+	"base-gin/app/domain/dao"
+	"base-gin/app/domain/dto"
+	"base-gin/app/repository"
+	"base-gin/exception"
+	"encoding/json"
+)
+
+const defaultVersionLimit = 20
+
+// VersionService keeps the version history of persons, publishers and books.
+// Their services save the row as it was before each update, and restore a
+// version by sending it through their usual update.
+type VersionService struct {
+	repo *repository.EntityVersionRepository
+}
+
+func newVersionService(repo *repository.EntityVersionRepository) *VersionService {
+	return &VersionService{repo: repo}
+}
+
+// newVersion prepares row, a record before an update by the account actorID,
+// to be saved as its next version. Repositories save it in the same
+// transaction as the update, which fails along with it.
+func (s *VersionService) newVersion(entity domain.TypeAuditEntity, entityID uint, row interface{}, actorID uint) (*dao.EntityVersion, error) {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return nil, err
+	}
+
+	item := dao.EntityVersion{Entity: entity, EntityID: entityID, Data: string(data)}
+	if actorID != 0 {
+		item.ActorID = &actorID
+	}
+
+	return &item, nil
+}
+
+// list returns the versions of a record, newest first.
+func (s *VersionService) list(entity domain.TypeAuditEntity, entityID uint, params *dto.Filter) ([]dao.EntityVersion, error) {
+	if params.Limit < 1 {
+		params.Limit = defaultVersionLimit
+	}
+
+	items, err := s.repo.GetList(entity, entityID, params)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) < 1 {
+		return nil, exception.ErrVersionNotFound
+	}
+
+	return items, nil
+}
+
+// get reads a version of a record into row.
+func (s *VersionService) get(entity domain.TypeAuditEntity, entityID, version uint, row interface{}) error {
+	item, err := s.repo.GetByVersion(entity, entityID, version)
+	if err != nil {
+		return err
+	}
+
+	return s.decode(item, row)
+}
+
+func (s *VersionService) decode(item *dao.EntityVersion, row interface{}) error {
+	return json.Unmarshal([]byte(item.Data), row)
+}
@@ -0,0 +1,44 @@
+// Command reencrypt rewrites the encrypted personal data of every person, and
+// of every version kept of a record, with the current PII key. Run it after
+// adding a new key to PII_KEYS, and before removing the old one, as values
+// still encrypted with a removed key can no longer be read. It also encrypts
+// values stored before encryption was added.
+package main
+
+import (
+	"base-gin/app/repository"
+	"base-gin/config"
+	"base-gin/storage"
+	"base-gin/util"
+	"flag"
+
+	"github.com/rs/zerolog/log"
+)
+
+func main() {
+	batchSize := flag.Int("batch", 100, "rows rewritten per batch")
+	flag.Parse()
+	if *batchSize < 1 {
+		log.Fatal().Int("batch", *batchSize).Msg("Batch size must be positive")
+	}
+
+	cfg := config.NewConfig()
+	if err := util.SetupPIICipher(&cfg); err != nil {
+		log.Fatal().Err(err).Msg("Failed to load PII keys")
+	}
+	storage.InitDB(cfg)
+	repository.SetupRepositories()
+
+	count, err := repository.GetPersonRepo().Reencrypt(*batchSize)
+	if err != nil {
+		log.Fatal().Err(err).Int64("count", count).Msg("Re-encryption of persons stopped")
+	}
+
+	versionCount, err := repository.GetEntityVersionRepo().Reencrypt(*batchSize)
+	if err != nil {
+		log.Fatal().Err(err).Int64("count", versionCount).Msg("Re-encryption of versions stopped")
+	}
+
+	log.Info().Int64("count", count).Int64("version_count", versionCount).
+		Msg("Re-encryption done")
+}
@@ -15,6 +15,9 @@ type AppConfig struct {
 	Name    string `env:"APP_NAME"`
 	Address string `env:"SERVER_ADDRESS"`
 	Mode    string `env:"GIN_MODE" envDefault:"release"`
+	// TrustedProxies may set the client IP through X-Forwarded-For, e.g. the
+	// load balancer. Without any, the IP of the connection is used.
+	TrustedProxies []string `env:"TRUSTED_PROXIES" envSeparator:"," envDefault:""`
 }
 
 type DBConfig struct {
@@ -25,18 +28,97 @@ type DBConfig struct {
 }
 
 type AuthNConfig struct {
-	LoginThrottleTTL         int    `env:"LOGIN_THROTTLE_TTL" envDefault:"300"` // in seconds
-	LoginMaxAttempt          int    `env:"LOGIN_MAX_ATTEMPT" envDefault:"10"`
-	JWTSecretKey             string `env:"JWT_SECRET"`
-	JWTAuthTTL               int    `env:"JWT_AUTH_TTL" envDefault:"3600"`
-	JWTRefreshTTL            int    `env:"JWT_REFRESH_TTL" envDefault:"2592000"`
-	PasswordEncryptionSecret string `env:"PWD_SECRET_32CHAR"`
+	// LoginBackends check passwords in the given order, the next one is asked
+	// when a backend does not know the user or can not be reached: local
+	// and/or ldap.
+	LoginBackends            []string `env:"LOGIN_BACKENDS" envSeparator:"," envDefault:"local"`
+	LoginThrottleTTL         int      `env:"LOGIN_THROTTLE_TTL" envDefault:"300"` // in seconds
+	LoginMaxAttempt          int      `env:"LOGIN_MAX_ATTEMPT" envDefault:"10"`
+	LoginMaxAttemptIP        int      `env:"LOGIN_MAX_ATTEMPT_IP" envDefault:"50"`
+	LoginThrottleStore       string   `env:"LOGIN_THROTTLE_STORE" envDefault:"memory"`     // memory or db
+	LoginLockoutThreshold    int      `env:"LOGIN_LOCKOUT_THRESHOLD" envDefault:"20"`      // 0 disables lockout
+	LoginHistoryRetention    int      `env:"LOGIN_HISTORY_RETENTION" envDefault:"90"`      // in days
+	JWTSecretKey             string   `env:"JWT_SECRET" envDefault:""`                     // HS256, used when JWT_KEY_FILES is empty
+	JWTAcceptHS256           bool     `env:"JWT_ACCEPT_HS256" envDefault:"false"`          // still accept HS256 tokens next to JWT_KEY_FILES while switching
+	JWTKeyFiles              []string `env:"JWT_KEY_FILES" envSeparator:"," envDefault:""` // RS256 or EdDSA PEM files, named <kid>.pem
+	JWTSigningKeyID          string   `env:"JWT_SIGNING_KID" envDefault:""`                // defaults to the first key file
+	JWTIssuer                string   `env:"JWT_ISSUER" envDefault:"plus.quranbest.com"`
+	JWTAudience              string   `env:"JWT_AUDIENCE" envDefault:""`
+	JWTAuthTTL               int      `env:"JWT_AUTH_TTL" envDefault:"3600"`
+	JWTRefreshTTL            int      `env:"JWT_REFRESH_TTL" envDefault:"2592000"`
+	PasswordEncryptionSecret string   `env:"PWD_SECRET_32CHAR"`
+	PasswordResetTTL         int      `env:"PWD_RESET_TTL" envDefault:"900"` // in seconds
+	PasswordResetMaxAttempt  int      `env:"PWD_RESET_MAX_ATTEMPT" envDefault:"5"`
+	EmailVerifyTTL           int      `env:"EMAIL_VERIFY_TTL" envDefault:"86400"` // in seconds
+	EmailVerifyMaxAttempt    int      `env:"EMAIL_VERIFY_MAX_ATTEMPT" envDefault:"5"`
+	PasswordMinLength        int      `env:"PWD_MIN_LENGTH" envDefault:"8"`
+	PasswordRequireUpper     bool     `env:"PWD_REQUIRE_UPPER" envDefault:"true"`
+	PasswordRequireLower     bool     `env:"PWD_REQUIRE_LOWER" envDefault:"true"`
+	PasswordRequireDigit     bool     `env:"PWD_REQUIRE_DIGIT" envDefault:"true"`
+	PasswordRequireSymbol    bool     `env:"PWD_REQUIRE_SYMBOL" envDefault:"false"`
+	PasswordDenyListFile     string   `env:"PWD_DENY_LIST_FILE" envDefault:""` // one password per line
+	PasswordHistorySize      int      `env:"PWD_HISTORY_SIZE" envDefault:"5"`  // 0 allows reuse
+	TOTPIssuer               string   `env:"TOTP_ISSUER" envDefault:"base-gin"`
+	TOTPChallengeTTL         int      `env:"TOTP_CHALLENGE_TTL" envDefault:"300"`                // in seconds
+	TOTPRequiredRoles        []string `env:"TOTP_REQUIRED_ROLES" envSeparator:"," envDefault:""` // e.g. librarian,admin, empty requires none
+	TOTPRecoveryCodes        int      `env:"TOTP_RECOVERY_CODES" envDefault:"10"`
+	APIKeyTTL                int      `env:"API_KEY_TTL" envDefault:"90"` // in days, when none is requested
+}
+
+// OIDCConfig enables login through an external OpenID Connect provider with
+// the authorization code flow.
+type OIDCConfig struct {
+	Enabled       bool     `env:"OIDC_ENABLED" envDefault:"false"`
+	IssuerURL     string   `env:"OIDC_ISSUER_URL" envDefault:""`
+	ClientID      string   `env:"OIDC_CLIENT_ID" envDefault:""`
+	ClientSecret  string   `env:"OIDC_CLIENT_SECRET" envDefault:""`
+	RedirectURL   string   `env:"OIDC_REDIRECT_URL" envDefault:""`
+	Scopes        []string `env:"OIDC_SCOPES" envSeparator:"," envDefault:"openid,email,profile"`
+	StateTTL      int      `env:"OIDC_STATE_TTL" envDefault:"600"` // in seconds
+	AutoProvision bool     `env:"OIDC_AUTO_PROVISION" envDefault:"true"`
+}
+
+// LDAPConfig lets users log in with their directory password. Accounts are
+// created on first login; with LDAP_GROUP_ROLES set, their role follows their
+// groups on every login.
+type LDAPConfig struct {
+	URL          string            `env:"LDAP_URL" envDefault:""` // ldap:// or ldaps://
+	StartTLS     bool              `env:"LDAP_START_TLS" envDefault:"false"`
+	BindDN       string            `env:"LDAP_BIND_DN" envDefault:""` // empty searches anonymously
+	BindPassword string            `env:"LDAP_BIND_PASSWORD" envDefault:""`
+	UserBaseDN   string            `env:"LDAP_USER_BASE_DN" envDefault:""`
+	UserFilter   string            `env:"LDAP_USER_FILTER" envDefault:"(uid=%s)"`
+	NameAttr     string            `env:"LDAP_NAME_ATTR" envDefault:"cn"`
+	Timeout      int               `env:"LDAP_TIMEOUT" envDefault:"10"`     // in seconds
+	GroupBaseDN  string            `env:"LDAP_GROUP_BASE_DN" envDefault:""` // empty skips group lookup
+	GroupFilter  string            `env:"LDAP_GROUP_FILTER" envDefault:"(member=%s)"`
+	GroupRoles   map[string]string `env:"LDAP_GROUP_ROLES" envSeparator:";" envDefault:""` // <group DN>:<role>;...
+}
+
+// PIIConfig holds the keys encrypting personal data at rest. Keys are
+// versioned so one can be rotated: values keep naming the version they were
+// encrypted with until they are re-encrypted with cmd/reencrypt.
+type PIIConfig struct {
+	Keys       []string `env:"PII_KEYS" envSeparator:"," envDefault:""` // <version>:<32-char key>, defaults to 1:PWD_SECRET_32CHAR
+	KeyVersion int      `env:"PII_KEY_VERSION" envDefault:"0"`          // encrypts new values, 0 uses the highest version
+	IndexKey   string   `env:"PII_INDEX_KEY" envDefault:""`             // blind index HMAC key, derived from PWD_SECRET_32CHAR when empty
+}
+
+type JobConfig struct {
+	TokenPurgeInterval        int `env:"JOB_TOKEN_PURGE_INTERVAL" envDefault:"3600"`          // in seconds
+	LoginHistoryPurgeInterval int `env:"JOB_LOGIN_HISTORY_PURGE_INTERVAL" envDefault:"86400"` // in seconds
+	TrashPurgeInterval        int `env:"JOB_TRASH_PURGE_INTERVAL" envDefault:"86400"`         // in seconds
+	TrashRetention            int `env:"JOB_TRASH_RETENTION" envDefault:"30"`                 // in days, deleted persons & publishers are kept this long
 }
 
 type Config struct {
 	App   AppConfig
 	DB    DBConfig
 	AuthN AuthNConfig
+	OIDC  OIDCConfig
+	LDAP  LDAPConfig
+	PII   PIIConfig
+	Job   JobConfig
 }
 
 func NewConfig() Config {
@@ -63,5 +145,31 @@ func NewConfig() Config {
 		log.Fatal().Err(fmt.Errorf("PWD_SECRET_32CHAR must be %d characters", 32)).Msg("config error")
 	}
 
+	if cfg.AuthN.JWTSecretKey == "" && len(cfg.AuthN.JWTKeyFiles) == 0 {
+		log.Fatal().Err(fmt.Errorf("JWT_SECRET or JWT_KEY_FILES must be set")).Msg("config error")
+	}
+
+	if cfg.OIDC.Enabled &&
+		(cfg.OIDC.IssuerURL == "" || cfg.OIDC.ClientID == "" || cfg.OIDC.RedirectURL == "") {
+		log.Fatal().Err(fmt.Errorf("OIDC_ISSUER_URL, OIDC_CLIENT_ID and OIDC_REDIRECT_URL must be set")).Msg("config error")
+	}
+
+	for _, backend := range cfg.AuthN.LoginBackends {
+		switch backend {
+		case "local":
+		case "ldap":
+			if cfg.LDAP.URL == "" || cfg.LDAP.UserBaseDN == "" {
+				log.Fatal().Err(fmt.Errorf("LDAP_URL and LDAP_USER_BASE_DN must be set")).Msg("config error")
+			}
+			for group, role := range cfg.LDAP.GroupRoles {
+				if role != "member" && role != "librarian" && role != "admin" {
+					log.Fatal().Err(fmt.Errorf("LDAP_GROUP_ROLES: unknown role %q for %s", role, group)).Msg("config error")
+				}
+			}
+		default:
+			log.Fatal().Err(fmt.Errorf("unknown login backend %q", backend)).Msg("config error")
+		}
+	}
+
 	return cfg
 }
@@ -2,19 +2,82 @@ package exception
 
 import (
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
 var (
+	ErrAPIKeyInvalid      = errors.New("API key tidak valid")
+	ErrAccountLastAdmin   = errors.New("harus ada setidaknya satu admin")
+	ErrAccountSelf        = errors.New("tidak dapat dilakukan pada akun sendiri")
+	ErrAPIKeyNotFound     = errors.New("API key tidak ditemukan")
+	ErrAPIKeyScope        = errors.New("API key tidak memiliki izin untuk permintaan ini")
+	ErrAuthorHasBooks     = errors.New("penulis masih memiliki buku")
+	ErrAuthorNotFound     = errors.New("penulis tidak ditemukan")
 	ErrBearerTokenInvalid = errors.New("format token bearer tidak sesuai")
+	ErrBookBorrowed       = errors.New("buku sedang dipinjam")
+	ErrBookNotFound       = errors.New("buku tidak ditemukan")
+	ErrBookReturned       = errors.New("buku sudah dikembalikan")
 	ErrDataNotFound       = errors.New("data tidak ditemukan")
 	ErrDateParsing        = errors.New("periksa input tanggal")
+	ErrEmailNotSet        = errors.New("email belum diisi")
+	ErrEmailVerified      = errors.New("email sudah terverifikasi")
+	ErrForbidden          = errors.New("akses ditolak")
+	ErrOIDCDisabled       = errors.New("login OIDC tidak aktif")
+	ErrOIDCLoginFailed    = errors.New("login OIDC gagal")
+	ErrOIDCStateInvalid   = errors.New("state OIDC tidak valid atau sudah kedaluwarsa")
+	ErrPasswordMismatch   = errors.New("password saat ini salah")
+	ErrPersonBorrowing    = errors.New("anggota masih meminjam buku")
+	ErrPersonForbidden    = errors.New("hanya dapat mengubah data diri sendiri")
+	ErrPersonHasAccount   = errors.New("anggota terhubung ke akun, gunakan anonimisasi akun")
+	ErrPersonNotFound     = errors.New("anggota tidak ditemukan")
+	ErrPublisherConflict  = errors.New("nama penerbit sudah terdaftar")
+	ErrPublisherHasBooks  = errors.New("penerbit masih memiliki buku")
+	ErrPublisherNotFound  = errors.New("penerbit tidak ditemukan")
+	ErrPublisherTrashed   = errors.New("nama penerbit dipakai penerbit yang terhapus, pulihkan dari tempat sampah")
+	ErrRequestThrottled   = errors.New("terlalu banyak percobaan, silakan coba lagi nanti")
+	ErrResetCodeInvalid   = errors.New("kode reset tidak valid atau sudah kedaluwarsa")
+	ErrSessionNotFound    = errors.New("sesi tidak ditemukan")
+	ErrTOTPEnabled        = errors.New("autentikasi dua faktor sudah aktif")
+	ErrTOTPInvalid        = errors.New("kode autentikasi dua faktor salah")
+	ErrTOTPNotEnrolled    = errors.New("autentikasi dua faktor belum didaftarkan")
+	ErrTOTPRequired       = errors.New("akun staf wajib mengaktifkan autentikasi dua faktor")
+	ErrTokenReused        = errors.New("token refresh sudah pernah digunakan")
+	ErrTokenRevoked       = errors.New("token tidak berlaku")
 	ErrUserConflict       = errors.New("akun pengguna sudah terdaftar")
+	ErrUserLocked         = errors.New("akun terkunci, silakan hubungi admin")
 	ErrUserNotFound       = errors.New("akun tidak ditemukan")
 	ErrUserLoginFailed    = errors.New("username/password salah")
+	ErrVerifyCodeInvalid  = errors.New("kode verifikasi tidak valid atau sudah kedaluwarsa")
+	ErrVersionNotFound    = errors.New("versi data tidak ditemukan")
 )
 
+// ThrottleError is returned while a caller is blocked by a rate limit.
+type ThrottleError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottleError) Error() string {
+	return ErrRequestThrottled.Error()
+}
+
+func (e *ThrottleError) Unwrap() error {
+	return ErrRequestThrottled
+}
+
+// ValidationError reports input rejected by a rule that can not be expressed
+// as a binding tag. Handlers render it like a binding error.
+type ValidationError struct {
+	Field    string
+	Messages []string
+}
+
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Messages, "; ")
+}
+
 func LogError(err error, message string) {
 	log.Error().Stack().Err(err).Msg(message)
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"base-gin/app/job"
 	"base-gin/app/repository"
 	"base-gin/app/rest"
 	"base-gin/app/service"
@@ -8,8 +9,11 @@ import (
 	_ "base-gin/docs"
 	"base-gin/server"
 	"base-gin/storage"
+	"base-gin/util"
+	"context"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -31,15 +35,29 @@ import (
 //	@name						Authorization
 //	@description				Bearer auth containing JWT
 
+//	@securityDefinitions.apiKey	APIKeyAuth
+//	@in							header
+//	@name						X-API-Key
+//	@description				API key created with POST /accounts/api-keys
+
 // @externalDocs.description  OpenAPI
 // @externalDocs.url          https://swagger.io/resources/open-api/
 func main() {
 	cfg := config.NewConfig()
+	if err := util.SetupTokenKeys(&cfg); err != nil {
+		log.Fatal().Err(err).Msg("Failed to load JWT keys")
+	}
+	if err := util.SetupPIICipher(&cfg); err != nil {
+		log.Fatal().Err(err).Msg("Failed to load PII keys")
+	}
 	storage.InitDB(cfg)
 	repository.SetupRepositories()
 	service.SetupServices(&cfg)
 
-	app := server.Init(&cfg, repository.GetAccountRepo())
+	job.SetupJobs(context.Background(), &cfg)
+
+	app := server.Init(&cfg, repository.GetAccountRepo(),
+		repository.GetRevokedTokenRepo(), repository.GetAPIKeyRepo())
 	rest.SetupRestHandlers(app)
 
 	// Swagger
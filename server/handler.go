@@ -1,6 +1,8 @@
 package server
 
 import (
+	"base-gin/app/domain"
+	"base-gin/app/domain/dao"
 	"base-gin/app/domain/dto"
 	"base-gin/app/repository"
 	"base-gin/config"
@@ -12,6 +14,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 
@@ -26,7 +29,7 @@ import (
 )
 
 var (
-	ErrRequestThrottled = errors.New("ratelimit")
+	ErrRequestThrottled = exception.ErrRequestThrottled
 )
 
 type BindingErrorMessage struct {
@@ -38,11 +41,15 @@ type Handler struct {
 	cfg         config.Config
 	idValidator ut.Translator
 	accountRepo *repository.AccountRepository
+	revokedRepo *repository.RevokedTokenRepository
+	apiKeyRepo  *repository.APIKeyRepository
 }
 
 func NewHandler(
 	cfg *config.Config,
 	accountRepo *repository.AccountRepository,
+	revokedRepo *repository.RevokedTokenRepository,
+	apiKeyRepo *repository.APIKeyRepository,
 ) *Handler {
 	var idValidator ut.Translator
 
@@ -59,6 +66,8 @@ func NewHandler(
 		cfg:         *cfg,
 		idValidator: idValidator,
 		accountRepo: accountRepo,
+		revokedRepo: revokedRepo,
+		apiKeyRepo:  apiKeyRepo,
 	}
 }
 
@@ -79,6 +88,23 @@ func (h *Handler) BindingError(err error) (int, dto.ErrorResponse) {
 			Errors:  messageBag,
 		}
 	}
+
+	var fe *exception.ValidationError
+	if errors.As(err, &fe) {
+		messageBag := make([]BindingErrorMessage, len(fe.Messages))
+		for i, msg := range fe.Messages {
+			messageBag[i] = BindingErrorMessage{
+				Field:   fe.Field,
+				Message: msg,
+			}
+		}
+		return http.StatusUnprocessableEntity, dto.ErrorResponse{
+			Success: false,
+			Message: "Validasi error",
+			Errors:  messageBag,
+		}
+	}
+
 	log.Error().Err(err).Msg("Handler.BindingError")
 	return http.StatusBadRequest, dto.ErrorResponse{
 		Success: false,
@@ -132,6 +158,17 @@ func (h *Handler) AuthAccess() gin.HandlerFunc {
 			return
 		}
 
+		tokenID, _ := token["jti"].(string)
+		family, _ := token["fam"].(string)
+		issuedAt, _ := token["iat"].(float64)
+		if tokenID == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, dto.ErrorResponse{
+				Success: false,
+				Message: util.ErrTokenInvalid.Error(),
+			})
+			return
+		}
+
 		account, err := h.accountRepo.GetByUsername(token["sub"].(string))
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, dto.ErrorResponse{
@@ -148,12 +185,142 @@ func (h *Handler) AuthAccess() gin.HandlerFunc {
 			return
 		}
 
+		revoked, err := h.revokedRepo.IsRevoked(
+			account.ID, tokenID, family, time.Unix(int64(issuedAt), 0).UTC())
+		if err != nil {
+			h.ErrorInternalServer(c, err)
+			c.Abort()
+			return
+		}
+		if revoked {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, dto.ErrorResponse{
+				Success: false,
+				Message: exception.ErrTokenRevoked.Error(),
+			})
+			return
+		}
+
 		c.Set(ParamTokenUserID, account.ID)
 		c.Set(ParamTokenUsername, account.Username)
+		c.Set(ParamTokenID, tokenID)
+		c.Set(ParamTokenFamily, family)
+		c.Set(ParamTokenRole, h.effectiveRole(c, &account))
 		c.Next()
 	}
 }
 
+// AuthAccessOrAPIKey accepts an API key in the X-API-Key header as an
+// alternative to a bearer token. It sets the same context values as
+// AuthAccess, except the token ID & family. GET and HEAD requests need the
+// key's read scope, every other method its write scope.
+func (h *Handler) AuthAccessOrAPIKey() gin.HandlerFunc {
+	authAccess := h.AuthAccess()
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderAPIKey)
+		if key == "" {
+			authAccess(c)
+			return
+		}
+
+		item, err := h.apiKeyRepo.GetByHash(util.TokenHash(key))
+		if err != nil && !errors.Is(err, exception.ErrDataNotFound) {
+			h.ErrorInternalServer(c, err)
+			c.Abort()
+			return
+		}
+		if err != nil || !item.IsActive(time.Now().UTC()) ||
+			item.Account == nil || item.Account.IsLocked() {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, dto.ErrorResponse{
+				Success: false,
+				Message: exception.ErrAPIKeyInvalid.Error(),
+			})
+			return
+		}
+
+		scope := domain.ScopeWrite
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			scope = domain.ScopeRead
+		}
+		if !item.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, dto.ErrorResponse{
+				Success: false,
+				Message: exception.ErrAPIKeyScope.Error(),
+			})
+			return
+		}
+
+		if err := h.apiKeyRepo.Touch(item.ID); err != nil {
+			log.Error().Err(err).Msg("Handler.AuthAccessOrAPIKey")
+		}
+
+		account := item.Account
+		c.Set(ParamTokenUserID, account.ID)
+		c.Set(ParamTokenUsername, account.Username)
+		c.Set(ParamTokenRole, h.effectiveRole(c, account))
+		c.Set(ParamAPIKeyID, item.ID)
+		c.Next()
+	}
+}
+
+// AuthOptional lets anonymous requests through and authenticates the others
+// like AuthAccessOrAPIKey, so a public route can show more to some callers.
+func (h *Handler) AuthOptional() gin.HandlerFunc {
+	auth := h.AuthAccessOrAPIKey()
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" && c.GetHeader(HeaderAPIKey) == "" {
+			c.Next()
+			return
+		}
+
+		auth(c)
+	}
+}
+
+// effectiveRole returns the role an account acts with. Roles listed in
+// TOTP_REQUIRED_ROLES fall back to member until two-factor authentication is
+// enabled, so staff can still login to enroll but can not reach staff-only
+// data with a password alone.
+func (h *Handler) effectiveRole(c *gin.Context, account *dao.Account) domain.TypeRole {
+	if account.HasTOTP() {
+		return account.Role
+	}
+
+	for _, role := range h.cfg.AuthN.TOTPRequiredRoles {
+		if domain.TypeRole(role) == account.Role {
+			c.Set(ParamTokenNoTOTP, true)
+			return domain.RoleMember
+		}
+	}
+
+	return account.Role
+}
+
+// RequireRole only lets accounts with one of the given roles through. It must
+// be chained after AuthAccess. The role is taken from the account record
+// loaded by AuthAccess rather than the token claim, so a role change applies
+// to tokens that were issued before it.
+func (h *Handler) RequireRole(roles ...domain.TypeRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get(ParamTokenRole)
+		for _, r := range roles {
+			if role == r {
+				c.Next()
+				return
+			}
+		}
+
+		err := exception.ErrForbidden
+		if c.GetBool(ParamTokenNoTOTP) {
+			err = exception.ErrTOTPRequired
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, dto.ErrorResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+}
+
 func (h *Handler) AuthRefresh() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token, err := h.verifyAuthRefreshToken(c.Request)
@@ -165,7 +332,19 @@ func (h *Handler) AuthRefresh() gin.HandlerFunc {
 			})
 			return
 		}
+		tokenID, _ := token["jti"].(string)
+		family, _ := token["fam"].(string)
+		if tokenID == "" || family == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, dto.ErrorResponse{
+				Success: false,
+				Message: util.ErrTokenInvalid.Error(),
+			})
+			return
+		}
+
 		c.Set(ParamTokenUsername, token["sub"])
+		c.Set(ParamTokenID, tokenID)
+		c.Set(ParamTokenFamily, family)
 		c.Next()
 	}
 }
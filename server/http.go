@@ -23,6 +23,13 @@ const (
 	ParamTokenUser     = "x-token-user"
 	ParamTokenUserID   = "x-token-user-id"
 	ParamTokenUsername = "x-token-uname"
+	ParamTokenID       = "x-token-id"
+	ParamTokenFamily   = "x-token-family"
+	ParamTokenRole     = "x-token-role"
+	ParamTokenNoTOTP   = "x-token-no-totp"
+	ParamAPIKeyID      = "x-api-key-id"
+
+	HeaderAPIKey = "X-API-Key"
 )
 
 var (
@@ -32,12 +39,17 @@ var (
 func Init(
 	cfg *config.Config,
 	accountRepo *repository.AccountRepository,
+	revokedRepo *repository.RevokedTokenRepository,
+	apiKeyRepo *repository.APIKeyRepository,
 ) *gin.Engine {
 	app := gin.New()
+	if err := app.SetTrustedProxies(cfg.App.TrustedProxies); err != nil {
+		log.Fatal().Err(err).Msg("Invalid TRUSTED_PROXIES")
+	}
 	app.Use(gin.Recovery())       // panic handling
 	registerCustomValidationTag() // returns json field name on errors
 
-	handler = NewHandler(cfg, accountRepo)
+	handler = NewHandler(cfg, accountRepo, revokedRepo, apiKeyRepo)
 
 	return app
 }
@@ -6,6 +6,44 @@ const (
 	RootAccount   = rootPath + "/accounts"
 	RootPerson    = rootPath + "/persons"
 	RootPublisher = rootPath + "/publishers"
+	RootBook      = rootPath + "/books"
+	RootAuthor    = rootPath + "/authors"
+	RootBorrowing = rootPath + "/borrowings"
+	RootAudit     = rootPath + "/audit"
 
-	PathLogin = "/login"
+	// RootJWKS sits outside rootPath where JWKS clients expect it.
+	RootJWKS = "/.well-known/jwks.json"
+
+	PathLogin        = "/login"
+	PathLoginTOTP    = "/login/2fa"
+	PathRegister     = "/register"
+	PathRefresh      = "/refresh"
+	PathLogout       = "/logout"
+	PathLogoutAll    = "/logout-all"
+	PathLogins       = "/logins"
+	PathExport       = "/export"
+	PathSessions     = "/sessions"
+	PathSession      = "/sessions/:id"
+	PathAPIKeys      = "/api-keys"
+	PathAPIKey       = "/api-keys/:id"
+	PathOIDCLogin    = "/oidc/login"
+	PathOIDCCallback = "/oidc/callback"
+	PathPassword     = "/password"
+	PathForgot       = "/password/forgot"
+	PathReset        = "/password/reset"
+	PathTOTPSetup    = "/2fa/setup"
+	PathTOTPEnable   = "/2fa/enable"
+	PathTOTPDisable  = "/2fa/disable"
+	PathBooks        = "/:id/books"
+	PathRole         = "/:id/role"
+	PathUnlock       = "/:id/unlock"
+	PathAnonymize    = "/:id/anonymize"
+	PathReturn       = "/:id/return"
+	PathContact      = "/:id/contact"
+	PathEmailCode    = "/:id/email/code"
+	PathEmailVerify  = "/:id/email/verify"
+	PathVersions     = "/:id/versions"
+	PathRestore      = "/:id/versions/:version/restore"
+	PathTrash        = "/trash"
+	PathUndelete     = "/:id/restore"
 )
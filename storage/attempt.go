@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"sync"
+	"time"
+)
+
+// AttemptStore counts attempts per key within a fixed window. Implementations
+// must be safe for concurrent use.
+type AttemptStore interface {
+	// Hit increments the counter of key, starting a new window of ttl when
+	// none is active. It returns the counter and the end of the window.
+	Hit(key string, ttl time.Duration) (int, time.Time, error)
+	// Get returns the counter of key and the end of its window. An expired or
+	// unknown key has a zero counter.
+	Get(key string) (int, time.Time, error)
+	Reset(key string) error
+}
+
+type attempt struct {
+	count     int
+	expiredAt time.Time
+}
+
+// MemoryAttemptStore keeps the counters in process memory. It is only
+// suitable for single instance deployments.
+type MemoryAttemptStore struct {
+	mu        sync.Mutex
+	items     map[string]attempt
+	lastSweep time.Time
+}
+
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{items: make(map[string]attempt)}
+}
+
+func (s *MemoryAttemptStore) Hit(key string, ttl time.Duration) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	s.sweep(now, ttl)
+
+	item, ok := s.items[key]
+	if !ok || !item.expiredAt.After(now) {
+		item = attempt{expiredAt: now.Add(ttl)}
+	}
+	item.count++
+	s.items[key] = item
+
+	return item.count, item.expiredAt, nil
+}
+
+func (s *MemoryAttemptStore) Get(key string) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[key]
+	if !ok || !item.expiredAt.After(time.Now().UTC()) {
+		return 0, time.Time{}, nil
+	}
+
+	return item.count, item.expiredAt, nil
+}
+
+func (s *MemoryAttemptStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.items, key)
+	return nil
+}
+
+// sweep drops expired counters at most once per ttl so the map does not grow
+// without bound.
+func (s *MemoryAttemptStore) sweep(now time.Time, ttl time.Duration) {
+	if now.Sub(s.lastSweep) < ttl {
+		return
+	}
+
+	for key, item := range s.items {
+		if !item.expiredAt.After(now) {
+			delete(s.items, key)
+		}
+	}
+	s.lastSweep = now
+}
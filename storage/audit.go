@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+var auditSchemas sync.Map
+
+// AuditChange is the change of one column. Old and New are the JSON encoded
+// values, both are left empty for a redacted column.
+type AuditChange struct {
+	Old      json.RawMessage `json:"old,omitempty" swaggertype:"object"`
+	New      json.RawMessage `json:"new,omitempty" swaggertype:"object"`
+	Redacted bool            `json:"redacted,omitempty"`
+}
+
+// AuditDiff compares two rows of the same model column by column, keyed by
+// column name. Before is nil for a created row and after for a deleted one.
+//
+// The primary key and timestamps are left out, as are fields
+// tagged `audit:"-"`. Encrypted fields (serializer:pii) and fields tagged
+// `audit:"redact"` only tell that they changed, so the audit log does not
+// become a plaintext copy of them.
+func AuditDiff(before, after interface{}) (map[string]AuditChange, error) {
+	model := before
+	if isNil(model) {
+		model = after
+	}
+	if isNil(model) {
+		return nil, nil
+	}
+
+	s, err := schema.Parse(model, &auditSchemas, schema.NamingStrategy{})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	changes := map[string]AuditChange{}
+	for _, dbName := range s.DBNames {
+		field := s.FieldsByDBName[dbName]
+		if field.PrimaryKey || field.AutoCreateTime > 0 ||
+			field.AutoUpdateTime > 0 || field.Name == "DeletedAt" ||
+			field.Tag.Get("audit") == "-" {
+			continue
+		}
+
+		oldValue, err := auditValue(ctx, field, before)
+		if err != nil {
+			return nil, err
+		}
+		newValue, err := auditValue(ctx, field, after)
+		if err != nil {
+			return nil, err
+		}
+		if string(oldValue) == string(newValue) {
+			continue
+		}
+
+		if field.TagSettings["SERIALIZER"] == "pii" || field.Tag.Get("audit") == "redact" {
+			changes[field.DBName] = AuditChange{Redacted: true}
+			continue
+		}
+		changes[field.DBName] = AuditChange{Old: oldValue, New: newValue}
+	}
+
+	return changes, nil
+}
+
+func auditValue(ctx context.Context, field *schema.Field, row interface{}) (json.RawMessage, error) {
+	if isNil(row) {
+		return json.RawMessage("null"), nil
+	}
+
+	// ValueOf would wrap fields with a serializer, so read the field itself.
+	value := field.ReflectValueOf(ctx, reflect.Indirect(reflect.ValueOf(row)))
+	return json.Marshal(value.Interface())
+}
+
+func isNil(row interface{}) bool {
+	if row == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(row)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"base-gin/util"
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// piiTimeLayouts are tried in order when reading a time. The last two read
+// DATE and DATETIME values stored before the column was encrypted.
+var piiTimeLayouts = []string{time.RFC3339Nano, "2006-01-02", "2006-01-02 15:04:05"}
+
+func init() {
+	schema.RegisterSerializer("pii", PIISerializer{})
+}
+
+// PIISerializer encrypts a column with the PII cipher, see util.PIICipher.
+// Fields tagged `serializer:pii` can be a string or a time.Time, or a pointer
+// to one; a nil pointer is stored as NULL.
+//
+// GORM only applies serializers to values read from a struct, so updates of
+// such fields must use Select(...).Updates(&item) rather than a map.
+type PIISerializer struct{}
+
+func (PIISerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	target := field.ReflectValueOf(ctx, dst)
+	if dbValue == nil {
+		target.Set(reflect.Zero(field.FieldType))
+		return nil
+	}
+
+	var raw string
+	switch v := dbValue.(type) {
+	case []byte:
+		raw = string(v)
+	case string:
+		raw = v
+	case time.Time:
+		raw = v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Errorf("PIISerializer: unsupported database value %T", dbValue)
+	}
+
+	plain, err := util.GetPIICipher().Decrypt(raw)
+	if err != nil {
+		return fmt.Errorf("PIISerializer: %s: %w", field.Name, err)
+	}
+
+	var value reflect.Value
+	switch field.FieldType {
+	case reflect.TypeOf(""), reflect.TypeOf((*string)(nil)):
+		value = reflect.ValueOf(plain)
+	case reflect.TypeOf(time.Time{}), reflect.TypeOf((*time.Time)(nil)):
+		t, err := parsePIITime(plain)
+		if err != nil {
+			return fmt.Errorf("PIISerializer: %s: %w", field.Name, err)
+		}
+		value = reflect.ValueOf(t)
+	default:
+		return fmt.Errorf("PIISerializer: unsupported field type %s", field.FieldType)
+	}
+
+	if field.FieldType.Kind() == reflect.Ptr {
+		ptr := reflect.New(value.Type())
+		ptr.Elem().Set(value)
+		value = ptr
+	}
+	target.Set(value)
+
+	return nil
+}
+
+func (PIISerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	var plain string
+	switch v := fieldValue.(type) {
+	case string:
+		plain = v
+	case *string:
+		if v == nil {
+			return nil, nil
+		}
+		plain = *v
+	case time.Time:
+		plain = v.Format(time.RFC3339Nano)
+	case *time.Time:
+		if v == nil {
+			return nil, nil
+		}
+		plain = v.Format(time.RFC3339Nano)
+	default:
+		return nil, fmt.Errorf("PIISerializer: unsupported field type %T", fieldValue)
+	}
+
+	return util.GetPIICipher().Encrypt(plain)
+}
+
+func parsePIITime(value string) (time.Time, error) {
+	var err error
+	for _, layout := range piiTimeLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, err
+}
@@ -1,10 +1,20 @@
 package integration_test
 
 import (
+	"base-gin/app/domain"
 	"base-gin/app/domain/dto"
+	"base-gin/app/repository"
+	"base-gin/exception"
 	"base-gin/server"
+	"base-gin/util"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -35,3 +45,591 @@ func TestAccount_GetProfile_ErrorAccessToken(t *testing.T) {
 	w = doTest("GET", server.RootAccount, nil, "accessToken")
 	assert.Equal(t, 401, w.Code)
 }
+
+func TestAccount_Register_Success(t *testing.T) {
+	req := dto.AccountRegisterReq{
+		AccountLoginReq: dto.AccountLoginReq{
+			Username: util.RandomStringAlpha(10),
+			Password: password,
+		},
+		Fullname:     util.RandomStringAlpha(5) + " " + util.RandomStringAlpha(6),
+		Gender:       "f",
+		BirthDateStr: "2001-02-03",
+	}
+
+	w := doTest("POST", server.RootAccount+server.PathRegister, req, "")
+	assert.Equal(t, 201, w.Code)
+	assert.Contains(t, w.Body.String(), req.Fullname)
+
+	w = doTest("POST", server.RootAccount+server.PathLogin, req.AccountLoginReq, "")
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestAccount_Register_ErrorConflict(t *testing.T) {
+	req := dto.AccountRegisterReq{
+		AccountLoginReq: dto.AccountLoginReq{
+			Username: dummyAdmin.Account.Username,
+			Password: password,
+		},
+		Fullname:     util.RandomStringAlpha(5) + " " + util.RandomStringAlpha(6),
+		Gender:       "m",
+		BirthDateStr: "2001-02-03",
+	}
+
+	w := doTest("POST", server.RootAccount+server.PathRegister, req, "")
+	assert.Equal(t, 409, w.Code)
+}
+
+func TestAccount_Register_ErrorValidation(t *testing.T) {
+	req := dto.AccountRegisterReq{
+		AccountLoginReq: dto.AccountLoginReq{
+			Username: util.RandomStringAlpha(20),
+			Password: "short",
+		},
+		Fullname:     util.RandomStringAlpha(5),
+		Gender:       "m",
+		BirthDateStr: "2001-02-03",
+	}
+
+	w := doTest("POST", server.RootAccount+server.PathRegister, req, "")
+	assert.Equal(t, 422, w.Code)
+}
+
+func TestAccount_Refresh_Rotation(t *testing.T) {
+	account := createDummyMemberAccount()
+	req := dto.AccountLoginReq{
+		Username: account.Username,
+		Password: password,
+	}
+
+	w := doTest("POST", server.RootAccount+server.PathLogin, req, "")
+	assert.Equal(t, 200, w.Code)
+
+	var login dto.SuccessResponse[dto.AccountLoginResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &login)
+
+	w = doTest("POST", server.RootAccount+server.PathRefresh, nil, login.Data.RefreshToken)
+	assert.Equal(t, 200, w.Code)
+
+	var refreshed dto.SuccessResponse[dto.AccountLoginResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &refreshed)
+	assert.NotEmpty(t, refreshed.Data.AccessToken)
+	assert.NotEqual(t, login.Data.RefreshToken, refreshed.Data.RefreshToken)
+
+	// reusing a consumed token revokes the whole family
+	w = doTest("POST", server.RootAccount+server.PathRefresh, nil, login.Data.RefreshToken)
+	assert.Equal(t, 401, w.Code)
+
+	w = doTest("POST", server.RootAccount+server.PathRefresh, nil, refreshed.Data.RefreshToken)
+	assert.Equal(t, 401, w.Code)
+}
+
+func TestAccount_Refresh_ErrorAccessToken(t *testing.T) {
+	accessToken := createAuthAccessToken(dummyAdmin.Account.Username)
+
+	w := doTest("POST", server.RootAccount+server.PathRefresh, nil, accessToken)
+	assert.Equal(t, 401, w.Code)
+}
+
+func TestAccount_Logout_Success(t *testing.T) {
+	accessToken := createAuthAccessToken(dummyAdmin.Account.Username)
+
+	w := doTest("POST", server.RootAccount+server.PathLogout, nil, accessToken)
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("GET", server.RootAccount, nil, accessToken)
+	assert.Equal(t, 401, w.Code)
+
+	w = doTest("GET", server.RootAccount, nil,
+		createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestAccount_LogoutAll_Success(t *testing.T) {
+	account := createDummyMemberAccount()
+
+	firstToken := createAuthAccessToken(account.Username)
+	secondToken := createAuthAccessToken(account.Username)
+
+	w := doTest("POST", server.RootAccount+server.PathLogoutAll, nil, firstToken)
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("GET", server.RootAccount, nil, firstToken)
+	assert.Equal(t, 401, w.Code)
+
+	w = doTest("GET", server.RootAccount, nil, secondToken)
+	assert.Equal(t, 401, w.Code)
+
+	// A login right after it, within the same second, is not revoked.
+	w = doTest("GET", server.RootAccount, nil, createAuthAccessToken(account.Username))
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestAccount_Login_ErrorThrottled(t *testing.T) {
+	req := dto.AccountLoginReq{
+		Username: util.RandomStringAlpha(12),
+		Password: password,
+	}
+
+	for i := 0; i < cfg.AuthN.LoginMaxAttempt; i++ {
+		w := doTest("POST", server.RootAccount+server.PathLogin, req, "")
+		assert.Equal(t, 400, w.Code)
+	}
+
+	w := doTest("POST", server.RootAccount+server.PathLogin, req, "")
+	assert.Equal(t, 429, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestAccount_UpdateRole_Success(t *testing.T) {
+	account := createDummyMemberAccount()
+	req := dto.AccountRoleUpdateReq{Role: string(domain.RoleLibrarian)}
+	url := fmt.Sprintf("%s/%d/role", server.RootAccount, account.ID)
+
+	w := doTest("PUT", url, req, createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 200, w.Code)
+
+	item, _ := accountRepo.GetByUsername(account.Username)
+	assert.Equal(t, domain.RoleLibrarian, item.Role)
+}
+
+func TestAccount_UpdateRole_ErrorForbidden(t *testing.T) {
+	account := createDummyMemberAccount()
+	req := dto.AccountRoleUpdateReq{Role: string(domain.RoleAdmin)}
+	url := fmt.Sprintf("%s/%d/role", server.RootAccount, account.ID)
+
+	w := doTest("PUT", url, req, createAuthAccessToken(account.Username))
+	assert.Equal(t, 403, w.Code)
+}
+
+func TestAccount_UpdateRole_ErrorLastAdmin(t *testing.T) {
+	req := dto.AccountRoleUpdateReq{Role: string(domain.RoleLibrarian)}
+	url := fmt.Sprintf("%s/%d/role", server.RootAccount, dummyAdmin.Account.ID)
+
+	w := doTest("PUT", url, req, createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 409, w.Code)
+
+	item, _ := accountRepo.GetByUsername(dummyAdmin.Account.Username)
+	assert.Equal(t, domain.RoleAdmin, item.Role)
+}
+
+func TestAccount_ChangePassword_Success(t *testing.T) {
+	account := createDummyMemberAccount()
+	accessToken := createAuthAccessToken(account.Username)
+	req := dto.AccountPasswordChangeReq{
+		Password:    password,
+		NewPassword: "NewPaswd123",
+	}
+
+	w := doTest("PUT", server.RootAccount+server.PathPassword, req, accessToken)
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("GET", server.RootAccount, nil, accessToken)
+	assert.Equal(t, 401, w.Code)
+
+	loginReq := dto.AccountLoginReq{Username: account.Username, Password: password}
+	w = doTest("POST", server.RootAccount+server.PathLogin, loginReq, "")
+	assert.Equal(t, 400, w.Code)
+
+	loginReq.Password = req.NewPassword
+	w = doTest("POST", server.RootAccount+server.PathLogin, loginReq, "")
+	assert.Equal(t, 200, w.Code)
+
+	var resp dto.SuccessResponse[dto.AccountLoginResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+
+	w = doTest("GET", server.RootAccount, nil, resp.Data.AccessToken)
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestAccount_ChangePassword_ErrorMismatch(t *testing.T) {
+	account := createDummyMemberAccount()
+	req := dto.AccountPasswordChangeReq{
+		Password:    "WrongPaswd123",
+		NewPassword: "NewPaswd123",
+	}
+
+	w := doTest("PUT", server.RootAccount+server.PathPassword, req,
+		createAuthAccessToken(account.Username))
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestAccount_ForgotPassword_Success(t *testing.T) {
+	account := createDummyMemberAccount()
+	req := dto.AccountPasswordForgotReq{Username: account.Username}
+
+	w := doTest("POST", server.RootAccount+server.PathForgot, req, "")
+	assert.Equal(t, 200, w.Code)
+
+	_, err := repository.GetPasswordResetRepo().GetActive(account.ID)
+	assert.Nil(t, err)
+
+	req.Username = util.RandomStringAlpha(12)
+	w = doTest("POST", server.RootAccount+server.PathForgot, req, "")
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestAccount_ForgotPassword_LoginNotThrottled(t *testing.T) {
+	account := createDummyMemberAccount()
+	req := dto.AccountPasswordForgotReq{Username: account.Username}
+
+	for i := 0; i < cfg.AuthN.LoginMaxAttempt; i++ {
+		w := doTest("POST", server.RootAccount+server.PathForgot, req, "")
+		assert.Equal(t, 200, w.Code)
+	}
+
+	w := doTest("POST", server.RootAccount+server.PathForgot, req, "")
+	assert.Equal(t, 429, w.Code)
+
+	// Reset requests are counted apart from failed logins.
+	loginReq := dto.AccountLoginReq{Username: account.Username, Password: password}
+	w = doTest("POST", server.RootAccount+server.PathLogin, loginReq, "")
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestAccount_ResetPassword_Success(t *testing.T) {
+	account := createDummyMemberAccount()
+	accessToken := createAuthAccessToken(account.Username)
+	code := createPasswordResetCode(account.ID)
+	req := dto.AccountPasswordResetReq{
+		Username:    account.Username,
+		Code:        code,
+		NewPassword: "NewPaswd123",
+	}
+
+	w := doTest("POST", server.RootAccount+server.PathReset, req, "")
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("POST", server.RootAccount+server.PathReset, req, "")
+	assert.Equal(t, 400, w.Code)
+
+	w = doTest("GET", server.RootAccount, nil, accessToken)
+	assert.Equal(t, 401, w.Code)
+
+	loginReq := dto.AccountLoginReq{Username: account.Username, Password: req.NewPassword}
+	w = doTest("POST", server.RootAccount+server.PathLogin, loginReq, "")
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestAccount_ResetPassword_ErrorReplacedCode(t *testing.T) {
+	account := createDummyMemberAccount()
+	oldCode := createPasswordResetCode(account.ID)
+	createPasswordResetCode(account.ID)
+	req := dto.AccountPasswordResetReq{
+		Username:    account.Username,
+		Code:        oldCode,
+		NewPassword: "NewPaswd123",
+	}
+
+	w := doTest("POST", server.RootAccount+server.PathReset, req, "")
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestAccount_ResetPassword_ErrorTooManyAttempts(t *testing.T) {
+	account := createDummyMemberAccount()
+	code := createPasswordResetCode(account.ID)
+	req := dto.AccountPasswordResetReq{
+		Username:    account.Username,
+		Code:        "000000",
+		NewPassword: "NewPaswd123",
+	}
+	if code == req.Code {
+		req.Code = "111111"
+	}
+
+	for i := 0; i < cfg.AuthN.PasswordResetMaxAttempt; i++ {
+		w := doTest("POST", server.RootAccount+server.PathReset, req, "")
+		assert.Equal(t, 400, w.Code)
+	}
+
+	req.Code = code
+	w := doTest("POST", server.RootAccount+server.PathReset, req, "")
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestAccount_ResetPassword_ErrorThrottled(t *testing.T) {
+	req := dto.AccountPasswordResetReq{
+		Username:    util.RandomStringAlpha(12),
+		Code:        "000000",
+		NewPassword: "NewPaswd123",
+	}
+
+	for i := 0; i < cfg.AuthN.LoginMaxAttempt; i++ {
+		w := doTest("POST", server.RootAccount+server.PathReset, req, "")
+		assert.Equal(t, 400, w.Code)
+	}
+
+	w := doTest("POST", server.RootAccount+server.PathReset, req, "")
+	assert.Equal(t, 429, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestAccount_Register_ErrorPasswordPolicy(t *testing.T) {
+	req := dto.AccountRegisterReq{
+		AccountLoginReq: dto.AccountLoginReq{
+			Username: util.RandomStringAlpha(10),
+			Password: "lowercase",
+		},
+		Fullname:     util.RandomStringAlpha(5) + " " + util.RandomStringAlpha(6),
+		Gender:       "f",
+		BirthDateStr: "2001-02-03",
+	}
+
+	w := doTest("POST", server.RootAccount+server.PathRegister, req, "")
+	assert.Equal(t, 422, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"paswd"`)
+}
+
+func TestAccount_ChangePassword_ErrorReused(t *testing.T) {
+	account := createDummyMemberAccount()
+	req := dto.AccountPasswordChangeReq{
+		Password:    password,
+		NewPassword: password,
+	}
+
+	w := doTest("PUT", server.RootAccount+server.PathPassword, req,
+		createAuthAccessToken(account.Username))
+	assert.Equal(t, 422, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"new_paswd"`)
+
+	req.NewPassword = "NewPaswd123"
+	w = doTest("PUT", server.RootAccount+server.PathPassword, req,
+		createAuthAccessToken(account.Username))
+	assert.Equal(t, 200, w.Code)
+
+	req = dto.AccountPasswordChangeReq{
+		Password:    "NewPaswd123",
+		NewPassword: password,
+	}
+	w = doTest("PUT", server.RootAccount+server.PathPassword, req,
+		createAuthAccessToken(account.Username))
+	assert.Equal(t, 422, w.Code)
+}
+
+func TestAccount_TOTP_Enrollment(t *testing.T) {
+	account := createDummyMemberAccount()
+	accessToken := createAuthAccessToken(account.Username)
+
+	w := doTest("POST", server.RootAccount+server.PathTOTPSetup, nil, accessToken)
+	assert.Equal(t, 200, w.Code)
+
+	var setup dto.SuccessResponse[dto.AccountTOTPSetupResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &setup)
+	assert.Contains(t, setup.Data.URI, "otpauth://totp/")
+
+	code, _ := util.TOTPCode(setup.Data.Secret, time.Now())
+	enableReq := dto.AccountTOTPEnableReq{Code: code}
+	w = doTest("POST", server.RootAccount+server.PathTOTPEnable, enableReq, accessToken)
+	assert.Equal(t, 200, w.Code)
+
+	var enabled dto.SuccessResponse[dto.AccountTOTPEnableResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &enabled)
+	assert.Len(t, enabled.Data.RecoveryCodes, cfg.AuthN.TOTPRecoveryCodes)
+
+	w = doTest("POST", server.RootAccount+server.PathTOTPSetup, nil, accessToken)
+	assert.Equal(t, 409, w.Code)
+}
+
+func TestAccount_LoginTOTP_Success(t *testing.T) {
+	account := createDummyMemberAccount()
+	secret := enableDummyTOTP(account)
+
+	challenge := loginChallenge(t, account.Username)
+
+	code, _ := util.TOTPCode(secret, time.Now())
+	req := dto.AccountLoginTOTPReq{ChallengeToken: challenge, Code: code}
+
+	w := doTest("POST", server.RootAccount+server.PathLoginTOTP, req, "")
+	assert.Equal(t, 200, w.Code)
+
+	var resp dto.SuccessResponse[dto.AccountLoginResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NotEmpty(t, resp.Data.AccessToken)
+
+	// neither the challenge token nor the code can be replayed
+	w = doTest("POST", server.RootAccount+server.PathLoginTOTP, req, "")
+	assert.Equal(t, 401, w.Code)
+
+	req.ChallengeToken = loginChallenge(t, account.Username)
+	w = doTest("POST", server.RootAccount+server.PathLoginTOTP, req, "")
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestAccount_LoginTOTP_RecoveryCode(t *testing.T) {
+	account := createDummyMemberAccount()
+	accessToken := createAuthAccessToken(account.Username)
+
+	w := doTest("POST", server.RootAccount+server.PathTOTPSetup, nil, accessToken)
+	var setup dto.SuccessResponse[dto.AccountTOTPSetupResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &setup)
+
+	code, _ := util.TOTPCode(setup.Data.Secret, time.Now())
+	w = doTest("POST", server.RootAccount+server.PathTOTPEnable,
+		dto.AccountTOTPEnableReq{Code: code}, accessToken)
+	var enabled dto.SuccessResponse[dto.AccountTOTPEnableResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &enabled)
+
+	req := dto.AccountLoginTOTPReq{
+		ChallengeToken: loginChallenge(t, account.Username),
+		Code:           enabled.Data.RecoveryCodes[0],
+	}
+	w = doTest("POST", server.RootAccount+server.PathLoginTOTP, req, "")
+	assert.Equal(t, 200, w.Code)
+
+	req.ChallengeToken = loginChallenge(t, account.Username)
+	w = doTest("POST", server.RootAccount+server.PathLoginTOTP, req, "")
+	assert.Equal(t, 400, w.Code)
+}
+
+// newTOTPRequiredApp returns an app with a single librarian-only route whose
+// handler requires TOTP from librarians.
+func newTOTPRequiredApp() *gin.Engine {
+	totpCfg := cfg
+	totpCfg.AuthN.TOTPRequiredRoles = []string{string(domain.RoleLibrarian)}
+	h := server.NewHandler(&totpCfg, accountRepo, repository.GetRevokedTokenRepo(),
+		repository.GetAPIKeyRepo())
+
+	totpApp := gin.New()
+	totpApp.GET("/staff", h.AuthAccess(), h.RequireRole(domain.RoleLibrarian),
+		func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	return totpApp
+}
+
+func TestAccount_TOTP_ErrorRequiredForStaff(t *testing.T) {
+	account := createDummyMemberAccount()
+	_ = accountRepo.UpdateRole(account.ID, domain.RoleLibrarian)
+	totpApp := newTOTPRequiredApp()
+
+	r, _ := http.NewRequest("GET", "/staff", nil)
+	r.Header.Set("Authorization", "Bearer "+createAuthAccessToken(account.Username))
+	w := httptest.NewRecorder()
+	totpApp.ServeHTTP(w, r)
+	assert.Equal(t, 403, w.Code)
+	assert.Contains(t, w.Body.String(), exception.ErrTOTPRequired.Error())
+
+	enableDummyTOTP(account)
+	w = httptest.NewRecorder()
+	totpApp.ServeHTTP(w, r)
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestAccount_TOTP_NotRequiredByDefault(t *testing.T) {
+	account := createDummyMemberAccount()
+	_ = accountRepo.UpdateRole(account.ID, domain.RoleLibrarian)
+
+	w := doTest("GET", server.RootBorrowing, nil, createAuthAccessToken(account.Username))
+	assert.Equal(t, 200, w.Code)
+}
+
+func loginChallenge(t *testing.T, username string) string {
+	req := dto.AccountLoginReq{Username: username, Password: password}
+
+	w := doTest("POST", server.RootAccount+server.PathLogin, req, "")
+	assert.Equal(t, 200, w.Code)
+
+	var resp dto.SuccessResponse[dto.AccountLoginResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Empty(t, resp.Data.AccessToken)
+	assert.NotEmpty(t, resp.Data.ChallengeToken)
+
+	return resp.Data.ChallengeToken
+}
+
+func TestAccount_GetLogins_Success(t *testing.T) {
+	account := createDummyMemberAccount()
+	req := dto.AccountLoginReq{Username: account.Username, Password: "WrongPaswd123"}
+
+	w := doTest("POST", server.RootAccount+server.PathLogin, req, "")
+	assert.Equal(t, 400, w.Code)
+
+	req.Password = password
+	w = doTest("POST", server.RootAccount+server.PathLogin, req, "")
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("GET", server.RootAccount+server.PathLogins, nil,
+		createAuthAccessToken(account.Username))
+	assert.Equal(t, 200, w.Code)
+
+	var resp dto.SuccessResponse[[]dto.AccountLoginHistoryResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Len(t, resp.Data, 2)
+	assert.True(t, resp.Data[0].Success)
+	assert.Equal(t, string(domain.LoginBadPassword), resp.Data[1].Result)
+}
+
+func TestAccount_Lockout_Unlock(t *testing.T) {
+	account := createDummyMemberAccount()
+	db.Model(account).Update("failed_logins", cfg.AuthN.LoginLockoutThreshold-1)
+
+	req := dto.AccountLoginReq{Username: account.Username, Password: "WrongPaswd123"}
+	w := doTest("POST", server.RootAccount+server.PathLogin, req, "")
+	assert.Equal(t, 400, w.Code)
+
+	req.Password = password
+	w = doTest("POST", server.RootAccount+server.PathLogin, req, "")
+	assert.Equal(t, 403, w.Code)
+
+	url := fmt.Sprintf("%s/%d/unlock", server.RootAccount, account.ID)
+	w = doTest("POST", url, nil, createAuthAccessToken(account.Username))
+	assert.Equal(t, 403, w.Code)
+
+	w = doTest("POST", url, nil, createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("POST", server.RootAccount+server.PathLogin, req, "")
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestAccount_Sessions_Revoke(t *testing.T) {
+	account := createDummyMemberAccount()
+	first := login(t, account.Username)
+	second := login(t, account.Username)
+
+	w := doTest("GET", server.RootAccount+server.PathSessions, nil, first.AccessToken)
+	assert.Equal(t, 200, w.Code)
+
+	var sessions dto.SuccessResponse[[]dto.AccountSessionResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &sessions)
+	assert.Len(t, sessions.Data, 2)
+
+	var other dto.AccountSessionResp
+	for _, item := range sessions.Data {
+		if !item.Current {
+			other = item
+		}
+	}
+	assert.NotZero(t, other.ID)
+
+	url := fmt.Sprintf("%s/sessions/%d", server.RootAccount, other.ID)
+	w = doTest("DELETE", url, nil, createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 404, w.Code)
+
+	w = doTest("DELETE", url, nil, first.AccessToken)
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("GET", server.RootAccount, nil, second.AccessToken)
+	assert.Equal(t, 401, w.Code)
+
+	w = doTest("POST", server.RootAccount+server.PathRefresh, nil, second.RefreshToken)
+	assert.Equal(t, 401, w.Code)
+
+	w = doTest("GET", server.RootAccount+server.PathSessions, nil, first.AccessToken)
+	assert.Equal(t, 200, w.Code)
+
+	_ = json.Unmarshal(w.Body.Bytes(), &sessions)
+	assert.Len(t, sessions.Data, 1)
+	assert.True(t, sessions.Data[0].Current)
+}
+
+func login(t *testing.T, username string) dto.AccountLoginResp {
+	req := dto.AccountLoginReq{Username: username, Password: password}
+
+	w := doTest("POST", server.RootAccount+server.PathLogin, req, "")
+	assert.Equal(t, 200, w.Code)
+
+	var resp dto.SuccessResponse[dto.AccountLoginResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+
+	return resp.Data
+}
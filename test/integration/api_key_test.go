@@ -0,0 +1,110 @@
+package integration_test
+
+import (
+	"base-gin/app/domain"
+	"base-gin/app/domain/dto"
+	"base-gin/exception"
+	"base-gin/server"
+	"base-gin/util"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createAPIKey(t *testing.T, accessToken string, scopes ...string) dto.AccountAPIKeyResp {
+	req := dto.AccountAPIKeyCreateReq{Name: "integration", Scopes: scopes}
+
+	w := doTest("POST", server.RootAccount+server.PathAPIKeys, req, accessToken)
+	assert.Equal(t, 201, w.Code)
+
+	var resp dto.SuccessResponse[dto.AccountAPIKeyResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+
+	return resp.Data
+}
+
+func TestAPIKey_Create_Success(t *testing.T) {
+	accessToken := createAuthAccessToken(dummyAdmin.Account.Username)
+	key := createAPIKey(t, accessToken, "read", "write")
+
+	assert.True(t, strings.HasPrefix(key.Key, key.Prefix))
+	assert.ElementsMatch(t, []domain.TypeScope{domain.ScopeRead, domain.ScopeWrite}, key.Scopes)
+
+	w := doTest("GET", server.RootAccount+server.PathAPIKeys, nil, accessToken)
+	assert.Equal(t, 200, w.Code)
+	assert.NotContains(t, w.Body.String(), key.Key)
+
+	var resp dto.SuccessResponse[[]dto.AccountAPIKeyResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Contains(t, w.Body.String(), key.Prefix)
+	for _, item := range resp.Data {
+		assert.Empty(t, item.Key)
+	}
+}
+
+func TestAPIKey_Access_Success(t *testing.T) {
+	key := createAPIKey(t, createAuthAccessToken(dummyAdmin.Account.Username), "read", "write")
+
+	req := dto.PublisherCreateReq{
+		Name: util.RandomStringAlpha(8),
+		City: util.RandomStringAlpha(10),
+	}
+	w := doTestAPIKey("POST", server.RootPublisher, req, key.Key)
+	assert.Equal(t, 201, w.Code)
+
+	// Account management still needs a bearer token.
+	w = doTestAPIKey("GET", server.RootAccount, nil, key.Key)
+	assert.Equal(t, 401, w.Code)
+}
+
+func TestAPIKey_Access_ErrorScope(t *testing.T) {
+	key := createAPIKey(t, createAuthAccessToken(dummyAdmin.Account.Username), "read")
+
+	req := dto.PublisherCreateReq{
+		Name: util.RandomStringAlpha(8),
+		City: util.RandomStringAlpha(10),
+	}
+	w := doTestAPIKey("POST", server.RootPublisher, req, key.Key)
+	assert.Equal(t, 403, w.Code)
+	assert.Contains(t, w.Body.String(), exception.ErrAPIKeyScope.Error())
+
+	borrowing := createDummyBorrowing(createDummyBook().ID, dummyMember.ID)
+	w = doTestAPIKey("GET", fmt.Sprintf("%s/%d", server.RootBorrowing, borrowing.ID), nil, key.Key)
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestAPIKey_Access_ErrorRole(t *testing.T) {
+	account := createDummyMemberAccount()
+	key := createAPIKey(t, createAuthAccessToken(account.Username), "read", "write")
+
+	req := dto.PublisherCreateReq{
+		Name: util.RandomStringAlpha(8),
+		City: util.RandomStringAlpha(10),
+	}
+	w := doTestAPIKey("POST", server.RootPublisher, req, key.Key)
+	assert.Equal(t, 403, w.Code)
+}
+
+func TestAPIKey_Revoke_Success(t *testing.T) {
+	accessToken := createAuthAccessToken(dummyAdmin.Account.Username)
+	key := createAPIKey(t, accessToken, "read")
+
+	url := fmt.Sprintf("%s/api-keys/%d", server.RootAccount, key.ID)
+	w := doTest("DELETE", url, nil, createAuthAccessToken(createDummyMemberAccount().Username))
+	assert.Equal(t, 404, w.Code)
+
+	w = doTest("DELETE", url, nil, accessToken)
+	assert.Equal(t, 200, w.Code)
+
+	w = doTestAPIKey("GET", server.RootBorrowing, nil, key.Key)
+	assert.Equal(t, 401, w.Code)
+	assert.Contains(t, w.Body.String(), exception.ErrAPIKeyInvalid.Error())
+}
+
+func TestAPIKey_Access_ErrorUnknownKey(t *testing.T) {
+	w := doTestAPIKey("GET", server.RootBorrowing, nil, "bgk_"+util.RandomString(40))
+	assert.Equal(t, 401, w.Code)
+}
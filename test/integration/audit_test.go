@@ -0,0 +1,88 @@
+package integration_test
+
+import (
+	"base-gin/app/domain"
+	"base-gin/app/domain/dto"
+	"base-gin/server"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createDummyLibrarianAccount() string {
+	account := createDummyMemberAccount()
+	_ = accountRepo.UpdateRole(account.ID, domain.RoleLibrarian)
+	return createAuthAccessToken(account.Username)
+}
+
+func TestAudit_GetList_PersonUpdate(t *testing.T) {
+	account := createDummyMemberAccount()
+	person, _ := personRepo.GetByAccountID(account.ID)
+	librarianToken := createDummyLibrarianAccount()
+
+	req := newPersonUpdateReq()
+	w := doTest("PUT", fmt.Sprintf("%s/%d", server.RootPerson, person.ID), req, librarianToken)
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("PUT", fmt.Sprintf("%s/%d/contact", server.RootPerson, person.ID),
+		newPersonContactUpdateReq(), createAuthAccessToken(account.Username))
+	assert.Equal(t, 200, w.Code)
+
+	url := fmt.Sprintf("%s?entity=person&entity_id=%d", server.RootAudit, person.ID)
+	w = doTest("GET", url, nil, createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 200, w.Code)
+
+	var resp dto.SuccessResponse[[]dto.AuditLogResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Len(t, resp.Data, 2)
+
+	// Newest first: the owner's contact change, then the librarian's edit.
+	contact := resp.Data[0]
+	assert.Equal(t, &dto.AuditActorResp{ID: int(account.ID), Username: account.Username},
+		contact.Actor)
+	assert.True(t, contact.Changes["email"].Redacted)
+	assert.Empty(t, contact.Changes["email"].New)
+	assert.NotContains(t, contact.Changes, "email_index")
+
+	update := resp.Data[1]
+	assert.Equal(t, string(domain.AuditUpdate), update.Action)
+	assert.NotNil(t, update.Actor)
+	assert.True(t, update.Changes["fullname"].Redacted)
+	assert.True(t, update.Changes["birth_date"].Redacted)
+	assert.NotEmpty(t, update.IPAddress)
+}
+
+func TestAudit_GetList_Librarian(t *testing.T) {
+	librarianToken := createDummyLibrarianAccount()
+
+	w := doTest("GET", server.RootAudit+"?entity=person", nil, librarianToken)
+	assert.Equal(t, 200, w.Code)
+
+	var resp dto.SuccessResponse[[]dto.AuditLogResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	for _, item := range resp.Data {
+		assert.Equal(t, string(domain.EntityPerson), item.Entity)
+	}
+
+	w = doTest("GET", server.RootAudit+"?entity=account", nil, librarianToken)
+	assert.Equal(t, 403, w.Code)
+}
+
+func TestAudit_GetList_Error(t *testing.T) {
+	w := doTest("GET", server.RootAudit, nil,
+		createAuthAccessToken(createDummyMemberAccount().Username))
+	assert.Equal(t, 403, w.Code)
+
+	adminToken := createAuthAccessToken(dummyAdmin.Account.Username)
+
+	w = doTest("GET", server.RootAudit+"?entity=loan", nil, adminToken)
+	assert.Equal(t, 422, w.Code)
+
+	w = doTest("GET", server.RootAudit+"?from=18-10-2026", nil, adminToken)
+	assert.Equal(t, 422, w.Code)
+
+	w = doTest("GET", server.RootAudit+"?entity_id=99999", nil, adminToken)
+	assert.Equal(t, 404, w.Code)
+}
@@ -0,0 +1,72 @@
+package integration_test
+
+import (
+	"base-gin/app/domain/dto"
+	"base-gin/server"
+	"base-gin/util"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthor_Create_Success(t *testing.T) {
+	req := dto.AuthorCreateReq{
+		Fullname:     util.RandomStringAlpha(5) + " " + util.RandomStringAlpha(6),
+		Gender:       "f",
+		BirthDateStr: "1965-07-31",
+	}
+
+	w := doTest("POST", server.RootAuthor, req,
+		createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 201, w.Code)
+	assert.Contains(t, w.Body.String(), req.Fullname)
+}
+
+func TestAuthor_GetList_Success(t *testing.T) {
+	url := fmt.Sprintf("%s?q=%s", server.RootAuthor, dummyAuthor.Fullname[:5])
+
+	w := doTest("GET", url, nil, "")
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), dummyAuthor.Fullname)
+}
+
+func TestAuthor_Update_Success(t *testing.T) {
+	author := createDummyAuthor()
+	req := dto.AuthorUpdateReq{
+		Fullname:     util.RandomStringAlpha(6) + " " + util.RandomStringAlpha(6),
+		Gender:       "m",
+		BirthDateStr: "1970-01-02",
+	}
+
+	w := doTest("PUT", fmt.Sprintf("%s/%d", server.RootAuthor, author.ID), req,
+		createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 200, w.Code)
+
+	item, _ := authorRepo.GetByID(author.ID)
+	assert.Equal(t, req.Fullname, item.Fullname)
+	assert.EqualValues(t, req.Gender, string(*item.Gender))
+	assert.Equal(t, req.BirthDateStr, item.BirthDate.Format("2006-01-02"))
+}
+
+func TestAuthor_Delete_Success(t *testing.T) {
+	author := createDummyAuthor()
+	url := fmt.Sprintf("%s/%d", server.RootAuthor, author.ID)
+
+	w := doTest("DELETE", url, nil, createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("GET", url, nil, "")
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestAuthor_Delete_ErrorHasBooks(t *testing.T) {
+	book := createDummyBook()
+	url := fmt.Sprintf("%s/%d", server.RootAuthor, book.AuthorID)
+
+	w := doTest("DELETE", url, nil, createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 409, w.Code)
+
+	w = doTest("GET", url, nil, "")
+	assert.Equal(t, 200, w.Code)
+}
@@ -0,0 +1,89 @@
+package integration_test
+
+import (
+	"base-gin/app/domain/dto"
+	"base-gin/app/repository"
+	"base-gin/server"
+	"base-gin/util"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBook_Create_Success(t *testing.T) {
+	req := dto.BookCreateReq{
+		Title:       util.RandomStringAlpha(6) + " " + util.RandomStringAlpha(8),
+		AuthorID:    dummyAuthor.ID,
+		PublisherID: dummyPublisher.ID,
+	}
+
+	w := doTest("POST", server.RootBook, req,
+		createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 201, w.Code)
+}
+
+func TestBook_Create_ErrorPublisherNotFound(t *testing.T) {
+	req := dto.BookCreateReq{
+		Title:       util.RandomStringAlpha(6),
+		AuthorID:    dummyAuthor.ID,
+		PublisherID: 9999,
+	}
+
+	w := doTest("POST", server.RootBook, req,
+		createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestBook_GetByID_Success(t *testing.T) {
+	book := createDummyBook()
+
+	w := doTest("GET", fmt.Sprintf("%s/%d", server.RootBook, book.ID), nil, "")
+	assert.Equal(t, 200, w.Code)
+
+	resp := w.Body.String()
+	assert.Contains(t, resp, book.Title)
+	assert.Contains(t, resp, dummyAuthor.Fullname)
+	assert.Contains(t, resp, dummyPublisher.Name)
+}
+
+func TestBook_Update_Success(t *testing.T) {
+	book := createDummyBook()
+	req := dto.BookUpdateReq{
+		Title:       util.RandomStringAlpha(10),
+		Subtitle:    util.RandomStringAlpha(12),
+		AuthorID:    dummyAuthor.ID,
+		PublisherID: dummyPublisher.ID,
+	}
+
+	w := doTest("PUT", fmt.Sprintf("%s/%d", server.RootBook, book.ID), req,
+		createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 200, w.Code)
+
+	item, _ := repository.GetBookRepo().GetByID(book.ID)
+	assert.Equal(t, req.Title, item.Title)
+	assert.Equal(t, req.Subtitle, *item.Subtitle)
+}
+
+func TestBook_Delete_Success(t *testing.T) {
+	book := createDummyBook()
+	url := fmt.Sprintf("%s/%d", server.RootBook, book.ID)
+
+	w := doTest("DELETE", url, nil, createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("GET", url, nil, "")
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestBook_Delete_ErrorBorrowed(t *testing.T) {
+	book := createDummyBook()
+	createDummyBorrowing(book.ID, dummyMember.ID)
+	url := fmt.Sprintf("%s/%d", server.RootBook, book.ID)
+
+	w := doTest("DELETE", url, nil, createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 409, w.Code)
+
+	w = doTest("GET", url, nil, "")
+	assert.Equal(t, 200, w.Code)
+}
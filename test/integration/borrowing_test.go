@@ -0,0 +1,57 @@
+package integration_test
+
+import (
+	"base-gin/app/domain/dto"
+	"base-gin/server"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBorrowing_Checkout_Success(t *testing.T) {
+	book := createDummyBook()
+	req := dto.BorrowingCheckoutReq{
+		BookID:   book.ID,
+		PersonID: dummyMember.ID,
+	}
+	accessToken := createAuthAccessToken(dummyAdmin.Account.Username)
+
+	w := doTest("POST", server.RootBorrowing, req, accessToken)
+	assert.Equal(t, 201, w.Code)
+
+	w = doTest("POST", server.RootBorrowing, req, accessToken)
+	assert.Equal(t, 409, w.Code)
+}
+
+func TestBorrowing_Checkout_ErrorPersonNotFound(t *testing.T) {
+	book := createDummyBook()
+	req := dto.BorrowingCheckoutReq{
+		BookID:   book.ID,
+		PersonID: 9999,
+	}
+
+	w := doTest("POST", server.RootBorrowing, req,
+		createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestBorrowing_Return_Success(t *testing.T) {
+	book := createDummyBook()
+	borrowing := createDummyBorrowing(book.ID, dummyMember.ID)
+	url := fmt.Sprintf("%s/%d/return", server.RootBorrowing, borrowing.ID)
+	accessToken := createAuthAccessToken(dummyAdmin.Account.Username)
+
+	w := doTest("POST", url, nil, accessToken)
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("POST", url, nil, accessToken)
+	assert.Equal(t, 409, w.Code)
+
+	req := dto.BorrowingCheckoutReq{
+		BookID:   book.ID,
+		PersonID: dummyMember.ID,
+	}
+	w = doTest("POST", server.RootBorrowing, req, accessToken)
+	assert.Equal(t, 201, w.Code)
+}
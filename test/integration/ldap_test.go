@@ -0,0 +1,332 @@
+package integration_test
+
+import (
+	"base-gin/app/domain"
+	"base-gin/app/domain/dto"
+	"base-gin/exception"
+	"base-gin/server"
+	"base-gin/util"
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	mockLDAPBindDN       = "cn=reader,dc=example,dc=com"
+	mockLDAPBindPassword = "reader-secret"
+	mockLDAPUserBaseDN   = "ou=people,dc=example,dc=com"
+	mockLDAPGroupBaseDN  = "ou=groups,dc=example,dc=com"
+	mockLDAPLibrarians   = "cn=librarians,ou=groups,dc=example,dc=com"
+)
+
+var directory *mockLDAP
+
+type mockLDAPUser struct {
+	UID      string
+	Password string
+	Name     string
+}
+
+func (u mockLDAPUser) dn() string {
+	return "uid=" + u.UID + "," + mockLDAPUserBaseDN
+}
+
+// mockLDAP is an in-process LDAP server answering the simple binds and the
+// equality searches done by util.LDAPDirectory.
+type mockLDAP struct {
+	listener net.Listener
+
+	mu     sync.Mutex
+	users  map[string]mockLDAPUser // by uid
+	groups map[string][]string     // member DNs by group DN
+	down   bool                    // drops every connection, like an outage
+}
+
+func newMockLDAP() *mockLDAP {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+
+	m := &mockLDAP{
+		listener: listener,
+		users:    map[string]mockLDAPUser{},
+		groups:   map[string][]string{},
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go m.serve(conn)
+		}
+	}()
+
+	return m
+}
+
+func (m *mockLDAP) url() string {
+	return "ldap://" + m.listener.Addr().String()
+}
+
+func (m *mockLDAP) addUser(user mockLDAPUser, groups ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.users[user.UID] = user
+	for _, group := range groups {
+		m.groups[group] = append(m.groups[group], user.dn())
+	}
+}
+
+func (m *mockLDAP) removeFromGroup(user mockLDAPUser, group string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var members []string
+	for _, member := range m.groups[group] {
+		if member != user.dn() {
+			members = append(members, member)
+		}
+	}
+	m.groups[group] = members
+}
+
+func (m *mockLDAP) setDown(down bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.down = down
+}
+
+func (m *mockLDAP) serve(conn net.Conn) {
+	defer conn.Close()
+
+	m.mu.Lock()
+	down := m.down
+	m.mu.Unlock()
+	if down {
+		return
+	}
+
+	var boundDN string
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+
+		id, _ := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn, _ := op.Children[1].Value.(string)
+			code := m.bind(dn, op.Children[2].Data.String())
+			if code == ldap.LDAPResultSuccess {
+				boundDN = dn
+			}
+			_, _ = conn.Write(mockLDAPResult(id, ldap.ApplicationBindResponse, code).Bytes())
+		case ldap.ApplicationSearchRequest:
+			// Only the reader account may search the directory.
+			if boundDN != mockLDAPBindDN {
+				_, _ = conn.Write(mockLDAPResult(id, ldap.ApplicationSearchResultDone,
+					ldap.LDAPResultInsufficientAccessRights).Bytes())
+				continue
+			}
+
+			baseDN, _ := op.Children[0].Value.(string)
+			filter, _ := ldap.DecompileFilter(op.Children[6])
+			for dn, attributes := range m.search(baseDN, filter) {
+				_, _ = conn.Write(mockLDAPEntry(id, dn, attributes).Bytes())
+			}
+			_, _ = conn.Write(mockLDAPResult(id, ldap.ApplicationSearchResultDone,
+				ldap.LDAPResultSuccess).Bytes())
+		case ldap.ApplicationUnbindRequest:
+			return
+		}
+	}
+}
+
+func (m *mockLDAP) bind(dn, paswd string) uint16 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if dn == mockLDAPBindDN && paswd == mockLDAPBindPassword {
+		return ldap.LDAPResultSuccess
+	}
+	for _, user := range m.users {
+		if user.dn() == dn && user.Password == paswd {
+			return ldap.LDAPResultSuccess
+		}
+	}
+
+	return ldap.LDAPResultInvalidCredentials
+}
+
+// search answers (uid=...) below the people and (member=...) below the groups.
+func (m *mockLDAP) search(baseDN, filter string) map[string]map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries := map[string]map[string]string{}
+	filter = strings.TrimSuffix(strings.TrimPrefix(filter, "("), ")")
+	attribute, value, _ := strings.Cut(filter, "=")
+
+	switch {
+	case baseDN == mockLDAPUserBaseDN && attribute == "uid":
+		if user, ok := m.users[value]; ok {
+			entries[user.dn()] = map[string]string{"cn": user.Name}
+		}
+	case baseDN == mockLDAPGroupBaseDN && attribute == "member":
+		for group, members := range m.groups {
+			for _, member := range members {
+				if member == value {
+					entries[group] = nil
+				}
+			}
+		}
+	}
+
+	return entries
+}
+
+func mockLDAPMessage(id int64, op *ber.Packet) *ber.Packet {
+	msg := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+	msg.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, ""))
+	msg.AppendChild(op)
+
+	return msg
+}
+
+func mockLDAPResult(id int64, tag ber.Tag, code uint16) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), ""))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+
+	return mockLDAPMessage(id, op)
+}
+
+func mockLDAPEntry(id int64, dn string, attributes map[string]string) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, ""))
+
+	list := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+	for name, value := range attributes {
+		attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, ""))
+		values := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+		values.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, ""))
+		attribute.AppendChild(values)
+		list.AppendChild(attribute)
+	}
+	op.AppendChild(list)
+
+	return mockLDAPMessage(id, op)
+}
+
+func newMockLDAPUser() mockLDAPUser {
+	uid := strings.ToLower(util.RandomStringAlpha(10))
+	return mockLDAPUser{
+		UID:      uid,
+		Password: "dir-" + util.RandomString(12),
+		Name:     "Rina " + uid,
+	}
+}
+
+func doLDAPLogin(username, paswd string) (int, dto.AccountLoginResp) {
+	req := dto.AccountLoginReq{Username: username, Password: paswd}
+	w := doTest("POST", server.RootAccount+server.PathLogin, req, "")
+
+	var resp dto.SuccessResponse[dto.AccountLoginResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+
+	return w.Code, resp.Data
+}
+
+func TestLDAP_Login_Provision(t *testing.T) {
+	user := newMockLDAPUser()
+	directory.addUser(user, mockLDAPLibrarians)
+
+	code, resp := doLDAPLogin(user.UID, user.Password)
+	assert.Equal(t, 200, code)
+	assert.NotEmpty(t, resp.AccessToken)
+
+	w := doTest("GET", server.RootAccount, nil, resp.AccessToken)
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), user.Name)
+
+	account, err := accountRepo.GetByUsername(user.UID)
+	assert.Nil(t, err)
+	assert.Equal(t, domain.RoleLibrarian, account.Role)
+
+	// The role follows the directory groups on the next login.
+	directory.removeFromGroup(user, mockLDAPLibrarians)
+
+	code, _ = doLDAPLogin(user.UID, user.Password)
+	assert.Equal(t, 200, code)
+
+	account, _ = accountRepo.GetByUsername(user.UID)
+	assert.Equal(t, domain.RoleMember, account.Role)
+}
+
+func TestLDAP_Login_ErrorPassword(t *testing.T) {
+	user := newMockLDAPUser()
+	directory.addUser(user)
+
+	code, _ := doLDAPLogin(user.UID, "wrong-"+user.Password)
+	assert.Equal(t, 400, code)
+
+	_, err := accountRepo.GetByUsername(user.UID)
+	assert.ErrorIs(t, err, exception.ErrUserNotFound)
+
+	code, _ = doLDAPLogin(user.UID, user.Password)
+	assert.Equal(t, 200, code)
+
+	// Once the account exists, failures count against its lockout.
+	code, _ = doLDAPLogin(user.UID, "wrong-"+user.Password)
+	assert.Equal(t, 400, code)
+
+	account, _ := accountRepo.GetByUsername(user.UID)
+	assert.Equal(t, 1, account.FailedLogins)
+}
+
+func TestLDAP_Login_UnlinkedLocalAccount(t *testing.T) {
+	local := createDummyMemberAccount()
+
+	// A directory user of the same name does not take over the local account.
+	user := newMockLDAPUser()
+	user.UID = local.Username
+	directory.addUser(user)
+
+	code, _ := doLDAPLogin(local.Username, user.Password)
+	assert.Equal(t, 400, code)
+
+	code, _ = doLDAPLogin(local.Username, password)
+	assert.Equal(t, 200, code)
+}
+
+func TestLDAP_Login_DirectoryDown(t *testing.T) {
+	local := createDummyMemberAccount()
+	user := newMockLDAPUser()
+	directory.addUser(user)
+
+	directory.setDown(true)
+	defer directory.setDown(false)
+
+	// Local accounts can still log in during a directory outage.
+	code, resp := doLDAPLogin(local.Username, password)
+	assert.Equal(t, 200, code)
+	assert.NotEmpty(t, resp.AccessToken)
+
+	code, _ = doLDAPLogin(user.UID, user.Password)
+	assert.Equal(t, 500, code)
+}
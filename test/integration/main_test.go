@@ -34,11 +34,14 @@ var (
 	db  *gorm.DB
 	app *gin.Engine
 
-	dummyAdmin  *dao.Person
-	dummyMember *dao.Person
+	dummyAdmin     *dao.Person
+	dummyMember    *dao.Person
+	dummyAuthor    *dao.Author
+	dummyPublisher *dao.Publisher
 
 	accountRepo *repository.AccountRepository
 	personRepo  *repository.PersonRepository
+	authorRepo  *repository.AuthorRepository
 )
 
 func TestMain(m *testing.M) {
@@ -55,6 +58,12 @@ func setup() {
 	}
 
 	cfg = config.NewConfig()
+	if err := util.SetupTokenKeys(&cfg); err != nil {
+		log.Fatal(err)
+	}
+	if err := util.SetupPIICipher(&cfg); err != nil {
+		log.Fatal(err)
+	}
 
 	storage.InitDB(cfg)
 	db = storage.GetDB()
@@ -64,15 +73,49 @@ func setup() {
 	repository.SetupRepositories()
 	accountRepo = repository.GetAccountRepo()
 	personRepo = repository.GetPersonRepo()
+	authorRepo = repository.GetAuthorRepo()
 
 	a := createDummyAccount()
 	dummyAdmin = createDummyProfile(a)
 	dummyMember = createDummyProfile(nil)
 	createDummyProfile(nil)
+	dummyAuthor = createDummyAuthor()
+	dummyPublisher = createDummyPublisher()
+
+	idp = newMockIdP()
+	cfg.OIDC = config.OIDCConfig{
+		Enabled:       true,
+		IssuerURL:     idp.server.URL,
+		ClientID:      mockIdPClientID,
+		ClientSecret:  mockIdPClientSecret,
+		RedirectURL:   mockIdPRedirectURL,
+		Scopes:        []string{"openid", "email", "profile"},
+		StateTTL:      600,
+		AutoProvision: true,
+	}
+
+	// TOTP enforcement is tested on its own handler, see newTOTPRequiredApp
+	cfg.AuthN.TOTPRequiredRoles = nil
+
+	directory = newMockLDAP()
+	cfg.AuthN.LoginBackends = []string{"ldap", "local"}
+	cfg.LDAP = config.LDAPConfig{
+		URL:          directory.url(),
+		BindDN:       mockLDAPBindDN,
+		BindPassword: mockLDAPBindPassword,
+		UserBaseDN:   mockLDAPUserBaseDN,
+		UserFilter:   "(uid=%s)",
+		NameAttr:     "cn",
+		Timeout:      5,
+		GroupBaseDN:  mockLDAPGroupBaseDN,
+		GroupFilter:  "(member=%s)",
+		GroupRoles:   map[string]string{mockLDAPLibrarians: string(domain.RoleLibrarian)},
+	}
 
 	service.SetupServices(&cfg)
 
-	app = server.Init(&cfg, accountRepo)
+	app = server.Init(&cfg, accountRepo, repository.GetRevokedTokenRepo(),
+		repository.GetAPIKeyRepo())
 	rest.SetupRestHandlers(app)
 }
 
@@ -81,6 +124,23 @@ func teardownDB() {
 		&dao.Account{},
 		&dao.Person{},
 		&dao.Publisher{},
+		&dao.Author{},
+		&dao.Book{},
+		&dao.Borrowing{},
+		&dao.RefreshToken{},
+		&dao.RevokedToken{},
+		&dao.LoginAttempt{},
+		&dao.PasswordReset{},
+		&dao.PasswordHistory{},
+		&dao.RecoveryCode{},
+		&dao.LoginHistory{},
+		&dao.Session{},
+		&dao.APIKey{},
+		&dao.ExternalIdentity{},
+		&dao.OIDCState{},
+		&dao.EmailVerification{},
+		&dao.AuditLog{},
+		&dao.EntityVersion{},
 	)
 }
 
@@ -89,15 +149,41 @@ func setupDB() {
 		&dao.Account{},
 		&dao.Person{},
 		&dao.Publisher{},
+		&dao.Author{},
+		&dao.Book{},
+		&dao.Borrowing{},
+		&dao.RefreshToken{},
+		&dao.RevokedToken{},
+		&dao.LoginAttempt{},
+		&dao.PasswordReset{},
+		&dao.PasswordHistory{},
+		&dao.RecoveryCode{},
+		&dao.LoginHistory{},
+		&dao.Session{},
+		&dao.APIKey{},
+		&dao.ExternalIdentity{},
+		&dao.OIDCState{},
+		&dao.EmailVerification{},
+		&dao.AuditLog{},
+		&dao.EntityVersion{},
 	)
 }
 
 func createDummyAccount() *dao.Account {
 	account, _ := dao.NewUser("admin", password, cfg.AuthN.PasswordEncryptionSecret)
+	account.Role = domain.RoleAdmin
 	accountRepo.Create(&account)
 	return &account
 }
 
+func createDummyMemberAccount() *dao.Account {
+	account, _ := dao.NewUser(util.RandomStringAlpha(12), password,
+		cfg.AuthN.PasswordEncryptionSecret)
+	accountRepo.Create(&account)
+	createDummyProfile(&account)
+	return &account
+}
+
 func createDummyProfile(account *dao.Account) *dao.Person {
 	birthDate, _ := time.Parse("2006-01-02", "1995-04-05")
 	male := domain.GenderMale
@@ -116,14 +202,127 @@ func createDummyProfile(account *dao.Account) *dao.Person {
 	return &person
 }
 
+func createDummyAuthor() *dao.Author {
+	female := domain.GenderFemale
+	author := dao.Author{
+		Fullname: util.RandomStringAlpha(5) + " " + util.RandomStringAlpha(6),
+		Gender:   &female,
+	}
+
+	authorRepo.Create(&author)
+
+	return &author
+}
+
+func createDummyPublisher() *dao.Publisher {
+	publisher := dao.Publisher{
+		Name: util.RandomStringAlpha(8),
+		City: util.RandomStringAlpha(10),
+	}
+
+	db.Create(&publisher)
+
+	return &publisher
+}
+
+func createDummyBook() *dao.Book {
+	book := dao.Book{
+		Title:       util.RandomStringAlpha(6) + " " + util.RandomStringAlpha(8),
+		AuthorID:    dummyAuthor.ID,
+		PublisherID: dummyPublisher.ID,
+	}
+
+	db.Create(&book)
+
+	return &book
+}
+
+func createDummyBorrowing(bookID, personID uint) *dao.Borrowing {
+	borrowing := dao.Borrowing{
+		BorrowDate: time.Now().UTC(),
+		BookID:     bookID,
+		PersonID:   personID,
+	}
+
+	db.Create(&borrowing)
+
+	return &borrowing
+}
+
+func createEmailVerificationCode(personID uint, email string) string {
+	code := util.RandomNumber(6)
+	codeHash, _ := util.PasswordHash(code)
+	_ = repository.GetEmailVerificationRepo().Replace(&dao.EmailVerification{
+		PersonID:   personID,
+		EmailIndex: util.PIIBlindIndex(email),
+		CodeHash:   codeHash,
+		ExpiredAt:  time.Now().UTC().Add(time.Minute),
+	})
+
+	return code
+}
+
+func createPasswordResetCode(accountID uint) string {
+	code := util.RandomNumber(6)
+	codeHash, _ := util.PasswordHash(code)
+	_ = repository.GetPasswordResetRepo().Replace(&dao.PasswordReset{
+		AccountID: accountID,
+		CodeHash:  codeHash,
+		ExpiredAt: time.Now().UTC().Add(time.Minute),
+	})
+
+	return code
+}
+
+// enableDummyTOTP turns on two-factor authentication and returns the plain
+// TOTP secret.
+func enableDummyTOTP(account *dao.Account) string {
+	secret, _ := util.NewTOTPSecret()
+	encrypted, _ := util.EncryptAESGCM(secret, cfg.AuthN.PasswordEncryptionSecret)
+	now := time.Now().UTC()
+
+	account.TOTPSecret = &encrypted
+	account.TOTPEnabledAt = &now
+	db.Save(account)
+
+	return secret
+}
+
+// createAuthAccessToken issues an access token with its own session, like a
+// login does.
 func createAuthAccessToken(username string) string {
-	token, err := util.CreateAuthAccessToken(cfg, username)
+	family := util.NewTokenID()
+	token, err := util.CreateAuthAccessToken(cfg, username,
+		util.NewTokenID(), family, "")
 	if err != nil {
 		log.Fatal(fmt.Errorf("main_test.createAuthAccessToken %w", err))
 	}
+
+	if account, err := accountRepo.GetByUsername(username); err == nil {
+		now := time.Now().UTC()
+		db.Create(&dao.Session{
+			AccountID:  account.ID,
+			Family:     family,
+			LastUsedAt: now,
+			ExpiredAt:  now.Add(time.Duration(cfg.AuthN.JWTRefreshTTL) * time.Second),
+		})
+	}
 	return token
 }
 
+// doTestAPIKey sends a request authenticated with an API key instead of a
+// bearer token.
+func doTestAPIKey(method, url string, body interface{}, apiKey string) *httptest.ResponseRecorder {
+	requestBody, _ := json.Marshal(body)
+	r, _ := http.NewRequest(method, url, bytes.NewBuffer(requestBody))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Accept", "application/json")
+	r.Header.Set(server.HeaderAPIKey, apiKey)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	return w
+}
+
 func doTest(
 	method, url string,
 	body interface{},
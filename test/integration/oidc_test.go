@@ -0,0 +1,254 @@
+package integration_test
+
+import (
+	"base-gin/app/domain/dto"
+	"base-gin/exception"
+	"base-gin/server"
+	"base-gin/util"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	mockIdPClientID     = "base-gin"
+	mockIdPClientSecret = "mock-secret"
+	mockIdPRedirectURL  = "http://localhost:3000/oidc/callback"
+	mockIdPKeyID        = "mock-1"
+)
+
+var idp *mockIdP
+
+type mockIdPUser struct {
+	Subject string
+	Email   string
+	Name    string
+}
+
+type mockIdPGrant struct {
+	user      mockIdPUser
+	nonce     string
+	challenge string
+}
+
+// mockIdP is an in-process OpenID Connect provider. A user "logs in" with
+// authorize, which returns the code & state the provider would redirect with.
+type mockIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]mockIdPGrant
+}
+
+func newMockIdP() *mockIdP {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	m := &mockIdP{key: key, grants: map[string]mockIdPGrant{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": mockIdPKeyID,
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", m.token)
+	m.server = httptest.NewServer(mux)
+
+	return m
+}
+
+// authorize logs user in at the provider for the given login page URL.
+func (m *mockIdP) authorize(authURL string, user mockIdPUser) (code, state string) {
+	u, _ := url.Parse(authURL)
+	query := u.Query()
+
+	code = util.RandomString(24)
+	m.mu.Lock()
+	m.grants[code] = mockIdPGrant{
+		user:      user,
+		nonce:     query.Get("nonce"),
+		challenge: query.Get("code_challenge"),
+	}
+	m.mu.Unlock()
+
+	return code, query.Get("state")
+}
+
+func (m *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	clientID, secret, ok := r.BasicAuth()
+	if !ok || clientID != mockIdPClientID || secret != mockIdPClientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	_ = r.ParseForm()
+	m.mu.Lock()
+	grant, ok := m.grants[r.PostForm.Get("code")]
+	delete(m.grants, r.PostForm.Get("code"))
+	m.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || r.PostForm.Get("redirect_uri") != mockIdPRedirectURL ||
+		base64.RawURLEncoding.EncodeToString(verifier[:]) != grant.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"access_token": util.RandomString(24),
+		"token_type":   "Bearer",
+		"id_token":     m.idToken(grant.user, grant.nonce),
+	})
+}
+
+func (m *mockIdP) idToken(user mockIdPUser, nonce string) string {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            m.server.URL,
+		"sub":            user.Subject,
+		"aud":            mockIdPClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Minute).Unix(),
+		"nonce":          nonce,
+		"email":          user.Email,
+		"email_verified": true,
+		"name":           user.Name,
+	})
+	token.Header["kid"] = mockIdPKeyID
+
+	signed, _ := token.SignedString(m.key)
+	return signed
+}
+
+// oidcAuthorize starts a login, lets the user sign in at the provider and
+// returns the callback request the client would send.
+func oidcAuthorize(t *testing.T, user mockIdPUser) dto.AccountOIDCCallbackReq {
+	w := doTest("GET", server.RootAccount+server.PathOIDCLogin, nil, "")
+	assert.Equal(t, 200, w.Code)
+
+	var resp dto.SuccessResponse[dto.AccountOIDCAuthResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NotEmpty(t, resp.Data.Binding)
+
+	code, state := idp.authorize(resp.Data.URL, user)
+
+	return dto.AccountOIDCCallbackReq{Code: code, State: state, Binding: resp.Data.Binding}
+}
+
+func newMockIdPUser() mockIdPUser {
+	local := util.RandomStringAlpha(8)
+	return mockIdPUser{
+		Subject: util.RandomString(20),
+		Email:   local + "@example.com",
+		Name:    "Siti " + local,
+	}
+}
+
+func TestOIDC_Login_Provision(t *testing.T) {
+	user := newMockIdPUser()
+
+	req := oidcAuthorize(t, user)
+	w := doTest("POST", server.RootAccount+server.PathOIDCCallback, req, "")
+	assert.Equal(t, 200, w.Code)
+
+	var resp dto.SuccessResponse[dto.AccountLoginResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NotEmpty(t, resp.Data.AccessToken)
+	assert.NotEmpty(t, resp.Data.RefreshToken)
+
+	w = doTest("GET", server.RootAccount, nil, resp.Data.AccessToken)
+	assert.Equal(t, 200, w.Code)
+
+	var profile dto.SuccessResponse[dto.AccountProfileResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &profile)
+	assert.Equal(t, user.Name, profile.Data.Fullname)
+
+	first, _ := util.VerifyAuthAccessToken(cfg, resp.Data.AccessToken)
+
+	// The second login maps `sub` to the same account.
+	req = oidcAuthorize(t, user)
+	w = doTest("POST", server.RootAccount+server.PathOIDCCallback, req, "")
+	assert.Equal(t, 200, w.Code)
+
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	second, _ := util.VerifyAuthAccessToken(cfg, resp.Data.AccessToken)
+	assert.Equal(t, first["sub"], second["sub"])
+}
+
+func TestOIDC_Login_ErrorStateReused(t *testing.T) {
+	user := newMockIdPUser()
+
+	req := oidcAuthorize(t, user)
+	w := doTest("POST", server.RootAccount+server.PathOIDCCallback, req, "")
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("POST", server.RootAccount+server.PathOIDCCallback, req, "")
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), exception.ErrOIDCStateInvalid.Error())
+}
+
+func TestOIDC_Login_ErrorNonce(t *testing.T) {
+	req := oidcAuthorize(t, newMockIdPUser())
+
+	// The provider signs a token meant for another login request.
+	idp.mu.Lock()
+	grant := idp.grants[req.Code]
+	grant.nonce = util.RandomString(32)
+	idp.grants[req.Code] = grant
+	idp.mu.Unlock()
+
+	w := doTest("POST", server.RootAccount+server.PathOIDCCallback, req, "")
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), exception.ErrOIDCLoginFailed.Error())
+}
+
+func TestOIDC_Login_ErrorCode(t *testing.T) {
+	req := oidcAuthorize(t, newMockIdPUser())
+
+	req.Code = util.RandomString(24)
+	w := doTest("POST", server.RootAccount+server.PathOIDCCallback, req, "")
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), exception.ErrOIDCLoginFailed.Error())
+}
+
+func TestOIDC_Login_ErrorBinding(t *testing.T) {
+	req := oidcAuthorize(t, newMockIdPUser())
+	binding := req.Binding
+
+	// A code & state passed to another browser lack the binding of the login.
+	req.Binding = util.RandomString(32)
+	w := doTest("POST", server.RootAccount+server.PathOIDCCallback, req, "")
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), exception.ErrOIDCStateInvalid.Error())
+
+	req.Binding = binding
+	w = doTest("POST", server.RootAccount+server.PathOIDCCallback, req, "")
+	assert.Equal(t, 200, w.Code)
+}
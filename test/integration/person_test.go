@@ -0,0 +1,197 @@
+package integration_test
+
+import (
+	"base-gin/app/domain/dto"
+	"base-gin/exception"
+	"base-gin/server"
+	"base-gin/util"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newPersonUpdateReq() dto.PersonUpdateReq {
+	return dto.PersonUpdateReq{
+		Fullname:     util.RandomStringAlpha(5) + " " + util.RandomStringAlpha(6),
+		Gender:       "f",
+		BirthDateStr: "1990-10-11",
+	}
+}
+
+func TestPerson_Update_SuccessOwner(t *testing.T) {
+	account := createDummyMemberAccount()
+	person, _ := personRepo.GetByAccountID(account.ID)
+	url := fmt.Sprintf("%s/%d", server.RootPerson, person.ID)
+
+	w := doTest("PUT", url, newPersonUpdateReq(), createAuthAccessToken(account.Username))
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestPerson_Update_ErrorNotOwner(t *testing.T) {
+	account := createDummyMemberAccount()
+	url := fmt.Sprintf("%s/%d", server.RootPerson, dummyMember.ID)
+
+	w := doTest("PUT", url, newPersonUpdateReq(), createAuthAccessToken(account.Username))
+	assert.Equal(t, 403, w.Code)
+}
+
+func TestPerson_Update_SuccessStaff(t *testing.T) {
+	url := fmt.Sprintf("%s/%d", server.RootPerson, dummyMember.ID)
+
+	w := doTest("PUT", url, newPersonUpdateReq(),
+		createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestPerson_Update_ErrorNotFound(t *testing.T) {
+	url := fmt.Sprintf("%s/%d", server.RootPerson, 99999)
+
+	w := doTest("PUT", url, newPersonUpdateReq(),
+		createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 404, w.Code)
+}
+
+func newPersonContactUpdateReq() dto.PersonContactUpdateReq {
+	return dto.PersonContactUpdateReq{
+		Email: strings.ToLower(util.RandomStringAlpha(8)) + "@example.com",
+		Phone: "6281234567890",
+	}
+}
+
+func getPersonDetail(t *testing.T, id uint, accessToken string) dto.PersonDetailResp {
+	w := doTest("GET", fmt.Sprintf("%s/%d", server.RootPerson, id), nil, accessToken)
+	assert.Equal(t, 200, w.Code)
+
+	var resp dto.SuccessResponse[dto.PersonDetailResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+
+	return resp.Data
+}
+
+func TestPerson_UpdateContact_Success(t *testing.T) {
+	account := createDummyMemberAccount()
+	person, _ := personRepo.GetByAccountID(account.ID)
+	url := fmt.Sprintf("%s/%d/contact", server.RootPerson, person.ID)
+	req := newPersonContactUpdateReq()
+
+	w := doTest("PUT", url, req, createAuthAccessToken(account.Username))
+	assert.Equal(t, 200, w.Code)
+
+	// Anonymous callers and other members see a masked email and no phone.
+	detail := getPersonDetail(t, person.ID, "")
+	assert.Equal(t, util.MaskEmailUsername(req.Email), detail.Email)
+	assert.Empty(t, detail.Phone)
+	assert.False(t, detail.EmailVerified)
+
+	other := createDummyMemberAccount()
+	detail = getPersonDetail(t, person.ID, createAuthAccessToken(other.Username))
+	assert.Equal(t, util.MaskEmailUsername(req.Email), detail.Email)
+
+	detail = getPersonDetail(t, person.ID, createAuthAccessToken(account.Username))
+	assert.Equal(t, req.Email, detail.Email)
+	assert.Equal(t, req.Phone, detail.Phone)
+
+	detail = getPersonDetail(t, person.ID, createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, req.Email, detail.Email)
+}
+
+func TestPerson_UpdateContact_ErrorPhone(t *testing.T) {
+	account := createDummyMemberAccount()
+	person, _ := personRepo.GetByAccountID(account.ID)
+	url := fmt.Sprintf("%s/%d/contact", server.RootPerson, person.ID)
+	req := newPersonContactUpdateReq()
+	req.Phone = "081234567890"
+
+	w := doTest("PUT", url, req, createAuthAccessToken(account.Username))
+	assert.Equal(t, 422, w.Code)
+}
+
+func TestPerson_UpdateContact_ErrorNotOwner(t *testing.T) {
+	account := createDummyMemberAccount()
+	url := fmt.Sprintf("%s/%d/contact", server.RootPerson, dummyMember.ID)
+
+	w := doTest("PUT", url, newPersonContactUpdateReq(), createAuthAccessToken(account.Username))
+	assert.Equal(t, 403, w.Code)
+}
+
+func TestPerson_VerifyEmail_Success(t *testing.T) {
+	account := createDummyMemberAccount()
+	accessToken := createAuthAccessToken(account.Username)
+	person, _ := personRepo.GetByAccountID(account.ID)
+	req := newPersonContactUpdateReq()
+
+	w := doTest("PUT", fmt.Sprintf("%s/%d/contact", server.RootPerson, person.ID),
+		req, accessToken)
+	assert.Equal(t, 200, w.Code)
+
+	url := fmt.Sprintf("%s/%d/email/verify", server.RootPerson, person.ID)
+	code := createEmailVerificationCode(person.ID, req.Email)
+
+	w = doTest("POST", url, dto.PersonEmailVerifyReq{Code: code}, accessToken)
+	assert.Equal(t, 200, w.Code)
+
+	assert.True(t, getPersonDetail(t, person.ID, accessToken).EmailVerified)
+
+	// The code is spent, and the email is verified already.
+	w = doTest("POST", url, dto.PersonEmailVerifyReq{Code: code}, accessToken)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), exception.ErrEmailVerified.Error())
+
+	// A new email has to be verified again.
+	req.Email = "new." + req.Email
+	w = doTest("PUT", fmt.Sprintf("%s/%d/contact", server.RootPerson, person.ID),
+		req, accessToken)
+	assert.Equal(t, 200, w.Code)
+	assert.False(t, getPersonDetail(t, person.ID, accessToken).EmailVerified)
+}
+
+func TestPerson_VerifyEmail_ErrorCode(t *testing.T) {
+	account := createDummyMemberAccount()
+	accessToken := createAuthAccessToken(account.Username)
+	person, _ := personRepo.GetByAccountID(account.ID)
+	req := newPersonContactUpdateReq()
+
+	w := doTest("PUT", fmt.Sprintf("%s/%d/contact", server.RootPerson, person.ID),
+		req, accessToken)
+	assert.Equal(t, 200, w.Code)
+
+	url := fmt.Sprintf("%s/%d/email/verify", server.RootPerson, person.ID)
+
+	// A code sent to an earlier email does not verify the current one.
+	code := createEmailVerificationCode(person.ID, "old."+req.Email)
+	w = doTest("POST", url, dto.PersonEmailVerifyReq{Code: code}, accessToken)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), exception.ErrVerifyCodeInvalid.Error())
+
+	createEmailVerificationCode(person.ID, req.Email)
+	w = doTest("POST", url, dto.PersonEmailVerifyReq{Code: "000000"}, accessToken)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), exception.ErrVerifyCodeInvalid.Error())
+}
+
+func TestPerson_GetList_ByEmail(t *testing.T) {
+	account := createDummyMemberAccount()
+	person, _ := personRepo.GetByAccountID(account.ID)
+	req := newPersonContactUpdateReq()
+
+	w := doTest("PUT", fmt.Sprintf("%s/%d/contact", server.RootPerson, person.ID),
+		req, createAuthAccessToken(account.Username))
+	assert.Equal(t, 200, w.Code)
+
+	url := server.RootPerson + "?email=" + strings.ToUpper(req.Email)
+	w = doTest("GET", url, nil, createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 200, w.Code)
+
+	var resp dto.SuccessResponse[[]dto.PersonDetailResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Len(t, resp.Data, 1)
+	assert.EqualValues(t, person.ID, resp.Data[0].ID)
+	assert.Equal(t, req.Email, resp.Data[0].Email)
+
+	// Members can not probe who owns an email.
+	w = doTest("GET", url, nil, createAuthAccessToken(account.Username))
+	assert.Equal(t, 403, w.Code)
+}
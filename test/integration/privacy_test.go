@@ -0,0 +1,158 @@
+package integration_test
+
+import (
+	"archive/zip"
+	"base-gin/app/domain"
+	"base-gin/app/domain/dao"
+	"base-gin/app/domain/dto"
+	"base-gin/exception"
+	"base-gin/server"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccount_Export_Zip(t *testing.T) {
+	account := createDummyMemberAccount()
+	person, _ := personRepo.GetByAccountID(account.ID)
+	borrowing := createDummyBorrowing(createDummyBook().ID, person.ID)
+	accessToken := login(t, account.Username).AccessToken
+
+	w := doTest("GET", server.RootAccount+server.PathExport, nil, accessToken)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	assert.Nil(t, err)
+
+	files := map[string][]byte{}
+	for _, file := range archive.File {
+		r, _ := file.Open()
+		files[file.Name], _ = io.ReadAll(r)
+		r.Close()
+	}
+	assert.Len(t, files, 4)
+
+	var exported dto.AccountExportAccount
+	_ = json.Unmarshal(files["account.json"], &exported)
+	assert.Equal(t, account.Username, exported.Username)
+	assert.NotContains(t, string(files["account.json"]), account.Password)
+
+	var exportedPerson dto.AccountExportPerson
+	_ = json.Unmarshal(files["person.json"], &exportedPerson)
+	assert.Equal(t, person.Fullname, exportedPerson.Fullname)
+	assert.Equal(t, "1995-04-05", exportedPerson.BirthDate)
+
+	var borrowings []dto.BorrowingDetailResp
+	_ = json.Unmarshal(files["borrowings.json"], &borrowings)
+	assert.Len(t, borrowings, 1)
+	assert.EqualValues(t, borrowing.ID, borrowings[0].ID)
+
+	var history []dto.AccountLoginHistoryResp
+	_ = json.Unmarshal(files["login_history.json"], &history)
+	assert.NotEmpty(t, history)
+}
+
+func TestAccount_Export_JSON(t *testing.T) {
+	account := createDummyMemberAccount()
+
+	w := doTest("GET", server.RootAccount+server.PathExport+"?format=json", nil,
+		createAuthAccessToken(account.Username))
+	assert.Equal(t, 200, w.Code)
+
+	var resp dto.SuccessResponse[dto.AccountExportResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, account.Username, resp.Data.Account.Username)
+	assert.NotNil(t, resp.Data.Person)
+	assert.Empty(t, resp.Data.Borrowings)
+
+	w = doTest("GET", server.RootAccount+server.PathExport+"?format=csv", nil,
+		createAuthAccessToken(account.Username))
+	assert.Equal(t, 422, w.Code)
+}
+
+func TestAccount_Anonymize_Success(t *testing.T) {
+	account := createDummyMemberAccount()
+	person, _ := personRepo.GetByAccountID(account.ID)
+	email := "rina." + account.Username + "@example.com"
+	_ = personRepo.UpdateContact(person.ID, &email, nil, true, nil)
+	borrowing := createDummyBorrowing(createDummyBook().ID, person.ID)
+	accessToken := login(t, account.Username).AccessToken
+
+	url := fmt.Sprintf("%s/%d/anonymize", server.RootAccount, account.ID)
+	w := doTest("POST", url, nil, createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 200, w.Code)
+
+	// The account can no longer be used, with a password or a token.
+	_, err := accountRepo.GetByUsername(account.Username)
+	assert.ErrorIs(t, err, exception.ErrUserNotFound)
+
+	w = doTest("GET", server.RootAccount, nil, accessToken)
+	assert.Equal(t, 401, w.Code)
+
+	scrubbed, _ := accountRepo.GetByID(account.ID)
+	assert.True(t, scrubbed.IsLocked())
+	assert.Equal(t, domain.RoleMember, scrubbed.Role)
+
+	item, _ := personRepo.GetByID(person.ID)
+	assert.NotEqual(t, person.Fullname, item.Fullname)
+	assert.Nil(t, item.Email)
+	assert.Nil(t, item.EmailIndex)
+	assert.Equal(t, 1995, item.BirthDate.Year())
+	assert.Equal(t, 1, item.BirthDate.YearDay())
+	assert.Equal(t, *person.Gender, *item.Gender)
+
+	// Borrowings still count for the person.
+	var count int64
+	db.Model(&dao.Borrowing{}).Where("id = ? AND person_id = ?", borrowing.ID, person.ID).Count(&count)
+	assert.EqualValues(t, 1, count)
+
+	db.Model(&dao.LoginHistory{}).Where("account_id = ?", account.ID).Count(&count)
+	assert.Zero(t, count)
+
+	var audit dao.AuditLog
+	db.Where("entity = ? AND entity_id = ?", domain.EntityAccount, account.ID).First(&audit)
+	assert.Equal(t, domain.AuditAnonymize, audit.Action)
+	assert.Equal(t, dummyAdmin.Account.ID, *audit.ActorID)
+}
+
+func TestAccount_Anonymize_DropsVersions(t *testing.T) {
+	account := createDummyMemberAccount()
+	person, _ := personRepo.GetByAccountID(account.ID)
+	adminToken := createAuthAccessToken(dummyAdmin.Account.Username)
+	personURL := fmt.Sprintf("%s/%d", server.RootPerson, person.ID)
+
+	w := doTest("PUT", personURL, newPersonUpdateReq(), createAuthAccessToken(account.Username))
+	assert.Equal(t, 200, w.Code)
+	w = doTest("GET", personURL+"/versions", nil, adminToken)
+	assert.Equal(t, 200, w.Code)
+
+	url := fmt.Sprintf("%s/%d/anonymize", server.RootAccount, account.ID)
+	w = doTest("POST", url, nil, adminToken)
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("GET", personURL+"/versions", nil, adminToken)
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestAccount_Anonymize_Error(t *testing.T) {
+	adminToken := createAuthAccessToken(dummyAdmin.Account.Username)
+
+	url := fmt.Sprintf("%s/%d/anonymize", server.RootAccount, dummyAdmin.Account.ID)
+	w := doTest("POST", url, nil, adminToken)
+	assert.Equal(t, 400, w.Code)
+
+	w = doTest("POST", fmt.Sprintf("%s/%d/anonymize", server.RootAccount, 99999), nil, adminToken)
+	assert.Equal(t, 404, w.Code)
+
+	account := createDummyMemberAccount()
+	other := createDummyMemberAccount()
+	url = fmt.Sprintf("%s/%d/anonymize", server.RootAccount, other.ID)
+	w = doTest("POST", url, nil, createAuthAccessToken(account.Username))
+	assert.Equal(t, 403, w.Code)
+}
@@ -2,7 +2,10 @@ package integration_test
 
 import (
 	"base-gin/app/domain/dto"
+	"base-gin/exception"
+	"base-gin/server"
 	"base-gin/util"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -17,4 +20,99 @@ func Test_Create_Success(t *testing.T) {
 	w := doTest("POST", "/v1/publishers", req,
 		createAuthAccessToken(dummyAdmin.Account.Username))
 	assert.Equal(t, 201, w.Code)
-}
\ No newline at end of file
+	assert.Contains(t, w.Body.String(), req.Name)
+}
+
+func TestPublisher_Create_ErrorConflict(t *testing.T) {
+	req := dto.PublisherCreateReq{
+		Name: dummyPublisher.Name,
+		City: util.RandomStringAlpha(10),
+	}
+
+	w := doTest("POST", server.RootPublisher, req,
+		createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 409, w.Code)
+}
+
+func TestPublisher_Create_ErrorTrashed(t *testing.T) {
+	publisher := createDummyPublisher()
+	adminToken := createAuthAccessToken(dummyAdmin.Account.Username)
+	w := doTest("DELETE", fmt.Sprintf("%s/%d", server.RootPublisher, publisher.ID), nil, adminToken)
+	assert.Equal(t, 200, w.Code)
+
+	req := dto.PublisherCreateReq{
+		Name: publisher.Name,
+		City: util.RandomStringAlpha(10),
+	}
+
+	w = doTest("POST", server.RootPublisher, req, adminToken)
+	assert.Equal(t, 409, w.Code)
+	assert.Contains(t, w.Body.String(), exception.ErrPublisherTrashed.Error())
+}
+
+func TestPublisher_Create_ErrorValidation(t *testing.T) {
+	req := dto.PublisherCreateReq{
+		Name: "abc",
+	}
+
+	w := doTest("POST", server.RootPublisher, req,
+		createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 422, w.Code)
+}
+
+func TestPublisher_Update_Success(t *testing.T) {
+	publisher := createDummyPublisher()
+	req := dto.PublisherUpdateReq{
+		Name: util.RandomStringAlpha(10),
+		City: util.RandomStringAlpha(8),
+	}
+	url := fmt.Sprintf("%s/%d", server.RootPublisher, publisher.ID)
+
+	w := doTest("PUT", url, req, createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("GET", url, nil, "")
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), req.Name)
+}
+
+func TestPublisher_GetBooks_Success(t *testing.T) {
+	book := createDummyBook()
+
+	w := doTest("GET", fmt.Sprintf("%s/%d/books", server.RootPublisher, dummyPublisher.ID), nil, "")
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), book.Title)
+}
+
+func TestPublisher_Delete_Success(t *testing.T) {
+	publisher := createDummyPublisher()
+	url := fmt.Sprintf("%s/%d", server.RootPublisher, publisher.ID)
+
+	w := doTest("DELETE", url, nil, createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("GET", url, nil, "")
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestPublisher_Delete_ErrorHasBooks(t *testing.T) {
+	book := createDummyBook()
+	url := fmt.Sprintf("%s/%d", server.RootPublisher, book.PublisherID)
+
+	w := doTest("DELETE", url, nil, createAuthAccessToken(dummyAdmin.Account.Username))
+	assert.Equal(t, 409, w.Code)
+
+	w = doTest("GET", url, nil, "")
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestPublisher_Create_ErrorForbidden(t *testing.T) {
+	account := createDummyMemberAccount()
+	req := dto.PublisherCreateReq{
+		Name: util.RandomStringAlpha(8),
+		City: util.RandomStringAlpha(10),
+	}
+
+	w := doTest("POST", server.RootPublisher, req, createAuthAccessToken(account.Username))
+	assert.Equal(t, 403, w.Code)
+}
@@ -0,0 +1,144 @@
+package integration_test
+
+import (
+	"base-gin/app/domain"
+	"base-gin/app/domain/dao"
+	"base-gin/app/domain/dto"
+	"base-gin/app/service"
+	"base-gin/server"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// moveToTrash soft-deletes row as if it was deleted days ago.
+func moveToTrash(row interface{}, days int) {
+	db.Delete(row)
+	db.Unscoped().Model(row).Update("deleted_at", time.Now().UTC().AddDate(0, 0, -days))
+}
+
+func TestPerson_Delete_Success(t *testing.T) {
+	person := createDummyProfile(nil)
+	adminToken := createAuthAccessToken(dummyAdmin.Account.Username)
+	url := fmt.Sprintf("%s/%d", server.RootPerson, person.ID)
+
+	w := doTest("DELETE", url, nil, adminToken)
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("GET", url, nil, adminToken)
+	assert.Equal(t, 404, w.Code)
+
+	w = doTest("GET", server.RootPerson+server.PathTrash+"?l=100", nil, adminToken)
+	assert.Equal(t, 200, w.Code)
+
+	var resp dto.SuccessResponse[[]dto.PersonTrashResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NotEmpty(t, resp.Data)
+	assert.Equal(t, int(person.ID), resp.Data[0].ID)
+	assert.False(t, resp.Data[0].DeletedAt.IsZero())
+
+	w = doTest("POST", url+"/restore", nil, adminToken)
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("GET", url, nil, adminToken)
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("POST", url+"/restore", nil, adminToken)
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestPerson_Delete_Error(t *testing.T) {
+	adminToken := createAuthAccessToken(dummyAdmin.Account.Username)
+
+	account := createDummyMemberAccount()
+	person, _ := personRepo.GetByAccountID(account.ID)
+	w := doTest("DELETE", fmt.Sprintf("%s/%d", server.RootPerson, person.ID), nil, adminToken)
+	assert.Equal(t, 409, w.Code)
+
+	borrower := createDummyProfile(nil)
+	createDummyBorrowing(createDummyBook().ID, borrower.ID)
+	w = doTest("DELETE", fmt.Sprintf("%s/%d", server.RootPerson, borrower.ID), nil, adminToken)
+	assert.Equal(t, 409, w.Code)
+
+	w = doTest("DELETE", fmt.Sprintf("%s/%d", server.RootPerson, 99999), nil, adminToken)
+	assert.Equal(t, 404, w.Code)
+
+	w = doTest("DELETE", fmt.Sprintf("%s/%d", server.RootPerson, createDummyProfile(nil).ID),
+		nil, createAuthAccessToken(account.Username))
+	assert.Equal(t, 403, w.Code)
+
+	w = doTest("GET", server.RootPerson+server.PathTrash, nil, createDummyLibrarianAccount())
+	assert.Equal(t, 403, w.Code)
+}
+
+func TestPublisher_Trash_Restore(t *testing.T) {
+	publisher := createDummyPublisher()
+	adminToken := createAuthAccessToken(dummyAdmin.Account.Username)
+	url := fmt.Sprintf("%s/%d", server.RootPublisher, publisher.ID)
+
+	w := doTest("DELETE", url, nil, adminToken)
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("GET", server.RootPublisher+server.PathTrash+"?q="+publisher.Name, nil, adminToken)
+	assert.Equal(t, 200, w.Code)
+
+	var resp dto.SuccessResponse[[]dto.PublisherTrashResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Len(t, resp.Data, 1)
+	assert.Equal(t, publisher.Name, resp.Data[0].Name)
+
+	w = doTest("POST", url+"/restore", nil, adminToken)
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("GET", url, nil, "")
+	assert.Equal(t, 200, w.Code)
+
+	var audit dao.AuditLog
+	db.Where("entity = ? AND entity_id = ?", domain.EntityPublisher, publisher.ID).
+		Order("id DESC").First(&audit)
+	assert.Equal(t, domain.AuditRestore, audit.Action)
+}
+
+func TestTrash_Purge(t *testing.T) {
+	cutoff := time.Now().UTC().AddDate(0, 0, -cfg.Job.TrashRetention)
+
+	expired := createDummyProfile(nil)
+	moveToTrash(expired, cfg.Job.TrashRetention+1)
+	recent := createDummyProfile(nil)
+	moveToTrash(recent, cfg.Job.TrashRetention-1)
+	borrower := createDummyProfile(nil)
+	createDummyBorrowing(createDummyBook().ID, borrower.ID)
+	moveToTrash(borrower, cfg.Job.TrashRetention+1)
+
+	count, err := service.GetPersonService().PurgeDeleted(cutoff)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, count)
+
+	var remaining int64
+	db.Unscoped().Model(&dao.Person{}).
+		Where("id IN ?", []uint{expired.ID, recent.ID, borrower.ID}).Count(&remaining)
+	assert.EqualValues(t, 2, remaining)
+
+	var audit dao.AuditLog
+	db.Where("entity = ? AND entity_id = ?", domain.EntityPerson, expired.ID).
+		Order("id DESC").First(&audit)
+	assert.Equal(t, domain.AuditPurge, audit.Action)
+	assert.Nil(t, audit.ActorID)
+
+	publisher := createDummyPublisher()
+	moveToTrash(publisher, cfg.Job.TrashRetention+1)
+	published := createDummyPublisher()
+	db.Create(&dao.Book{Title: "Buku", AuthorID: dummyAuthor.ID, PublisherID: published.ID})
+	moveToTrash(published, cfg.Job.TrashRetention+1)
+
+	count, err = service.GetPublisherServide().PurgeDeleted(cutoff)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, count)
+
+	db.Unscoped().Model(&dao.Publisher{}).
+		Where("id IN ?", []uint{publisher.ID, published.ID}).Count(&remaining)
+	assert.EqualValues(t, 1, remaining)
+}
@@ -0,0 +1,159 @@
+package integration_test
+
+import (
+	"base-gin/app/domain/dao"
+	"base-gin/app/domain/dto"
+	"base-gin/server"
+	"base-gin/util"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublisher_Version_Restore(t *testing.T) {
+	publisher := createDummyPublisher()
+	adminToken := createAuthAccessToken(dummyAdmin.Account.Username)
+	url := fmt.Sprintf("%s/%d", server.RootPublisher, publisher.ID)
+
+	req := dto.PublisherUpdateReq{Name: util.RandomStringAlpha(8), City: publisher.City}
+	w := doTest("PUT", url, req, adminToken)
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("GET", url+"/versions", nil, adminToken)
+	assert.Equal(t, 200, w.Code)
+
+	var resp dto.SuccessResponse[[]dto.PublisherVersionResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Len(t, resp.Data, 1)
+	assert.Equal(t, 1, resp.Data[0].Version)
+	assert.Equal(t, publisher.Name, resp.Data[0].Data.Name)
+	assert.Equal(t, int(dummyAdmin.Account.ID), resp.Data[0].ReplacedBy.ID)
+
+	w = doTest("POST", url+"/versions/1/restore", nil, adminToken)
+	assert.Equal(t, 200, w.Code)
+
+	var item dao.Publisher
+	db.First(&item, publisher.ID)
+	assert.Equal(t, publisher.Name, item.Name)
+
+	// The restore is an update too, so it can be undone in turn.
+	w = doTest("GET", url+"/versions", nil, adminToken)
+	resp = dto.SuccessResponse[[]dto.PublisherVersionResp]{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Len(t, resp.Data, 2)
+	assert.Equal(t, 2, resp.Data[0].Version)
+	assert.Equal(t, req.Name, resp.Data[0].Data.Name)
+}
+
+func TestPublisher_Version_ErrorConflict(t *testing.T) {
+	publisher := createDummyPublisher()
+	adminToken := createAuthAccessToken(dummyAdmin.Account.Username)
+	url := fmt.Sprintf("%s/%d", server.RootPublisher, publisher.ID)
+
+	req := dto.PublisherUpdateReq{Name: util.RandomStringAlpha(8), City: publisher.City}
+	w := doTest("PUT", url, req, adminToken)
+	assert.Equal(t, 200, w.Code)
+
+	// Another publisher took the old name in the meantime.
+	other := createDummyPublisher()
+	db.Model(other).Update("name", publisher.Name)
+
+	w = doTest("POST", url+"/versions/1/restore", nil, adminToken)
+	assert.Equal(t, 409, w.Code)
+}
+
+func TestPublisher_Version_Error(t *testing.T) {
+	publisher := createDummyPublisher()
+	adminToken := createAuthAccessToken(dummyAdmin.Account.Username)
+	url := fmt.Sprintf("%s/%d", server.RootPublisher, publisher.ID)
+
+	w := doTest("GET", url+"/versions", nil, adminToken)
+	assert.Equal(t, 404, w.Code)
+
+	w = doTest("POST", url+"/versions/1/restore", nil, adminToken)
+	assert.Equal(t, 404, w.Code)
+
+	w = doTest("POST", url+"/versions/x/restore", nil, adminToken)
+	assert.Equal(t, 400, w.Code)
+
+	w = doTest("GET", url+"/versions", nil,
+		createAuthAccessToken(createDummyMemberAccount().Username))
+	assert.Equal(t, 403, w.Code)
+}
+
+func TestBook_Version_Restore(t *testing.T) {
+	book := createDummyBook()
+	adminToken := createAuthAccessToken(dummyAdmin.Account.Username)
+	url := fmt.Sprintf("%s/%d", server.RootBook, book.ID)
+
+	req := dto.BookUpdateReq{
+		Title:       util.RandomStringAlpha(10),
+		Subtitle:    util.RandomStringAlpha(10),
+		AuthorID:    book.AuthorID,
+		PublisherID: createDummyPublisher().ID,
+	}
+	w := doTest("PUT", url, req, adminToken)
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("GET", url+"/versions", nil, adminToken)
+	assert.Equal(t, 200, w.Code)
+
+	var resp dto.SuccessResponse[[]dto.BookVersionResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Len(t, resp.Data, 1)
+	assert.Equal(t, book.Title, resp.Data[0].Data.Title)
+	assert.Equal(t, book.PublisherID, resp.Data[0].Data.PublisherID)
+
+	w = doTest("POST", url+"/versions/1/restore", nil, adminToken)
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("GET", url, nil, "")
+	var detail dto.SuccessResponse[dto.BookDetailResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &detail)
+	assert.Equal(t, book.Title, detail.Data.Title)
+	assert.Empty(t, detail.Data.Subtitle)
+	assert.EqualValues(t, book.PublisherID, detail.Data.Publisher.ID)
+}
+
+func TestPerson_Version_Restore(t *testing.T) {
+	account := createDummyMemberAccount()
+	person, _ := personRepo.GetByAccountID(account.ID)
+	accessToken := createAuthAccessToken(account.Username)
+	url := fmt.Sprintf("%s/%d", server.RootPerson, person.ID)
+
+	w := doTest("PUT", url, newPersonUpdateReq(), accessToken)
+	assert.Equal(t, 200, w.Code)
+	w = doTest("PUT", url+"/contact", newPersonContactUpdateReq(), accessToken)
+	assert.Equal(t, 200, w.Code)
+
+	w = doTest("GET", url+"/versions", nil, accessToken)
+	assert.Equal(t, 200, w.Code)
+
+	var resp dto.SuccessResponse[[]dto.PersonVersionResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Len(t, resp.Data, 2)
+	assert.Equal(t, person.Fullname, resp.Data[1].Data.Fullname)
+	assert.Equal(t, "1995-04-05", resp.Data[1].Data.BirthDateStr)
+	assert.Empty(t, resp.Data[1].Contact.Email)
+
+	w = doTest("GET", url+"/versions", nil,
+		createAuthAccessToken(createDummyMemberAccount().Username))
+	assert.Equal(t, 403, w.Code)
+
+	w = doTest("POST", url+"/versions/1/restore", nil, accessToken)
+	assert.Equal(t, 200, w.Code)
+
+	item, _ := personRepo.GetByID(person.ID)
+	assert.Equal(t, person.Fullname, item.Fullname)
+	assert.Equal(t, person.BirthDate.Format("2006-01-02"), item.BirthDate.Format("2006-01-02"))
+	assert.Nil(t, item.Email)
+
+	// The restore, detail and contact alike, is kept as one version.
+	w = doTest("GET", url+"/versions", nil, accessToken)
+	var after dto.SuccessResponse[[]dto.PersonVersionResp]
+	_ = json.Unmarshal(w.Body.Bytes(), &after)
+	assert.Len(t, after.Data, 3)
+	assert.NotEmpty(t, after.Data[0].Contact.Email)
+}
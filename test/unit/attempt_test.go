@@ -0,0 +1,41 @@
+package unit_test
+
+import (
+	"base-gin/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryAttemptStore_Hit(t *testing.T) {
+	store := storage.NewMemoryAttemptStore()
+
+	count, expiredAt, err := store.Hit("u:admin", time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	assert.True(t, expiredAt.After(time.Now()))
+
+	count, _, _ = store.Hit("u:admin", time.Minute)
+	assert.Equal(t, 2, count)
+
+	count, _, _ = store.Get("u:admin")
+	assert.Equal(t, 2, count)
+
+	_ = store.Reset("u:admin")
+	count, _, _ = store.Get("u:admin")
+	assert.Equal(t, 0, count)
+}
+
+func TestMemoryAttemptStore_Expired(t *testing.T) {
+	store := storage.NewMemoryAttemptStore()
+
+	_, _, _ = store.Hit("ip:127.0.0.1", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	count, _, _ := store.Get("ip:127.0.0.1")
+	assert.Equal(t, 0, count)
+
+	count, _, _ = store.Hit("ip:127.0.0.1", time.Minute)
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,40 @@
+package unit_test
+
+import (
+	"base-gin/app/domain"
+	"base-gin/app/domain/dao"
+	"base-gin/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudit_Diff_Update(t *testing.T) {
+	birthDate, _ := time.Parse("2006-01-02", "1995-04-05")
+	email, index := "rina@example.com", "abc"
+	before := dao.Person{Fullname: "Rina Wati", BirthDate: &birthDate}
+	before.ID = 1
+	after := before
+	after.Fullname = "Rina Wulandari"
+	after.Email, after.EmailIndex = &email, &index
+	after.UpdatedAt = time.Now()
+
+	changes, err := storage.AuditDiff(&before, &after)
+	assert.Nil(t, err)
+	assert.Len(t, changes, 2)
+	assert.Equal(t, storage.AuditChange{Redacted: true}, changes["fullname"])
+	assert.Equal(t, storage.AuditChange{Redacted: true}, changes["email"])
+}
+
+func TestAudit_Diff_Create(t *testing.T) {
+	account := dao.Account{Username: "rina", Password: "hash", Role: domain.RoleMember}
+
+	changes, err := storage.AuditDiff(nil, &account)
+	assert.Nil(t, err)
+	assert.Equal(t, "null", string(changes["role"].Old))
+	assert.Equal(t, `"member"`, string(changes["role"].New))
+	assert.True(t, changes["username"].Redacted)
+	assert.True(t, changes["password"].Redacted)
+	assert.NotContains(t, changes, "id")
+}
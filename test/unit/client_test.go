@@ -0,0 +1,34 @@
+package unit_test
+
+import (
+	"base-gin/server"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func clientIP(trustedProxies []string, forwardedFor string) string {
+	appCfg := cfg
+	appCfg.App.TrustedProxies = trustedProxies
+	app := server.Init(&appCfg, nil, nil, nil)
+	app.GET("/ip", func(c *gin.Context) {
+		c.String(200, server.GetHandler().ClientInfo(c).IPAddress)
+	})
+
+	req := httptest.NewRequest("GET", "/ip", nil) // from 192.0.2.1
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	return w.Body.String()
+}
+
+func TestClientInfo_IgnoresForwardedForByDefault(t *testing.T) {
+	assert.Equal(t, "192.0.2.1", clientIP(nil, "203.0.113.7"))
+}
+
+func TestClientInfo_TrustedProxy(t *testing.T) {
+	assert.Equal(t, "203.0.113.7", clientIP([]string{"192.0.2.1"}, "203.0.113.7"))
+}
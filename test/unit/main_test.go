@@ -47,6 +47,9 @@ func setup() {
 	}
 
 	cfg = config.NewConfig()
+	if err := util.SetupPIICipher(&cfg); err != nil {
+		log.Fatal(err)
+	}
 
 	storage.InitDB(cfg)
 	db = storage.GetDB()
@@ -65,6 +68,7 @@ func setup() {
 
 func teardownDB() {
 	_ = db.Migrator().DropTable(
+		&dao.EntityVersion{},
 		&dao.Account{},
 		&dao.Person{},
 	)
@@ -74,6 +78,7 @@ func setupDB() {
 	_ = db.AutoMigrate(
 		&dao.Account{},
 		&dao.Person{},
+		&dao.EntityVersion{},
 	)
 }
 
@@ -0,0 +1,49 @@
+package unit_test
+
+import (
+	"base-gin/config"
+	"base-gin/util"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	policy, err := util.NewPasswordPolicy(config.AuthNConfig{
+		PasswordMinLength:     10,
+		PasswordRequireUpper:  true,
+		PasswordRequireLower:  true,
+		PasswordRequireDigit:  true,
+		PasswordRequireSymbol: true,
+	})
+	assert.Nil(t, err)
+
+	assert.Empty(t, policy.Check("Password", "Paswd123!word"))
+	assert.Len(t, policy.Check("Password", "short"), 4)
+	assert.Len(t, policy.Check("Password", "alllowercase"), 3)
+	assert.Contains(t, policy.Check("Password", "NOLOWERCASE1!")[0], "huruf kecil")
+}
+
+func TestPasswordPolicy_DenyList(t *testing.T) {
+	denyFile := filepath.Join(t.TempDir(), "deny.txt")
+	_ = os.WriteFile(denyFile, []byte("# common passwords\nPassword123\n\nqwerty\n"), 0o600)
+
+	policy, err := util.NewPasswordPolicy(config.AuthNConfig{
+		PasswordMinLength:    6,
+		PasswordDenyListFile: denyFile,
+	})
+	assert.Nil(t, err)
+
+	assert.Len(t, policy.Check("Password", "password123"), 1)
+	assert.Len(t, policy.Check("Password", "QWERTY"), 1)
+	assert.Empty(t, policy.Check("Password", "Paswd123"))
+}
+
+func TestPasswordPolicy_ErrorDenyListFile(t *testing.T) {
+	_, err := util.NewPasswordPolicy(config.AuthNConfig{
+		PasswordDenyListFile: filepath.Join(t.TempDir(), "missing.txt"),
+	})
+	assert.NotNil(t, err)
+}
@@ -2,8 +2,13 @@ package unit_test
 
 import (
 	"base-gin/app/domain"
+	"base-gin/app/domain/dao"
 	"base-gin/app/domain/dto"
+	"base-gin/app/repository"
+	"base-gin/config"
+	"base-gin/exception"
 	"base-gin/util"
+	"strings"
 	"testing"
 	"time"
 
@@ -21,7 +26,7 @@ func TestPerson_Update_Success(t *testing.T) {
 		BirthDate:    birthDate,
 	}
 
-	err := personRepo.Update(&params)
+	err := personRepo.Update(&params, nil)
 	assert.Nil(t, err)
 
 	item, _ := personRepo.GetByID(dummyMember.ID)
@@ -29,3 +34,106 @@ func TestPerson_Update_Success(t *testing.T) {
 	assert.EqualValues(t, params.Gender, string(*item.Gender))
 	assert.EqualValues(t, params.BirthDateStr, item.BirthDate.Format("2006-01-02"))
 }
+
+func TestPerson_Update_ErrorNotFound(t *testing.T) {
+	birthDate, _ := time.Parse("2006-01-02", "1993-09-13")
+	params := dto.PersonUpdateReq{
+		ID:           99999,
+		Fullname:     util.RandomStringAlpha(6),
+		Gender:       string(domain.GenderMale),
+		BirthDateStr: birthDate.Format("2006-01-02"),
+		BirthDate:    birthDate,
+	}
+	version := dao.EntityVersion{Entity: domain.EntityPerson, EntityID: params.ID, Data: "{}"}
+
+	err := personRepo.Update(&params, &version)
+	assert.ErrorIs(t, err, exception.ErrUserNotFound)
+
+	// The version is only saved along with the update.
+	items, _ := repository.GetEntityVersionRepo().GetList(domain.EntityPerson, params.ID, &dto.Filter{})
+	assert.Empty(t, items)
+}
+
+func TestPerson_UpdateContact_Encrypted(t *testing.T) {
+	email := strings.ToLower(util.RandomStringAlpha(8)) + "@example.com"
+	phone := "0812" + util.RandomNumber(8)
+
+	err := personRepo.UpdateContact(dummyMember.ID, &email, &phone, true, nil)
+	assert.Nil(t, err)
+
+	var raw struct{ Email, Phone, BirthDate string }
+	db.Raw("SELECT email, phone, birth_date FROM persons WHERE id = ?", dummyMember.ID).Scan(&raw)
+	assert.True(t, strings.HasPrefix(raw.Email, "v1:"))
+	assert.True(t, strings.HasPrefix(raw.Phone, "v1:"))
+	assert.True(t, strings.HasPrefix(raw.BirthDate, "v1:"))
+	assert.NotContains(t, raw.Email, email)
+
+	item, _ := personRepo.GetByID(dummyMember.ID)
+	assert.Equal(t, email, *item.Email)
+	assert.Equal(t, phone, *item.Phone)
+
+	items, err := personRepo.GetList(&dto.PersonFilter{Email: strings.ToUpper(email)})
+	assert.Nil(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, dummyMember.ID, items[0].ID)
+}
+
+func TestPerson_Reencrypt_Success(t *testing.T) {
+	email := strings.ToLower(util.RandomStringAlpha(8)) + "@example.com"
+	_ = personRepo.UpdateContact(dummyAdmin.ID, &email, nil, true, nil)
+
+	keys := []string{"1:" + cfg.AuthN.PasswordEncryptionSecret, "2:" + piiKeyV2}
+	usePIIKeys(t, config.PIIConfig{Keys: keys})
+
+	count, err := personRepo.Reencrypt(2)
+	assert.Nil(t, err)
+	assert.Positive(t, count)
+
+	var raw struct{ Email string }
+	db.Raw("SELECT email FROM persons WHERE id = ?", dummyAdmin.ID).Scan(&raw)
+	assert.True(t, strings.HasPrefix(raw.Email, "v2:"))
+
+	item, _ := personRepo.GetByID(dummyAdmin.ID)
+	assert.Equal(t, email, *item.Email)
+
+	// Every value is current now, and the blind index is unchanged.
+	count, _ = personRepo.Reencrypt(2)
+	assert.Zero(t, count)
+
+	items, _ := personRepo.GetList(&dto.PersonFilter{Email: email})
+	assert.Len(t, items, 1)
+
+	// Back to version 1 for the other tests.
+	usePIIKeys(t, config.PIIConfig{Keys: keys, KeyVersion: 1})
+	_, err = personRepo.Reencrypt(2)
+	assert.Nil(t, err)
+}
+
+func TestEntityVersion_Reencrypt_Success(t *testing.T) {
+	versionRepo := repository.GetEntityVersionRepo()
+	version := dao.EntityVersion{
+		Entity:   domain.EntityPerson,
+		EntityID: dummyMember.ID,
+		Data:     `{"fullname":"Rina Wati"}`,
+	}
+	_ = versionRepo.Create(&version)
+
+	keys := []string{"1:" + cfg.AuthN.PasswordEncryptionSecret, "2:" + piiKeyV2}
+	usePIIKeys(t, config.PIIConfig{Keys: keys})
+
+	count, err := versionRepo.Reencrypt(2)
+	assert.Nil(t, err)
+	assert.Positive(t, count)
+
+	var raw struct{ Data string }
+	db.Raw("SELECT data FROM entity_versions WHERE id = ?", version.ID).Scan(&raw)
+	assert.True(t, strings.HasPrefix(raw.Data, "v2:"))
+
+	item, _ := versionRepo.GetByVersion(domain.EntityPerson, dummyMember.ID, version.Version)
+	assert.Equal(t, version.Data, item.Data)
+
+	// Back to version 1 for the other tests.
+	usePIIKeys(t, config.PIIConfig{Keys: keys, KeyVersion: 1})
+	_, err = versionRepo.Reencrypt(2)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,104 @@
+package unit_test
+
+import (
+	"base-gin/config"
+	"base-gin/util"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	piiKeyV1 = "0123456789abcdef0123456789abcdef"
+	piiKeyV2 = "fedcba9876543210fedcba9876543210"
+)
+
+func newPIICipher(t *testing.T, keys ...string) *util.PIICipher {
+	c, err := util.NewPIICipher(config.PIIConfig{Keys: keys}, cfg.AuthN.PasswordEncryptionSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+// usePIIKeys loads a key set for the test and restores the configured one
+// afterwards.
+func usePIIKeys(t *testing.T, pii config.PIIConfig) {
+	c := cfg
+	c.PII = pii
+	if err := util.SetupPIICipher(&c); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = util.SetupPIICipher(&cfg) })
+}
+
+func TestPII_Encrypt_Success(t *testing.T) {
+	c := newPIICipher(t, "1:"+piiKeyV1)
+
+	encrypted, err := c.Encrypt("rina@example.com")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(encrypted, "v1:"))
+	assert.NotContains(t, encrypted, "rina")
+	assert.True(t, c.IsCurrent(encrypted))
+
+	plain, err := c.Decrypt(encrypted)
+	assert.Nil(t, err)
+	assert.Equal(t, "rina@example.com", plain)
+}
+
+func TestPII_Decrypt_Rotation(t *testing.T) {
+	old := newPIICipher(t, "1:"+piiKeyV1)
+	encrypted, _ := old.Encrypt("081234567890")
+
+	rotated := newPIICipher(t, "1:"+piiKeyV1, "2:"+piiKeyV2)
+	assert.False(t, rotated.IsCurrent(encrypted))
+
+	plain, err := rotated.Decrypt(encrypted)
+	assert.Nil(t, err)
+	assert.Equal(t, "081234567890", plain)
+
+	reencrypted, _ := rotated.Encrypt(plain)
+	assert.True(t, strings.HasPrefix(reencrypted, "v2:"))
+
+	// Once the old key is gone, its values can no longer be read.
+	_, err = newPIICipher(t, "2:"+piiKeyV2).Decrypt(encrypted)
+	assert.ErrorIs(t, err, util.ErrPIIKeyInvalid)
+}
+
+func TestPII_Decrypt_Plaintext(t *testing.T) {
+	c := newPIICipher(t, "1:"+piiKeyV1)
+
+	plain, err := c.Decrypt("1995-04-05")
+	assert.Nil(t, err)
+	assert.Equal(t, "1995-04-05", plain)
+	assert.False(t, c.IsCurrent("1995-04-05"))
+}
+
+func TestPII_BlindIndex(t *testing.T) {
+	c := newPIICipher(t, "1:"+piiKeyV1)
+	rotated := newPIICipher(t, "1:"+piiKeyV1, "2:"+piiKeyV2)
+
+	index := c.BlindIndex("rina@example.com")
+	assert.Len(t, index, 64)
+	assert.Equal(t, index, c.BlindIndex(" Rina@Example.com"))
+	assert.Equal(t, index, rotated.BlindIndex("rina@example.com"))
+	assert.NotEqual(t, index, c.BlindIndex("dewi@example.com"))
+}
+
+func TestPII_NewPIICipher_ErrorKey(t *testing.T) {
+	secret := cfg.AuthN.PasswordEncryptionSecret
+
+	_, err := util.NewPIICipher(config.PIIConfig{Keys: []string{"1:short"}}, secret)
+	assert.ErrorIs(t, err, util.ErrPIIKeyInvalid)
+
+	_, err = util.NewPIICipher(config.PIIConfig{Keys: []string{"x:" + piiKeyV1}}, secret)
+	assert.ErrorIs(t, err, util.ErrPIIKeyInvalid)
+
+	_, err = util.NewPIICipher(config.PIIConfig{
+		Keys:       []string{"1:" + piiKeyV1},
+		KeyVersion: 2,
+	}, secret)
+	assert.ErrorIs(t, err, util.ErrPIIKeyInvalid)
+}
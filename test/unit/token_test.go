@@ -0,0 +1,213 @@
+package unit_test
+
+import (
+	"base-gin/config"
+	"base-gin/util"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeKeyFile(t *testing.T, name, blockType string, der []byte) string {
+	file := filepath.Join(t.TempDir(), name+".pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+func newRSAKeyFile(t *testing.T, name string) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return writeKeyFile(t, name, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key))
+}
+
+func newEd25519KeyFiles(t *testing.T, name string) (private, public string) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	pubDER, _ := x509.MarshalPKIXPublicKey(pub)
+
+	return writeKeyFile(t, name, "PRIVATE KEY", der),
+		writeKeyFile(t, name, "PUBLIC KEY", pubDER)
+}
+
+// useTokenKeys loads a key set for the test and restores the configured one
+// afterwards.
+func useTokenKeys(t *testing.T, authN config.AuthNConfig) config.Config {
+	c := cfg
+	c.AuthN = authN
+	if err := util.SetupTokenKeys(&c); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = util.SetupTokenKeys(&cfg) })
+
+	return c
+}
+
+func tokenKeyID(t *testing.T, token string) string {
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func TestToken_RS256(t *testing.T) {
+	authN := cfg.AuthN
+	authN.JWTSecretKey = ""
+	authN.JWTKeyFiles = []string{newRSAKeyFile(t, "rsa-1")}
+	c := useTokenKeys(t, authN)
+
+	token, err := util.CreateAuthAccessToken(c, "admin", util.NewTokenID(), "fam", "admin")
+	assert.Nil(t, err)
+	assert.Equal(t, "rsa-1", tokenKeyID(t, token))
+
+	claims, err := util.VerifyAuthAccessToken(c, token)
+	assert.Nil(t, err)
+	assert.Equal(t, "admin", claims["sub"])
+
+	_, err = util.VerifyAuthRefreshToken(c, token)
+	assert.ErrorIs(t, err, util.ErrRefreshTokenFailedToVerify)
+}
+
+func TestToken_Rotation(t *testing.T) {
+	oldKey, oldPublic := newEd25519KeyFiles(t, "ed-1")
+	newKey := newRSAKeyFile(t, "rsa-2")
+
+	authN := cfg.AuthN
+	authN.JWTKeyFiles = []string{oldKey}
+	c := useTokenKeys(t, authN)
+
+	oldToken, err := util.CreateAuthRefreshToken(c, "admin", util.NewTokenID(), "fam")
+	assert.Nil(t, err)
+	assert.Equal(t, "ed-1", tokenKeyID(t, oldToken))
+
+	// Sign with the new key while the public half of the old one remains.
+	authN.JWTKeyFiles = []string{oldPublic, newKey}
+	authN.JWTSigningKeyID = "rsa-2"
+	c = useTokenKeys(t, authN)
+
+	newToken, err := util.CreateAuthRefreshToken(c, "admin", util.NewTokenID(), "fam")
+	assert.Nil(t, err)
+	assert.Equal(t, "rsa-2", tokenKeyID(t, newToken))
+
+	_, err = util.VerifyAuthRefreshToken(c, oldToken)
+	assert.Nil(t, err)
+	_, err = util.VerifyAuthRefreshToken(c, newToken)
+	assert.Nil(t, err)
+
+	// Once the old key is dropped its tokens are rejected.
+	authN.JWTKeyFiles = []string{newKey}
+	c = useTokenKeys(t, authN)
+
+	_, err = util.VerifyAuthRefreshToken(c, oldToken)
+	assert.ErrorIs(t, err, util.ErrRefreshTokenFailedToVerify)
+}
+
+func TestToken_SigningKeyWithoutPrivatePart(t *testing.T) {
+	_, public := newEd25519KeyFiles(t, "ed-1")
+
+	_, err := util.NewTokenKeySet([]string{public}, "")
+	assert.ErrorIs(t, err, util.ErrTokenKeyInvalid)
+
+	_, err = util.NewTokenKeySet([]string{newRSAKeyFile(t, "rsa-1")}, "rsa-9")
+	assert.ErrorIs(t, err, util.ErrTokenKeyInvalid)
+}
+
+func TestToken_SecretAfterSwitch(t *testing.T) {
+	authN := cfg.AuthN
+	authN.JWTSecretKey = "secret"
+	authN.JWTKeyFiles = nil
+	legacy := cfg
+	legacy.AuthN = authN
+	assert.Nil(t, util.SetupTokenKeys(&legacy))
+	t.Cleanup(func() { _ = util.SetupTokenKeys(&cfg) })
+
+	token, err := util.CreateAuthAccessToken(legacy, "admin", util.NewTokenID(), "fam", "admin")
+	assert.Nil(t, err)
+
+	authN.JWTKeyFiles = []string{newRSAKeyFile(t, "rsa-1")}
+	c := useTokenKeys(t, authN)
+
+	_, err = util.VerifyAuthAccessToken(c, token)
+	assert.ErrorIs(t, err, util.ErrAccessTokenFailedToVerify)
+
+	// Tokens signed with the secret stay valid while JWT_ACCEPT_HS256 is on.
+	c.AuthN.JWTAcceptHS256 = true
+	_, err = util.VerifyAuthAccessToken(c, token)
+	assert.Nil(t, err)
+
+	c.AuthN.JWTSecretKey = ""
+	_, err = util.VerifyAuthAccessToken(c, token)
+	assert.ErrorIs(t, err, util.ErrAccessTokenFailedToVerify)
+}
+
+func TestToken_IssuerAudience(t *testing.T) {
+	authN := cfg.AuthN
+	authN.JWTKeyFiles = []string{newRSAKeyFile(t, "rsa-1")}
+	authN.JWTIssuer = "auth.example.com"
+	authN.JWTAudience = "api.example.com"
+	c := useTokenKeys(t, authN)
+
+	token, err := util.CreateAuthAccessToken(c, "admin", util.NewTokenID(), "fam", "admin")
+	assert.Nil(t, err)
+
+	claims, err := util.VerifyAuthAccessToken(c, token)
+	assert.Nil(t, err)
+	assert.Equal(t, "auth.example.com", claims["iss"])
+	assert.True(t, claims.VerifyAudience("api.example.com", true))
+
+	other := c
+	other.AuthN.JWTAudience = "other.example.com"
+	_, err = util.VerifyAuthAccessToken(other, token)
+	assert.ErrorIs(t, err, util.ErrAccessTokenFailedToVerify)
+
+	other = c
+	other.AuthN.JWTIssuer = "other.example.com"
+	_, err = util.VerifyAuthAccessToken(other, token)
+	assert.ErrorIs(t, err, util.ErrAccessTokenFailedToVerify)
+}
+
+func TestToken_JWKS(t *testing.T) {
+	edKey, _ := newEd25519KeyFiles(t, "ed-1")
+
+	keySet, err := util.NewTokenKeySet([]string{newRSAKeyFile(t, "rsa-1"), edKey}, "")
+	assert.Nil(t, err)
+
+	jwks := keySet.JWKS()
+	if assert.Len(t, jwks.Keys, 2) {
+		assert.Equal(t, "rsa-1", jwks.Keys[0].KeyID)
+		assert.Equal(t, "RSA", jwks.Keys[0].KeyType)
+		assert.Equal(t, "RS256", jwks.Keys[0].Algorithm)
+		assert.Equal(t, "AQAB", jwks.Keys[0].E)
+		assert.NotEmpty(t, jwks.Keys[0].N)
+
+		assert.Equal(t, "ed-1", jwks.Keys[1].KeyID)
+		assert.Equal(t, "OKP", jwks.Keys[1].KeyType)
+		assert.Equal(t, "Ed25519", jwks.Keys[1].Curve)
+		assert.Equal(t, "EdDSA", jwks.Keys[1].Algorithm)
+		assert.NotEmpty(t, jwks.Keys[1].X)
+	}
+
+	var empty *util.TokenKeySet
+	assert.Empty(t, empty.JWKS().Keys)
+}
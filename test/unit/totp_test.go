@@ -0,0 +1,53 @@
+package unit_test
+
+import (
+	"base-gin/util"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// secret of the RFC 6238 SHA1 test vectors, "12345678901234567890"
+const rfcTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTP_Code(t *testing.T) {
+	// RFC 6238 appendix B, truncated to 6 digits
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, expected := range vectors {
+		code, err := util.TOTPCode(rfcTOTPSecret, time.Unix(unix, 0))
+		assert.Nil(t, err)
+		assert.Equal(t, expected, code)
+	}
+}
+
+func TestTOTP_Verify(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, _ := util.TOTPCode(rfcTOTPSecret, now)
+
+	step, ok := util.VerifyTOTP(rfcTOTPSecret, code, now.Add(30*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, util.TOTPStep(now), step)
+
+	_, ok = util.VerifyTOTP(rfcTOTPSecret, code, now.Add(2*time.Minute))
+	assert.False(t, ok)
+
+	_, ok = util.VerifyTOTP(rfcTOTPSecret, "12345", now)
+	assert.False(t, ok)
+}
+
+func TestTOTP_ProvisioningURI(t *testing.T) {
+	secret, err := util.NewTOTPSecret()
+	assert.Nil(t, err)
+
+	uri := util.TOTPProvisioningURI("base-gin", "admin", secret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/base-gin:admin?"))
+	assert.Contains(t, uri, "secret="+secret)
+}
@@ -4,7 +4,9 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"regexp"
@@ -132,6 +134,13 @@ func VerifyPasswordHash(hash string, plain string) bool {
 	return err == nil
 }
 
+// TokenHash returns the hex SHA-256 of a random token. Unlike PasswordHash it
+// can be looked up, which is fine for values with enough entropy of their own.
+func TokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // MaskEmailUsername that takes an email address as input and returns a masked
 // version of the username part of the email. If the email does not contain an
 // "@" symbol, it returns the original email. The username is masked by replacing
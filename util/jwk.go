@@ -0,0 +1,213 @@
+package util
+
+import (
+	"base-gin/config"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var ErrTokenKeyInvalid = errors.New("kunci token tidak valid")
+
+// tokenKey is one key of the key set. A key without a private part, e.g. the
+// public half of a retired key, can only verify tokens.
+type tokenKey struct {
+	id      string
+	method  jwt.SigningMethod
+	private crypto.PrivateKey
+	public  crypto.PublicKey
+}
+
+// TokenKeySet holds the asymmetric keys used for auth tokens. Tokens are
+// signed with one key and verified with whichever key their `kid` header
+// names, so a rotated key keeps verifying the tokens it signed.
+type TokenKeySet struct {
+	signing *tokenKey
+	keys    map[string]*tokenKey
+	order   []string
+}
+
+// tokenKeys is nil when no key file is configured, in which case tokens are
+// signed with the HS256 secret.
+var tokenKeys *TokenKeySet
+
+// SetupTokenKeys loads the PEM files listed in JWT_KEY_FILES. The file name
+// without its extension is the key's `kid`.
+func SetupTokenKeys(cfg *config.Config) error {
+	keySet, err := NewTokenKeySet(cfg.AuthN.JWTKeyFiles, cfg.AuthN.JWTSigningKeyID)
+	if err != nil {
+		return err
+	}
+
+	tokenKeys = keySet
+	return nil
+}
+
+// NewTokenKeySet loads the given PEM files. The key named by signingKeyID, or
+// the first file when it is empty, signs new tokens and must hold a private
+// key. It returns nil when no file is given.
+func NewTokenKeySet(files []string, signingKeyID string) (*TokenKeySet, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	keySet := TokenKeySet{keys: map[string]*tokenKey{}}
+	for _, file := range files {
+		key, err := loadTokenKey(file)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := keySet.keys[key.id]; ok {
+			return nil, fmt.Errorf("%w: duplicate kid %s", ErrTokenKeyInvalid, key.id)
+		}
+
+		keySet.keys[key.id] = key
+		keySet.order = append(keySet.order, key.id)
+	}
+
+	if signingKeyID == "" {
+		signingKeyID = keySet.order[0]
+	}
+	signing, ok := keySet.keys[signingKeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown signing kid %s", ErrTokenKeyInvalid, signingKeyID)
+	}
+	if signing.private == nil {
+		return nil, fmt.Errorf("%w: signing kid %s has no private key", ErrTokenKeyInvalid, signingKeyID)
+	}
+	keySet.signing = signing
+
+	return &keySet, nil
+}
+
+func loadTokenKey(file string) (*tokenKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("TokenKey: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: %s is not a PEM file", ErrTokenKeyInvalid, file)
+	}
+
+	key := tokenKey{
+		id: strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)),
+	}
+
+	var parsed any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported PEM block %s", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrTokenKeyInvalid, file, err)
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.method, key.public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.method, key.public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("%w: %s: only RSA and Ed25519 keys are supported", ErrTokenKeyInvalid, file)
+	}
+
+	return &key, nil
+}
+
+// sign signs the claims with the signing key and names it in the `kid`
+// header.
+func (s *TokenKeySet) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.signing.method, claims)
+	token.Header["kid"] = s.signing.id
+
+	return token.SignedString(s.signing.private)
+}
+
+// verificationKey returns the public key named by the token's `kid` header.
+// The key must have been made for the token's algorithm.
+func (s *TokenKeySet) verificationKey(token *jwt.Token) (crypto.PublicKey, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok || key.method.Alg() != token.Method.Alg() {
+		return nil, fmt.Errorf("%w: %s", ErrTokenUnknown, "unknown key")
+	}
+
+	return key.public, nil
+}
+
+// JSONWebKey is the public part of a token key as described in RFC 7517.
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS returns the public keys other services need to verify our tokens. It
+// is empty when tokens are signed with the HS256 secret.
+func (s *TokenKeySet) JWKS() JSONWebKeySet {
+	jwks := JSONWebKeySet{Keys: []JSONWebKey{}}
+	if s == nil {
+		return jwks
+	}
+
+	for _, kid := range s.order {
+		key := s.keys[kid]
+		jwk := JSONWebKey{
+			KeyID:     key.id,
+			Use:       "sig",
+			Algorithm: key.method.Alg(),
+		}
+
+		switch k := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(k.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(k)
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks
+}
+
+// GetJWKS returns the public keys of the loaded key set.
+func GetJWKS() JSONWebKeySet {
+	return tokenKeys.JWKS()
+}
@@ -0,0 +1,152 @@
+package util
+
+import (
+	"base-gin/config"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+var (
+	ErrLDAPInvalidCredentials = errors.New("kredensial LDAP salah")
+	ErrLDAPServer             = errors.New("gagal menghubungi server LDAP")
+	ErrLDAPUserNotFound       = errors.New("pengguna LDAP tidak ditemukan")
+)
+
+// LDAPUser is a directory entry found by its login name.
+type LDAPUser struct {
+	DN     string
+	Name   string
+	Groups []string // DNs of the groups the user is a member of
+}
+
+// LDAPDirectory checks passwords with a bind against an LDAP server. Users are
+// looked up with the service account, or anonymously when LDAP_BIND_DN is
+// empty, then bound with their own DN & password.
+type LDAPDirectory struct {
+	cfg config.LDAPConfig
+}
+
+func NewLDAPDirectory(cfg config.LDAPConfig) *LDAPDirectory {
+	return &LDAPDirectory{cfg: cfg}
+}
+
+// Authenticate returns the user named username when paswd is their directory
+// password. On ErrLDAPInvalidCredentials the returned user still holds the DN
+// that was found.
+func (d *LDAPDirectory) Authenticate(username, paswd string) (LDAPUser, error) {
+	var user LDAPUser
+
+	conn, err := d.dial()
+	if err != nil {
+		return user, err
+	}
+	defer conn.Close()
+
+	if err := d.serviceBind(conn); err != nil {
+		return user, err
+	}
+
+	entries, err := d.search(conn, d.cfg.UserBaseDN,
+		fmt.Sprintf(d.cfg.UserFilter, ldap.EscapeFilter(username)),
+		[]string{d.cfg.NameAttr})
+	if err != nil {
+		return user, err
+	}
+	// A filter matching several entries can not tell which user logs in.
+	if len(entries) != 1 {
+		return user, ErrLDAPUserNotFound
+	}
+
+	user.DN = entries[0].DN
+	user.Name = entries[0].GetAttributeValue(d.cfg.NameAttr)
+
+	// Servers treat a bind with an empty password as anonymous and accept it,
+	// see RFC 4513 section 5.1.2.
+	if paswd == "" {
+		return user, ErrLDAPInvalidCredentials
+	}
+	if err := conn.Bind(user.DN, paswd); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return user, ErrLDAPInvalidCredentials
+		}
+		return user, fmt.Errorf("%w: %w", ErrLDAPServer, err)
+	}
+
+	if d.cfg.GroupBaseDN == "" {
+		return user, nil
+	}
+
+	// Groups are read with the service account, when there is one, as users
+	// may not be allowed to see them.
+	if err := d.serviceBind(conn); err != nil {
+		return user, err
+	}
+
+	groups, err := d.search(conn, d.cfg.GroupBaseDN,
+		fmt.Sprintf(d.cfg.GroupFilter, ldap.EscapeFilter(user.DN)),
+		[]string{"1.1"})
+	if err != nil {
+		return user, err
+	}
+	for _, group := range groups {
+		user.Groups = append(user.Groups, group.DN)
+	}
+
+	return user, nil
+}
+
+func (d *LDAPDirectory) dial() (*ldap.Conn, error) {
+	timeout := time.Duration(d.cfg.Timeout) * time.Second
+
+	conn, err := ldap.DialURL(d.cfg.URL, ldap.DialWithDialer(&net.Dialer{Timeout: timeout}))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLDAPServer, err)
+	}
+	conn.SetTimeout(timeout)
+
+	if d.cfg.StartTLS {
+		u, err := url.Parse(d.cfg.URL)
+		if err == nil {
+			err = conn.StartTLS(&tls.Config{ServerName: u.Hostname()})
+		}
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%w: %w", ErrLDAPServer, err)
+		}
+	}
+
+	return conn, nil
+}
+
+func (d *LDAPDirectory) serviceBind(conn *ldap.Conn) error {
+	if d.cfg.BindDN == "" {
+		return nil
+	}
+
+	if err := conn.Bind(d.cfg.BindDN, d.cfg.BindPassword); err != nil {
+		return fmt.Errorf("%w: service bind: %w", ErrLDAPServer, err)
+	}
+
+	return nil
+}
+
+func (d *LDAPDirectory) search(conn *ldap.Conn, baseDN, filter string, attributes []string) ([]*ldap.Entry, error) {
+	req := ldap.NewSearchRequest(baseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		0, d.cfg.Timeout, false, filter, attributes, nil)
+
+	res, err := conn.Search(req)
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: %w", ErrLDAPServer, err)
+	}
+
+	return res.Entries, nil
+}
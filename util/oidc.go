@@ -0,0 +1,312 @@
+package util
+
+import (
+	"base-gin/config"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// oidcKeyRefreshInterval limits how often an unknown `kid` makes the
+// provider's JWKS be fetched again.
+const oidcKeyRefreshInterval = time.Minute
+
+var (
+	ErrOIDCProvider     = errors.New("gagal menghubungi penyedia OIDC")
+	ErrOIDCTokenInvalid = errors.New("token OIDC tidak valid")
+)
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCClaims are the ID token claims used to find or create an account.
+type OIDCClaims struct {
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Nonce             string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// OIDCProvider talks to an OpenID Connect provider for the authorization code
+// flow. The discovery document and the signing keys are fetched on first use
+// and cached.
+type OIDCProvider struct {
+	cfg    config.OIDCConfig
+	client *http.Client
+
+	mu            sync.Mutex
+	discovery     *oidcDiscovery
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+func NewOIDCProvider(cfg config.OIDCConfig, client *http.Client) *OIDCProvider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &OIDCProvider{cfg: cfg, client: client}
+}
+
+// AuthURL returns the provider's login page for the given state & nonce. The
+// code verifier is sent as an S256 PKCE challenge.
+func (p *OIDCProvider) AuthURL(state, nonce, codeVerifier string) (string, error) {
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange trades an authorization code for the user's verified ID token
+// claims. The token's nonce must match the one sent with AuthURL.
+func (p *OIDCProvider) Exchange(code, codeVerifier, nonce string) (OIDCClaims, error) {
+	var claims OIDCClaims
+
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return claims, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"client_id":     {p.cfg.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint,
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return claims, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return claims, fmt.Errorf("%w: %w", ErrOIDCProvider, err)
+	}
+	defer res.Body.Close()
+
+	// The provider rejects invalid or reused codes with 400.
+	if res.StatusCode != http.StatusOK {
+		return claims, fmt.Errorf("%w: token endpoint returned %d", ErrOIDCTokenInvalid, res.StatusCode)
+	}
+
+	var body struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return claims, fmt.Errorf("%w: %w", ErrOIDCProvider, err)
+	}
+
+	return p.VerifyIDToken(body.IDToken, nonce)
+}
+
+// VerifyIDToken checks the signature, issuer, audience, expiry and nonce of
+// an ID token.
+func (p *OIDCProvider) VerifyIDToken(rawToken, nonce string) (OIDCClaims, error) {
+	var claims OIDCClaims
+
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return claims, err
+	}
+
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "ES256", "EdDSA"}))
+	_, err = parser.ParseWithClaims(rawToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.getKey(discovery.JWKSURI, kid)
+	})
+	if err != nil {
+		return claims, fmt.Errorf("%w: %w", ErrOIDCTokenInvalid, err)
+	}
+
+	if !claims.VerifyIssuer(discovery.Issuer, true) ||
+		!claims.VerifyAudience(p.cfg.ClientID, true) ||
+		claims.Subject == "" || claims.Nonce != nonce {
+		return claims, ErrOIDCTokenInvalid
+	}
+
+	return claims, nil
+}
+
+func (p *OIDCProvider) getDiscovery() (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var discovery oidcDiscovery
+	wellKnown := strings.TrimSuffix(p.cfg.IssuerURL, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(wellKnown, &discovery); err != nil {
+		return nil, err
+	}
+
+	// The document must be about the configured issuer, see OpenID Connect
+	// Discovery 1.0 section 4.3.
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(p.cfg.IssuerURL, "/") {
+		return nil, fmt.Errorf("%w: issuer %s does not match", ErrOIDCProvider, discovery.Issuer)
+	}
+
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+// getKey returns the provider key named kid, fetching the JWKS again when the
+// key is unknown, e.g. after the provider rotated its keys.
+func (p *OIDCProvider) getKey(jwksURI, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(p.keysFetchedAt) < oidcKeyRefreshInterval {
+		return nil, fmt.Errorf("%w: unknown kid %s", ErrOIDCTokenInvalid, kid)
+	}
+
+	var jwks struct {
+		Keys []oidcJWK `json:"keys"`
+	}
+	if err := p.getJSON(jwksURI, &jwks); err != nil {
+		return nil, err
+	}
+
+	p.keys = map[string]crypto.PublicKey{}
+	p.keysFetchedAt = time.Now()
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, err := jwk.publicKey(); err == nil {
+			p.keys[jwk.KeyID] = key
+		}
+	}
+
+	key, ok := p.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown kid %s", ErrOIDCTokenInvalid, kid)
+	}
+
+	return key, nil
+}
+
+func (p *OIDCProvider) getJSON(url string, v any) error {
+	res, err := p.client.Get(url)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrOIDCProvider, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s returned %d", ErrOIDCProvider, url, res.StatusCode)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("%w: %w", ErrOIDCProvider, err)
+	}
+
+	return nil
+}
+
+// oidcJWK holds the JSON Web Key members needed for RSA, P-256 and Ed25519
+// keys.
+type oidcJWK struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	Curve   string `json:"crv"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+func (k *oidcJWK) publicKey() (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch {
+	case k.KeyType == "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case k.KeyType == "EC" && k.Curve == "P-256":
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	case k.KeyType == "OKP" && k.Curve == "Ed25519":
+		x, err := decode(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: invalid Ed25519 key", ErrOIDCTokenInvalid)
+		}
+
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, fmt.Errorf("%w: unsupported key type %s", ErrOIDCTokenInvalid, k.KeyType)
+}
@@ -0,0 +1,110 @@
+package util
+
+import (
+	"base-gin/config"
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// PasswordPolicy checks new passwords against the rules set in
+// config.AuthNConfig. Reuse of earlier passwords is checked by the caller,
+// since it needs the account's password history.
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	HistorySize   int
+	denyList      map[string]struct{}
+}
+
+// NewPasswordPolicy builds a policy from config and loads the deny-list file,
+// one password per line, when one is set.
+func NewPasswordPolicy(cfg config.AuthNConfig) (*PasswordPolicy, error) {
+	policy := PasswordPolicy{
+		MinLength:     cfg.PasswordMinLength,
+		RequireUpper:  cfg.PasswordRequireUpper,
+		RequireLower:  cfg.PasswordRequireLower,
+		RequireDigit:  cfg.PasswordRequireDigit,
+		RequireSymbol: cfg.PasswordRequireSymbol,
+		HistorySize:   cfg.PasswordHistorySize,
+		denyList:      map[string]struct{}{},
+	}
+
+	if cfg.PasswordDenyListFile == "" {
+		return &policy, nil
+	}
+
+	file, err := os.Open(cfg.PasswordDenyListFile)
+	if err != nil {
+		return nil, fmt.Errorf("PasswordPolicy: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		policy.denyList[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("PasswordPolicy: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// Check returns every rule the password breaks, worded for the given field
+// name. It returns nil when the password is acceptable.
+func (p *PasswordPolicy) Check(field, paswd string) []string {
+	var messages []string
+
+	if len([]rune(paswd)) < p.MinLength {
+		messages = append(messages,
+			fmt.Sprintf("panjang minimal %s adalah %d karakter", field, p.MinLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range paswd {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r), unicode.IsSymbol(r), unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		messages = append(messages, field+" harus mengandung huruf besar")
+	}
+	if p.RequireLower && !hasLower {
+		messages = append(messages, field+" harus mengandung huruf kecil")
+	}
+	if p.RequireDigit && !hasDigit {
+		messages = append(messages, field+" harus mengandung angka")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		messages = append(messages, field+" harus mengandung simbol")
+	}
+
+	if _, denied := p.denyList[strings.ToLower(paswd)]; denied {
+		messages = append(messages, field+" terlalu umum dan mudah ditebak")
+	}
+
+	return messages
+}
+
+// ReuseMessage is the message for a password found in the account's history.
+func (p *PasswordPolicy) ReuseMessage(field string) string {
+	return fmt.Sprintf("%s tidak boleh sama dengan %d password terakhir",
+		field, p.HistorySize)
+}
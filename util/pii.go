@@ -0,0 +1,157 @@
+package util
+
+import (
+	"base-gin/config"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrPIIKeyInvalid = errors.New("kunci enkripsi data pribadi tidak valid")
+
+// PIICipher encrypts personal data stored in the database. Encrypted values
+// are prefixed with the version of their key, e.g. "v2:...", so values
+// encrypted with an older key can still be read after a rotation. Values
+// without a prefix were stored before encryption and are read as they are.
+type PIICipher struct {
+	keys     map[int]string
+	version  int
+	indexKey []byte
+}
+
+// piiCipher is set up by SetupPIICipher before the database is used.
+var piiCipher *PIICipher
+
+// SetupPIICipher loads the keys listed in PII_KEYS.
+func SetupPIICipher(cfg *config.Config) error {
+	c, err := NewPIICipher(cfg.PII, cfg.AuthN.PasswordEncryptionSecret)
+	if err != nil {
+		return err
+	}
+
+	piiCipher = c
+	return nil
+}
+
+// GetPIICipher returns the cipher loaded by SetupPIICipher.
+func GetPIICipher() *PIICipher {
+	if piiCipher == nil {
+		panic("PII cipher is not initialised")
+	}
+
+	return piiCipher
+}
+
+// NewPIICipher parses the versioned keys of cfg. Without any key, secret is
+// used as key version 1. The blind index key falls back to one derived from
+// secret.
+func NewPIICipher(cfg config.PIIConfig, secret string) (*PIICipher, error) {
+	c := PIICipher{keys: map[int]string{}}
+
+	entries := cfg.Keys
+	if len(entries) == 0 {
+		entries = []string{"1:" + secret}
+	}
+	for _, entry := range entries {
+		versionStr, key, _ := strings.Cut(entry, ":")
+		version, err := strconv.Atoi(versionStr)
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("%w: invalid version %q", ErrPIIKeyInvalid, versionStr)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("%w: key version %d must be 32 characters", ErrPIIKeyInvalid, version)
+		}
+		if _, ok := c.keys[version]; ok {
+			return nil, fmt.Errorf("%w: duplicate version %d", ErrPIIKeyInvalid, version)
+		}
+
+		c.keys[version] = key
+		if version > c.version {
+			c.version = version
+		}
+	}
+
+	if cfg.KeyVersion != 0 {
+		if _, ok := c.keys[cfg.KeyVersion]; !ok {
+			return nil, fmt.Errorf("%w: unknown version %d", ErrPIIKeyInvalid, cfg.KeyVersion)
+		}
+		c.version = cfg.KeyVersion
+	}
+
+	if cfg.IndexKey != "" {
+		c.indexKey = []byte(cfg.IndexKey)
+	} else {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte("pii-blind-index"))
+		c.indexKey = mac.Sum(nil)
+	}
+
+	return &c, nil
+}
+
+// Encrypt encrypts plain with the current key.
+func (c *PIICipher) Encrypt(plain string) (string, error) {
+	encrypted, err := EncryptAESGCM(plain, c.keys[c.version])
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("v%d:%s", c.version, encrypted), nil
+}
+
+// Decrypt returns the plain value, whichever key version encrypted it.
+func (c *PIICipher) Decrypt(value string) (string, error) {
+	version, encrypted, ok := parsePIIValue(value)
+	if !ok {
+		return value, nil
+	}
+
+	key, ok := c.keys[version]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown version %d", ErrPIIKeyInvalid, version)
+	}
+	if strings.Count(encrypted, "$@") != 2 {
+		return "", fmt.Errorf("%w: malformed value", ErrPIIKeyInvalid)
+	}
+
+	return DecryptAESGCM(encrypted, key)
+}
+
+// IsCurrent reports whether value is encrypted with the current key.
+func (c *PIICipher) IsCurrent(value string) bool {
+	version, _, ok := parsePIIValue(value)
+	return ok && version == c.version
+}
+
+// BlindIndex returns a keyed hash of value for exact-match lookups of an
+// encrypted column. Case and surrounding spaces are ignored, and the hash does
+// not change when the encryption key is rotated.
+func (c *PIICipher) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, c.indexKey)
+	mac.Write([]byte(strings.ToLower(strings.TrimSpace(value))))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// PIIBlindIndex returns the blind index of value with the loaded cipher.
+func PIIBlindIndex(value string) string {
+	return GetPIICipher().BlindIndex(value)
+}
+
+func parsePIIValue(value string) (int, string, bool) {
+	if !strings.HasPrefix(value, "v") {
+		return 0, "", false
+	}
+
+	versionStr, encrypted, found := strings.Cut(value[1:], ":")
+	version, err := strconv.Atoi(versionStr)
+	if !found || err != nil {
+		return 0, "", false
+	}
+
+	return version, encrypted, true
+}
@@ -7,58 +7,80 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
-const tokenIssuer = "plus.quranbest.com"
-
 var (
-	ErrTokenUnknown               = errors.New("token tidak dikenali")
-	ErrTokenVerificationFailed    = errors.New("gagal melakukan verifikasi token")
-	ErrTokenInvalid               = errors.New("token tidak valid")
-	ErrAuthTokenExpired           = errors.New("token kedaluwarsa")
-	ErrAccessTokenFailedToIssue   = errors.New("gagal menerbitkan token access")
-	ErrRefreshTokenFailedToIssue  = errors.New("gagal menerbitkan token refresh")
-	ErrAccessTokenFailedToVerify  = errors.New("gagal verifikasi token access")
-	ErrRefreshTokenFailedToVerify = errors.New("gagal verifikasi token refresh")
+	ErrTokenUnknown                 = errors.New("token tidak dikenali")
+	ErrTokenVerificationFailed      = errors.New("gagal melakukan verifikasi token")
+	ErrTokenInvalid                 = errors.New("token tidak valid")
+	ErrAuthTokenExpired             = errors.New("token kedaluwarsa")
+	ErrAccessTokenFailedToIssue     = errors.New("gagal menerbitkan token access")
+	ErrRefreshTokenFailedToIssue    = errors.New("gagal menerbitkan token refresh")
+	ErrAccessTokenFailedToVerify    = errors.New("gagal verifikasi token access")
+	ErrRefreshTokenFailedToVerify   = errors.New("gagal verifikasi token refresh")
+	ErrChallengeTokenFailedToIssue  = errors.New("gagal menerbitkan token challenge")
+	ErrChallengeTokenFailedToVerify = errors.New("gagal verifikasi token challenge")
 )
 
 type AuthAccessClaims struct {
-	Email string `json:"email"`
+	Email  string `json:"email"`
+	Family string `json:"fam"`
+	Role   string `json:"role"`
 	jwt.RegisteredClaims
 }
 
-func CreateAuthAccessToken(cfg config.Config, subject string) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, AuthAccessClaims{
+func CreateAuthAccessToken(cfg config.Config, subject, tokenID, family, role string) (string, error) {
+	now := time.Now().UTC()
+	signedToken, err := signAuthToken(cfg, AuthAccessClaims{
+		Family: family,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			Subject: subject,
-			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().
+			ID:       tokenID,
+			Subject:  subject,
+			IssuedAt: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.
 				Add(time.Duration(cfg.AuthN.JWTAuthTTL) * time.Second),
 			),
-			Issuer:   tokenIssuer,
-			Audience: jwt.ClaimStrings{"access"},
+			Issuer:   cfg.AuthN.JWTIssuer,
+			Audience: tokenAudience(cfg, "access"),
 		},
 	})
-
-	signedToken, err := token.SignedString([]byte(cfg.AuthN.JWTSecretKey))
 	if err != nil {
 		return "", fmt.Errorf("%w: %w", ErrAccessTokenFailedToIssue, err)
 	}
 	return signedToken, nil
 }
 
-func CreateAuthRefreshToken(cfg config.Config, subject string) (string, error) {
-	refreshClaims := &jwt.RegisteredClaims{
-		Subject: subject,
-		ExpiresAt: jwt.NewNumericDate(time.Now().UTC().
-			Add(time.Duration(cfg.AuthN.JWTRefreshTTL) * time.Second),
-		),
-		Issuer:   tokenIssuer,
-		Audience: jwt.ClaimStrings{"refresh"},
-	}
+// AuthRefreshClaims carries the token family. Every refresh token issued from
+// the same login shares one family, so a reused token can revoke all of them.
+type AuthRefreshClaims struct {
+	Family string `json:"fam"`
+	jwt.RegisteredClaims
+}
+
+// NewTokenID returns a random value used for the `jti` claim.
+func NewTokenID() string {
+	return uuid.NewString()
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
+func CreateAuthRefreshToken(cfg config.Config, subject, tokenID, family string) (string, error) {
+	now := time.Now().UTC()
+	refreshClaims := &AuthRefreshClaims{
+		Family: family,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:       tokenID,
+			Subject:  subject,
+			IssuedAt: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.
+				Add(time.Duration(cfg.AuthN.JWTRefreshTTL) * time.Second),
+			),
+			Issuer:   cfg.AuthN.JWTIssuer,
+			Audience: tokenAudience(cfg, "refresh"),
+		},
+	}
 
-	signedRefreshToken, err := token.SignedString([]byte(cfg.AuthN.JWTSecretKey))
+	signedRefreshToken, err := signAuthToken(cfg, refreshClaims)
 	if err != nil {
 		return "", fmt.Errorf("%w: %s", ErrRefreshTokenFailedToIssue, err.Error())
 	}
@@ -66,12 +88,61 @@ func CreateAuthRefreshToken(cfg config.Config, subject string) (string, error) {
 	return signedRefreshToken, nil
 }
 
+// CreateAuthChallengeToken issues the short-lived token returned by a login
+// that still needs a second factor. It can only be exchanged for an access &
+// refresh token pair together with a valid code.
+func CreateAuthChallengeToken(cfg config.Config, subject, tokenID string) (string, error) {
+	now := time.Now().UTC()
+	signedToken, err := signAuthToken(cfg, jwt.RegisteredClaims{
+		ID:       tokenID,
+		Subject:  subject,
+		IssuedAt: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.
+			Add(time.Duration(cfg.AuthN.TOTPChallengeTTL) * time.Second),
+		),
+		Issuer:   cfg.AuthN.JWTIssuer,
+		Audience: tokenAudience(cfg, "challenge"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrChallengeTokenFailedToIssue, err)
+	}
+	return signedToken, nil
+}
+
+// tokenAudience returns the token type followed by JWT_AUDIENCE when set, so
+// other services can check the audience meant for them.
+func tokenAudience(cfg config.Config, tokenType string) jwt.ClaimStrings {
+	if cfg.AuthN.JWTAudience == "" {
+		return jwt.ClaimStrings{tokenType}
+	}
+	return jwt.ClaimStrings{tokenType, cfg.AuthN.JWTAudience}
+}
+
+// signAuthToken signs with the active key of the key set, or with the HS256
+// secret when no key file is configured.
+func signAuthToken(cfg config.Config, claims jwt.Claims) (string, error) {
+	if tokenKeys != nil {
+		return tokenKeys.sign(claims)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(cfg.AuthN.JWTSecretKey))
+}
+
 func verifyAuthToken(cfg config.Config, authToken string, tokenAud string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(authToken, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+			// Next to a key set HS256 is only accepted when JWT_ACCEPT_HS256
+			// is on, so tokens issued before the switch can run out.
+			if cfg.AuthN.JWTSecretKey == "" || (tokenKeys != nil && !cfg.AuthN.JWTAcceptHS256) {
+				return nil, fmt.Errorf("%w: %s", ErrTokenUnknown, "signature not match")
+			}
+			return []byte(cfg.AuthN.JWTSecretKey), nil
+		}
+		if tokenKeys == nil {
 			return nil, fmt.Errorf("%w: %s", ErrTokenUnknown, "signature not match")
 		}
-		return []byte(cfg.AuthN.JWTSecretKey), nil
+		return tokenKeys.verificationKey(token)
 	})
 	if err != nil || !token.Valid {
 		return nil, ErrAuthTokenExpired
@@ -82,8 +153,14 @@ func verifyAuthToken(cfg config.Config, authToken string, tokenAud string) (jwt.
 		return nil, fmt.Errorf("%w: %s", ErrTokenUnknown, "invalid structure")
 	}
 
-	if !accessClaims.VerifyIssuer(tokenIssuer, true) ||
-		!accessClaims.VerifyAudience(tokenAud, true) {
+	// The parser checks `exp` only when present, every token must carry it.
+	if _, ok := accessClaims["exp"]; !ok {
+		return nil, ErrAuthTokenExpired
+	}
+
+	if !accessClaims.VerifyIssuer(cfg.AuthN.JWTIssuer, true) ||
+		!accessClaims.VerifyAudience(tokenAud, true) ||
+		(cfg.AuthN.JWTAudience != "" && !accessClaims.VerifyAudience(cfg.AuthN.JWTAudience, true)) {
 		return nil, ErrTokenUnknown
 	}
 
@@ -107,3 +184,12 @@ func VerifyAuthRefreshToken(cfg config.Config, token string) (jwt.MapClaims, err
 
 	return accessClaims, nil
 }
+
+func VerifyAuthChallengeToken(cfg config.Config, token string) (jwt.MapClaims, error) {
+	challengeClaims, err := verifyAuthToken(cfg, token, "challenge")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrChallengeTokenFailedToVerify, err.Error())
+	}
+
+	return challengeClaims, nil
+}